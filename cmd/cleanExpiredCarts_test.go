@@ -1,44 +1,39 @@
-//go:build long
-
 package main
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 type MockRepo struct {
 	mock.Mock
+	calls atomic.Int32
 }
 
-func (m *MockRepo) CleanExpiredCarts(ctx context.Context, duration time.Duration) error {
-	args := m.Called(ctx, duration)
-	return args.Error(0)
+func (m *MockRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) (int, int, error) {
+	m.calls.Add(1)
+	args := m.Called(ctx, ttl)
+	return args.Int(0), args.Int(1), args.Error(2)
 }
 
 func TestCleanExpiredCarts(t *testing.T) {
 	mockRepo := new(MockRepo)
-	mockRepo.On("CleanExpiredCarts", mock.Anything, time.Minute).Return(nil)
+	mockRepo.On("CleanExpiredCarts", mock.Anything, 30*time.Minute).Return(1, 2, nil)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				mockRepo.CleanExpiredCarts(ctx, time.Minute)
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
-
-	time.Sleep(130 * time.Second)
-	mockRepo.AssertNumberOfCalls(t, "CleanExpiredCarts", 2)
+	go runPeriodically(ctx, time.Millisecond, func(ctx context.Context) {
+		sweepExpiredCarts(ctx, mockRepo, 30*time.Minute)
+	})
+
+	assert.Eventually(t, func() bool {
+		return mockRepo.calls.Load() >= 2
+	}, time.Second, time.Millisecond)
+	mockRepo.AssertExpectations(t)
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"log"
@@ -12,7 +13,9 @@ import (
 	"time"
 
 	_ "github.com/cronnoss/bookshop-home-task/docs"
+	"github.com/cronnoss/bookshop-home-task/internal/app/blobstore"
 	"github.com/cronnoss/bookshop-home-task/internal/app/config"
+	"github.com/cronnoss/bookshop-home-task/internal/app/payment"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/pgrepo"
 	"github.com/cronnoss/bookshop-home-task/internal/app/services"
 	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver"
@@ -40,10 +43,17 @@ func main() {
 
 const tokenTTL = time.Minute * 5
 
+// sweeperLockKey is the advisory lock held by the replica running the expiry sweeps.
+var sweeperLockKey = pg.LockKey("bookshop-sweeper")
+
 func run() error {
 	// read config from env
 	cfg := config.Read()
 
+	// ctx is cancelled on shutdown and stops the background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	pgDB, err := pg.Dial(cfg.DSN)
 	if err != nil {
 		return fmt.Errorf("pg.Dial failed: %w", err)
@@ -62,15 +72,36 @@ func run() error {
 	bookRepo := pgrepo.NewBookRepo(pgDB)
 	categoryRepo := pgrepo.NewCategoryRepo(pgDB)
 	cartRepo := pgrepo.NewCartRepo(pgDB)
+	orderRepo := pgrepo.NewOrderRepo(pgDB)
+	idempotencyRepo := pgrepo.NewIdempotencyRepo(pgDB)
+	authorRepo := pgrepo.NewAuthorRepo(pgDB)
+	auditRepo := pgrepo.NewAuditRepo(pgDB)
+
+	// TODO: plug in a real payment provider.
+	paymentGateway := payment.NewFakeGateway()
+
+	coverStore, err := blobstore.NewLocalStore(cfg.CoversDir)
+	if err != nil {
+		return fmt.Errorf("blobstore.NewLocalStore failed: %w", err)
+	}
 
 	userService := services.NewUserService(userRepo)
-	bookService := services.NewBookService(bookRepo)
+	cursorSecret, err := readCursorSecret(cfg)
+	if err != nil {
+		return fmt.Errorf("readCursorSecret failed: %w", err)
+	}
+	bookService := services.NewBookService(bookRepo, coverStore, cursorSecret)
 	categoryService := services.NewCategoryService(categoryRepo)
 	tokenService := services.NewTokenService(tokenTTL)
-	cartService := services.NewCartService(cartRepo)
+	cartService := services.NewCartService(cartRepo, orderRepo, paymentGateway, cfg.CartReservationTTL)
+	orderService := services.NewOrderService(orderRepo, paymentGateway)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyKeyTTL)
+	authorService := services.NewAuthorService(authorRepo)
+	auditService := services.NewAuditService(auditRepo)
 
 	// create http server with application injected
-	httpServer := httpserver.NewHTTPServer(userService, tokenService, bookService, categoryService, cartService)
+	httpServer := httpserver.NewHTTPServer(userService, tokenService, bookService, categoryService, cartService,
+		orderService, idempotencyService, authorService, auditService)
 
 	// create http router
 	router := mux.NewRouter()
@@ -83,38 +114,101 @@ func run() error {
 	router.HandleFunc("/signin", httpServer.SignIn).Methods(http.MethodPost)
 
 	router.HandleFunc("/books", httpServer.GetBooks).Methods(http.MethodGet)
+	router.HandleFunc("/books/isbn/{isbn}", httpServer.GetBookByISBN).Methods(http.MethodGet)
 	router.HandleFunc("/book/{book_id}", httpServer.GetBook).Methods(http.MethodGet)
 	router.HandleFunc("/book", httpServer.CheckAdmin(httpServer.CreateBook)).Methods(http.MethodPost)
 	router.HandleFunc("/book/{book_id}", httpServer.CheckAdmin(httpServer.UpdateBook)).Methods(http.MethodPatch)
 	router.HandleFunc("/book/{book_id}", httpServer.CheckAdmin(httpServer.DeleteBook)).Methods(http.MethodDelete)
+	router.HandleFunc("/book/{book_id}/restore", httpServer.CheckAdmin(httpServer.RestoreBook)).Methods(
+		http.MethodPost)
+	router.HandleFunc("/book/{book_id}/cover", httpServer.CheckAdmin(httpServer.UploadBookCover)).Methods(
+		http.MethodPost)
+	router.HandleFunc("/covers/{key}", httpServer.GetCover).Methods(http.MethodGet, http.MethodHead)
+	router.HandleFunc("/admin/books/import", httpServer.CheckAdmin(httpServer.ImportBooks)).Methods(http.MethodPost)
+	router.HandleFunc("/admin/books/export", httpServer.CheckAdmin(httpServer.ExportBooks)).Methods(http.MethodGet)
 
 	router.HandleFunc("/categories", httpServer.GetCategories).Methods(http.MethodGet)
+	router.HandleFunc("/categories/tree", httpServer.GetCategoryTree).Methods(http.MethodGet)
 	router.HandleFunc("/category/{category_id}", httpServer.GetCategory).Methods(http.MethodGet)
 	router.HandleFunc("/category", httpServer.CheckAdmin(httpServer.CreateCategory)).Methods(http.MethodPost)
 	router.HandleFunc("/category/{category_id}", httpServer.CheckAdmin(httpServer.UpdateCategory)).Methods(
 		http.MethodPatch)
 	router.HandleFunc("/category/{category_id}", httpServer.CheckAdmin(httpServer.DeleteCategory)).Methods(
 		http.MethodDelete)
+	router.HandleFunc("/category/{category_id}/restore", httpServer.CheckAdmin(httpServer.RestoreCategory)).Methods(
+		http.MethodPost)
+
+	router.HandleFunc("/authors", httpServer.GetAuthors).Methods(http.MethodGet)
+	router.HandleFunc("/authors/{author_id}/books", httpServer.GetAuthorBooks).Methods(http.MethodGet)
+	router.HandleFunc("/author/{author_id}", httpServer.GetAuthor).Methods(http.MethodGet)
+	router.HandleFunc("/author", httpServer.CheckAdmin(httpServer.CreateAuthor)).Methods(http.MethodPost)
+	router.HandleFunc("/author/{author_id}", httpServer.CheckAdmin(httpServer.UpdateAuthor)).Methods(
+		http.MethodPatch)
+	router.HandleFunc("/author/{author_id}", httpServer.CheckAdmin(httpServer.DeleteAuthor)).Methods(
+		http.MethodDelete)
+	router.HandleFunc("/author/{author_id}/merge", httpServer.CheckAdmin(httpServer.MergeAuthors)).Methods(
+		http.MethodPost)
+
+	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.GetCart)).Methods(http.MethodGet)
+	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.UpdateCart))).Methods(
+		http.MethodPost)
+	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.ClearCart)).Methods(http.MethodDelete)
+	router.HandleFunc("/cart/items/{book_id}", httpServer.CheckAuthorizedUser(httpServer.SetCartItem)).Methods(
+		http.MethodPut)
+	router.HandleFunc("/cart/items/{book_id}", httpServer.CheckAuthorizedUser(httpServer.RemoveCartItem)).Methods(
+		http.MethodDelete)
+	router.HandleFunc("/checkout", httpServer.CheckAuthorizedUser(httpServer.Idempotent(httpServer.Checkout))).Methods(
+		http.MethodPost)
+
+	router.HandleFunc("/orders", httpServer.CheckAuthorizedUser(httpServer.GetOrders)).Methods(http.MethodGet)
+	router.HandleFunc("/orders/{order_id}", httpServer.CheckAuthorizedUser(httpServer.GetOrder)).Methods(http.MethodGet)
+	router.HandleFunc("/orders/{order_id}/cancel", httpServer.CheckAuthorizedUser(httpServer.CancelOrder)).Methods(
+		http.MethodPost)
+	router.HandleFunc("/admin/orders", httpServer.CheckAdmin(httpServer.GetAdminOrders)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/orders/{order_id}/status", httpServer.CheckAdmin(httpServer.UpdateOrderStatus)).Methods(
+		http.MethodPatch)
 
-	router.HandleFunc("/cart", httpServer.CheckAuthorizedUser(httpServer.UpdateCart)).Methods(http.MethodPost)
-	router.HandleFunc("/checkout", httpServer.CheckAuthorizedUser(httpServer.Checkout)).Methods(http.MethodPost)
-
-	go func(ctx context.Context) {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				log.Println("Cleaning expired carts")
-				err := cartRepo.CleanExpiredCarts(ctx, time.Minute)
-				if err != nil {
-					log.Printf("cartRepo.CleanExpiredCarts failed: %v", err)
-				}
-			case <-ctx.Done():
-				return
+	router.HandleFunc("/admin/audit", httpServer.CheckAdmin(httpServer.GetAuditEvents)).Methods(http.MethodGet)
+
+	// only the replica holding the sweeper lock sweeps, the others keep trying to take it over
+	sweeperLock := pg.NewLeaderLock(pgDB, sweeperLockKey)
+	defer func() {
+		if err := sweeperLock.Release(context.Background()); err != nil {
+			log.Printf("sweeperLock.Release failed: %v", err)
+		}
+	}()
+	go runPeriodically(ctx, cfg.CartSweepInterval, func(ctx context.Context) {
+		if err := sweeperLock.Hold(ctx); err != nil {
+			if !errors.Is(err, pg.ErrLockNotAcquired) {
+				log.Printf("sweeperLock.Hold failed: %v", err)
 			}
+			return
+		}
+
+		sweepExpiredCarts(ctx, cartRepo, cfg.CartReservationTTL)
+
+		deleted, err := idempotencyService.DeleteExpired(ctx)
+		if err != nil {
+			log.Printf("idempotencyService.DeleteExpired failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired idempotency keys", deleted)
 		}
-	}(context.TODO())
+
+		// books go first, a category is only purged once no book references it
+		deletedBefore := time.Now().Add(-cfg.DeletedRetention)
+		books, err := bookService.PurgeDeletedBooks(ctx, deletedBefore)
+		if err != nil {
+			log.Printf("bookService.PurgeDeletedBooks failed: %v", err)
+		} else if books > 0 {
+			log.Printf("Purged %d deleted books", books)
+		}
+		categories, err := categoryService.PurgeDeletedCategories(ctx, deletedBefore)
+		if err != nil {
+			log.Printf("categoryService.PurgeDeletedCategories failed: %v", err)
+		} else if categories > 0 {
+			log.Printf("Purged %d deleted categories", categories)
+		}
+	})
 
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
@@ -128,6 +222,7 @@ func run() error {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 		<-sigint
+		cancel()
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := srv.Shutdown(ctx); err != nil {
@@ -150,6 +245,54 @@ func run() error {
 	return nil
 }
 
+// cartCleaner releases the books reserved by expired carts.
+type cartCleaner interface {
+	CleanExpiredCarts(ctx context.Context, ttl time.Duration) (carts, units int, err error)
+}
+
+// runPeriodically calls job every interval until ctx is done.
+func runPeriodically(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			job(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sweepExpiredCarts releases carts not updated within ttl and returns how many carts
+// and book copies were released.
+func sweepExpiredCarts(ctx context.Context, cleaner cartCleaner, ttl time.Duration) (carts, units int) {
+	carts, units, err := cleaner.CleanExpiredCarts(ctx, ttl)
+	if err != nil {
+		log.Printf("cartRepo.CleanExpiredCarts failed: %v", err)
+		return 0, 0
+	}
+	if carts > 0 {
+		log.Printf("Released %d expired carts holding %d books", carts, units)
+	}
+
+	return carts, units
+}
+
+// readCursorSecret returns the key the book cursors are signed with. Without CURSOR_SECRET a random
+// key is used, so cursors stop working on restart and are only valid on the replica that made them.
+func readCursorSecret(cfg config.Config) ([]byte, error) {
+	if cfg.CursorSecret != "" {
+		return []byte(cfg.CursorSecret), nil
+	}
+	log.Println("CURSOR_SECRET is not set, book cursors are signed with a random key")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate a cursor secret: %w", err)
+	}
+	return secret, nil
+}
+
 // runPgMigrations runs Postgres migrations.
 func runPgMigrations(dsn, path string) error {
 	if path == "" {
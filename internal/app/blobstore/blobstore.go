@@ -0,0 +1,17 @@
+// Package blobstore stores files such as book covers by key.
+package blobstore
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// keyPattern restricts the keys to plain file names, so that a key can't escape the store directory.
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+func validateKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package blobstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (domain.Blob, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func TestStores(t *testing.T) {
+	localStore, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for name, s := range map[string]store{
+		"local":  localStore,
+		"memory": NewMemoryStore(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := s.Get(ctx, "book-1-abc.png")
+			require.ErrorIs(t, err, domain.ErrNotFound)
+
+			require.NoError(t, s.Put(ctx, "book-1-abc.png", []byte("png data"), "image/png"))
+			blob, err := s.Get(ctx, "book-1-abc.png")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("png data"), blob.Data)
+			assert.Equal(t, "image/png", blob.ContentType)
+			assert.False(t, blob.ModTime.IsZero())
+
+			require.NoError(t, s.Delete(ctx, "book-1-abc.png"))
+			_, err = s.Get(ctx, "book-1-abc.png")
+			require.ErrorIs(t, err, domain.ErrNotFound)
+			require.NoError(t, s.Delete(ctx, "book-1-abc.png"))
+
+			// Keys can't point outside the store
+			require.Error(t, s.Put(ctx, "../escape.png", []byte("x"), "image/png"))
+			require.Error(t, s.Put(ctx, "a/b.png", []byte("x"), "image/png"))
+			require.Error(t, s.Put(ctx, ".hidden", []byte("x"), "image/png"))
+		})
+	}
+}
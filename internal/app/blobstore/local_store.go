@@ -0,0 +1,109 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// LocalStore keeps the blobs as files in a directory. The content type is derived from the key extension.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a new local store, creating the directory if needed.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the blob directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes a blob. The file is renamed into place, so readers never see a partial blob.
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, _ string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write a blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write a blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, key)); err != nil {
+		return fmt.Errorf("failed to store a blob: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads a blob, domain.ErrNotFound is returned for unknown keys.
+func (s *LocalStore) Get(ctx context.Context, key string) (domain.Blob, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Blob{}, err
+	}
+	if validateKey(key) != nil {
+		return domain.Blob{}, domain.ErrNotFound
+	}
+
+	path := filepath.Join(s.dir, key)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return domain.Blob{}, domain.ErrNotFound
+		}
+		return domain.Blob{}, fmt.Errorf("failed to stat a blob: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return domain.Blob{}, fmt.Errorf("failed to read a blob: %w", err)
+	}
+
+	return domain.Blob{
+		Data:        data,
+		ContentType: contentTypeOf(key),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// Delete removes a blob, unknown keys are ignored.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(s.dir, key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete a blob: %w", err)
+	}
+
+	return nil
+}
+
+func contentTypeOf(key string) string {
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	return contentType
+}
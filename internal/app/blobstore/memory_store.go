@@ -0,0 +1,90 @@
+package blobstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// MemoryStore keeps the blobs in memory, for tests and local runs.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	blobs map[string]domain.Blob
+}
+
+// NewMemoryStore creates a new empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blobs: map[string]domain.Blob{},
+	}
+}
+
+// Put stores a copy of the data.
+func (s *MemoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = domain.Blob{
+		Data:        slices.Clone(data),
+		ContentType: contentType,
+		ModTime:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	return nil
+}
+
+// Get returns a blob, domain.ErrNotFound is returned for unknown keys.
+func (s *MemoryStore) Get(ctx context.Context, key string) (domain.Blob, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Blob{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blob, ok := s.blobs[key]
+	if !ok {
+		return domain.Blob{}, domain.ErrNotFound
+	}
+	blob.Data = slices.Clone(blob.Data)
+
+	return blob, nil
+}
+
+// Delete removes a blob, unknown keys are ignored.
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+
+	return nil
+}
+
+// Keys returns the stored keys, sorted.
+func (s *MemoryStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.blobs))
+	for key := range s.blobs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
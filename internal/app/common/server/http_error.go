@@ -14,6 +14,11 @@ func InternalError(slug string, err error, w http.ResponseWriter, r *http.Reques
 	httpRespondWithError(err, slug, w, r, "Internal server error", http.StatusInternalServerError)
 }
 
+// InternalErrorWithDetails responds with an internal server error and the details of what was done before it.
+func InternalErrorWithDetails(slug string, err error, details any, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithErrorDetails(err, slug, details, w, r, "Internal server error", http.StatusInternalServerError)
+}
+
 func Unauthorised(slug string, err error, w http.ResponseWriter, r *http.Request) {
 	httpRespondWithError(err, slug, w, r, "Unauthorised", http.StatusUnauthorized)
 }
@@ -22,10 +27,36 @@ func BadRequest(slug string, err error, w http.ResponseWriter, r *http.Request)
 	httpRespondWithError(err, slug, w, r, "Bad request", http.StatusBadRequest)
 }
 
+// BadRequestWithDetails responds with a bad request error and the details of what was done before it.
+func BadRequestWithDetails(slug string, err error, details any, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithErrorDetails(err, slug, details, w, r, "Bad request", http.StatusBadRequest)
+}
+
 func NotFound(slug string, err error, w http.ResponseWriter, r *http.Request) {
 	httpRespondWithError(err, slug, w, r, "Not found", http.StatusBadRequest)
 }
 
+func Conflict(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Conflict", http.StatusConflict)
+}
+
+// ConflictWithDetails responds with a conflict error and the details needed to resolve it.
+func ConflictWithDetails(slug string, err error, details any, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithErrorDetails(err, slug, details, w, r, "Conflict", http.StatusConflict)
+}
+
+func UnprocessableEntity(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Unprocessable entity", http.StatusUnprocessableEntity)
+}
+
+func RequestTooLarge(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Request entity too large", http.StatusRequestEntityTooLarge)
+}
+
+func UnsupportedMediaType(slug string, err error, w http.ResponseWriter, r *http.Request) {
+	httpRespondWithError(err, slug, w, r, "Unsupported media type", http.StatusUnsupportedMediaType)
+}
+
 func RespondWithError(err error, w http.ResponseWriter, r *http.Request) {
 	var slugError slugerrors.SlugError
 	if !errors.As(err, &slugError) {
@@ -40,15 +71,25 @@ func RespondWithError(err error, w http.ResponseWriter, r *http.Request) {
 		BadRequest(slugError.Slug(), slugError, w, r)
 	case slugerrors.ErrorTypeNotFound:
 		NotFound(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypeConflict:
+		Conflict(slugError.Slug(), slugError, w, r)
+	case slugerrors.ErrorTypeUnprocessable:
+		UnprocessableEntity(slugError.Slug(), slugError, w, r)
 	default:
 		InternalError(slugError.Slug(), slugError, w, r)
 	}
 }
 
-func httpRespondWithError(err error, slug string, w http.ResponseWriter, _ *http.Request, msg string, status int) {
+func httpRespondWithError(err error, slug string, w http.ResponseWriter, r *http.Request, msg string, status int) {
+	httpRespondWithErrorDetails(err, slug, nil, w, r, msg, status)
+}
+
+func httpRespondWithErrorDetails(
+	err error, slug string, details any, w http.ResponseWriter, _ *http.Request, msg string, status int,
+) {
 	log.Printf("error: %s, slug: %s, msg: %s", err, slug, msg)
 
-	resp := ErrorResponse{Slug: slug, httpStatus: status}
+	resp := ErrorResponse{Slug: slug, Details: details, httpStatus: status}
 	if os.Getenv("DEBUG_ERRORS") != "" && err != nil {
 		resp.Error = err.Error()
 	}
@@ -59,8 +100,10 @@ func httpRespondWithError(err error, slug string, w http.ResponseWriter, _ *http
 }
 
 type ErrorResponse struct {
-	Slug       string `json:"slug"`
-	Error      string `json:"error,omitempty"`
+	Slug  string `json:"slug"`
+	Error string `json:"error,omitempty"`
+	// Details is set by the errors that carry more than a slug, e.g. category-not-empty.
+	Details    any `json:"details,omitempty"`
 	httpStatus int
 }
 
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginatedProfile is the Accept profile asking for a paginated envelope instead of a bare array,
+// e.g. `Accept: application/json; profile="urn:bookshop:paginated"`.
+const PaginatedProfile = "urn:bookshop:paginated"
+
+// Page is a paginated response envelope.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+	// Page is the page number, it is omitted for cursor pagination.
+	Page     int    `json:"page,omitempty"`
+	PageSize int    `json:"pageSize"`
+	Next     string `json:"next,omitempty"`
+	Prev     string `json:"prev,omitempty"`
+}
+
+// WantsPage reports whether the client asked for a paginated envelope, either with
+// the PaginatedProfile Accept profile or with the envelope=true query parameter.
+func WantsPage(r *http.Request) bool {
+	if envelope, err := strconv.ParseBool(r.URL.Query().Get("envelope")); err == nil {
+		return envelope
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
+		}
+		for _, profile := range strings.Fields(params["profile"]) {
+			if profile == PaginatedProfile {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewNumberedPage creates a page of a listing paginated with the "page" query parameter.
+// The links point to the neighbouring pages of the request URL.
+func NewNumberedPage[T any](r *http.Request, items []T, total, page, pageSize int) Page[T] {
+	p := Page[T]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if pageSize > 0 && page*pageSize < total {
+		p.Next = linkWith(r, "page", strconv.Itoa(page+1))
+	}
+	if page > 1 {
+		p.Prev = linkWith(r, "page", strconv.Itoa(page-1))
+	}
+	return p
+}
+
+// NewCursorPage creates a page of a listing paginated with the "cursor" query parameter.
+// Cursors only go forward, so there is no link to the previous page.
+func NewCursorPage[T any](r *http.Request, items []T, total, pageSize int, nextCursor string) Page[T] {
+	p := Page[T]{
+		Items:    items,
+		Total:    total,
+		PageSize: pageSize,
+	}
+	if nextCursor != "" {
+		p.Next = linkWith(r, "cursor", nextCursor)
+	}
+	return p
+}
+
+// RespondPage writes the page with its links in an RFC 8288 Link header.
+func RespondPage[T any](page Page[T], w http.ResponseWriter, _ *http.Request) {
+	links := make([]string, 0, 2)
+	if page.Next != "" {
+		links = append(links, "<"+page.Next+`>; rel="next"`)
+	}
+	if page.Prev != "" {
+		links = append(links, "<"+page.Prev+`>; rel="prev"`)
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	w.Header().Set("Content-Type", `application/json; charset=utf-8; profile="`+PaginatedProfile+`"`)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// linkWith returns the request path and query with one page parameter replaced. The "page" and
+// "cursor" parameters exclude each other, so setting one drops the other.
+func linkWith(r *http.Request, key, value string) string {
+	query := r.URL.Query()
+	query.Del("page")
+	query.Del("cursor")
+	query.Set(key, value)
+	link := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return link.String()
+}
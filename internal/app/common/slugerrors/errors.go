@@ -9,6 +9,8 @@ var (
 	ErrorTypeAuthorization = ErrorType{"authorization"}
 	ErrorTypeBadRequest    = ErrorType{"bad-request"}
 	ErrorTypeNotFound      = ErrorType{"not-found"}
+	ErrorTypeConflict      = ErrorType{"conflict"}
+	ErrorTypeUnprocessable = ErrorType{"unprocessable"}
 )
 
 type SlugError struct {
@@ -60,3 +62,19 @@ func NewNotFoundError(errMsg string, slug string) SlugError {
 		errorType: ErrorTypeNotFound,
 	}
 }
+
+func NewConflictError(errMsg string, slug string) SlugError {
+	return SlugError{
+		message:   errMsg,
+		slug:      slug,
+		errorType: ErrorTypeConflict,
+	}
+}
+
+func NewUnprocessableError(errMsg string, slug string) SlugError {
+	return SlugError{
+		message:   errMsg,
+		slug:      slug,
+		errorType: ErrorTypeUnprocessable,
+	}
+}
@@ -1,12 +1,34 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
+
+const (
+	defaultIdempotencyKeyTTL  = 24 * time.Hour
+	defaultCartReservationTTL = 30 * time.Minute
+	defaultCartSweepInterval  = time.Minute
+	defaultCoversDir          = "covers"
+	defaultDeletedRetention   = 30 * 24 * time.Hour
+)
 
 // Config is a config :).
 type Config struct {
-	HTTPAddr       string
-	DSN            string
-	MigrationsPath string
+	HTTPAddr          string
+	DSN               string
+	MigrationsPath    string
+	IdempotencyKeyTTL time.Duration
+	// CartReservationTTL is how long books stay reserved by a cart after its last update.
+	CartReservationTTL time.Duration
+	// CartSweepInterval is how often expired carts are released.
+	CartSweepInterval time.Duration
+	// CoversDir is the directory the book covers are stored in.
+	CoversDir string
+	// DeletedRetention is how long deleted books and categories can be restored before they are purged.
+	DeletedRetention time.Duration
+	// CursorSecret is the key the book page cursors are signed with. All replicas must share it.
+	CursorSecret string
 }
 
 // Read reads config from environment.
@@ -24,5 +46,29 @@ func Read() Config {
 	if exists {
 		config.MigrationsPath = migrationsPath
 	}
+	config.IdempotencyKeyTTL = readDuration("IDEMPOTENCY_KEY_TTL", defaultIdempotencyKeyTTL)
+	config.CartReservationTTL = readDuration("CART_RESERVATION_TTL", defaultCartReservationTTL)
+	config.CartSweepInterval = readDuration("CART_SWEEP_INTERVAL", defaultCartSweepInterval)
+	config.CoversDir = defaultCoversDir
+	coversDir, exists := os.LookupEnv("COVERS_DIR")
+	if exists && coversDir != "" {
+		config.CoversDir = coversDir
+	}
+	config.DeletedRetention = readDuration("DELETED_RETENTION", defaultDeletedRetention)
+	config.CursorSecret = os.Getenv("CURSOR_SECRET")
 	return config
 }
+
+// readDuration reads a positive duration such as "30m" from the environment.
+// The default is used when the variable is unset or invalid.
+func readDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+	return duration
+}
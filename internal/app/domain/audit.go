@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntity is the kind of entity an audit event is about.
+type AuditEntity string
+
+const (
+	AuditEntityBook     AuditEntity = "book"
+	AuditEntityCategory AuditEntity = "category"
+)
+
+// Valid reports whether the entity is audited.
+func (e AuditEntity) Valid() bool {
+	return e == AuditEntityBook || e == AuditEntityCategory
+}
+
+// AuditAction is the change an audit event records.
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	// AuditActionUpdateCover is a new cover on a book.
+	AuditActionUpdateCover AuditAction = "update_cover"
+)
+
+// AuditEvent records who changed a book or a category and how, with snapshots of the entity
+// before and after the change.
+type AuditEvent struct {
+	id        int64
+	actorID   int
+	entity    AuditEntity
+	entityID  int
+	action    AuditAction
+	before    json.RawMessage
+	after     json.RawMessage
+	createdAt time.Time
+}
+
+type NewAuditEventData struct {
+	ID int64
+	// ActorID is the user who made the change, 0 when the user is unknown.
+	ActorID  int
+	Entity   AuditEntity
+	EntityID int
+	Action   AuditAction
+	// Before is nil for created entities.
+	Before    json.RawMessage
+	After     json.RawMessage
+	CreatedAt time.Time
+}
+
+// NewAuditEvent creates a new audit event.
+func NewAuditEvent(data NewAuditEventData) (AuditEvent, error) {
+	if !data.Entity.Valid() {
+		return AuditEvent{}, fmt.Errorf("%w: %q", ErrInvalidAuditEntity, data.Entity)
+	}
+	if data.EntityID == 0 {
+		return AuditEvent{}, fmt.Errorf("%w: entity id", ErrRequired)
+	}
+	if data.Action == "" {
+		return AuditEvent{}, fmt.Errorf("%w: action", ErrRequired)
+	}
+
+	return AuditEvent{
+		id:        data.ID,
+		actorID:   data.ActorID,
+		entity:    data.Entity,
+		entityID:  data.EntityID,
+		action:    data.Action,
+		before:    data.Before,
+		after:     data.After,
+		createdAt: data.CreatedAt,
+	}, nil
+}
+
+// ID returns the audit event ID.
+func (e AuditEvent) ID() int64 {
+	return e.id
+}
+
+// ActorID returns the ID of the user who made the change, 0 when the user is unknown.
+func (e AuditEvent) ActorID() int {
+	return e.actorID
+}
+
+// Entity returns the kind of the changed entity.
+func (e AuditEvent) Entity() AuditEntity {
+	return e.entity
+}
+
+// EntityID returns the ID of the changed entity.
+func (e AuditEvent) EntityID() int {
+	return e.entityID
+}
+
+// Action returns the change.
+func (e AuditEvent) Action() AuditAction {
+	return e.action
+}
+
+// Before returns the JSON snapshot of the entity before the change, nil for created entities.
+func (e AuditEvent) Before() json.RawMessage {
+	return e.before
+}
+
+// After returns the JSON snapshot of the entity after the change.
+func (e AuditEvent) After() json.RawMessage {
+	return e.after
+}
+
+// CreatedAt returns the time of the change.
+func (e AuditEvent) CreatedAt() time.Time {
+	return e.createdAt
+}
+
+// AuditFilter narrows down a list of audit events. Zero values are ignored.
+type AuditFilter struct {
+	Entity AuditEntity
+	// EntityID keeps the events of one entity, it requires Entity.
+	EntityID int
+	ActorID  int
+	From     time.Time
+	To       time.Time
+}
+
+// Validate checks that the entity is audited and that an entity ID comes with its entity.
+func (f AuditFilter) Validate() error {
+	if f.Entity != "" && !f.Entity.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidAuditEntity, f.Entity)
+	}
+	if f.EntityID != 0 && f.Entity == "" {
+		return fmt.Errorf("%w: entity, to filter by entity ID", ErrRequired)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return fmt.Errorf("%w: from %s is not before to %s", ErrInvalidRange, f.From, f.To)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuditEvent_InvalidEntity(t *testing.T) {
+	_, err := NewAuditEvent(NewAuditEventData{Entity: "order", EntityID: 1, Action: AuditActionCreate})
+	require.ErrorIs(t, err, ErrInvalidAuditEntity)
+}
+
+func TestAuditFilter_Validate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter AuditFilter
+		err    error
+	}{
+		{name: "empty", filter: AuditFilter{}},
+		{name: "entity", filter: AuditFilter{Entity: AuditEntityBook, EntityID: 1, From: day, To: day.Add(time.Hour)}},
+		{name: "unknown entity", filter: AuditFilter{Entity: "order"}, err: ErrInvalidAuditEntity},
+		{name: "entity id without entity", filter: AuditFilter{EntityID: 1}, err: ErrRequired},
+		{name: "empty range", filter: AuditFilter{From: day, To: day}, err: ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
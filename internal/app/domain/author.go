@@ -0,0 +1,37 @@
+package domain
+
+import "strings"
+
+// Author is a domain author.
+type Author struct {
+	id   int
+	name string
+}
+
+type NewAuthorData struct {
+	ID   int
+	Name string
+}
+
+// NewAuthor creates a new author. Extra whitespace in the name is removed.
+func NewAuthor(data NewAuthorData) (Author, error) {
+	return Author{
+		id:   data.ID,
+		name: NormalizeAuthorName(data.Name),
+	}, nil
+}
+
+// NormalizeAuthorName trims the name and collapses the whitespace inside it.
+func NormalizeAuthorName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ID returns the author ID.
+func (a Author) ID() int {
+	return a.id
+}
+
+// Name returns the author name.
+func (a Author) Name() string {
+	return a.name
+}
@@ -1,14 +1,29 @@
 package domain
 
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
 // Book is a domain book.
 type Book struct {
-	id         int
-	title      string
-	year       int
-	author     string
-	price      int
-	stock      int
-	categoryID int
+	id          int
+	title       string
+	year        int
+	author      string
+	price       int
+	stock       int
+	categoryID  int
+	categoryIDs []int
+	authorIDs   []int
+	isbn        string
+	coverKey    string
+	createdAt   time.Time
+	deletedAt   time.Time
+	highlight   BookHighlight
+	rank        float64
 }
 
 type NewBookData struct {
@@ -19,18 +34,173 @@ type NewBookData struct {
 	Price      int
 	Stock      int
 	CategoryID int
+	// CategoryIDs are all the book categories. The main category CategoryID is always one of them,
+	// it defaults to the first of CategoryIDs.
+	CategoryIDs []int
+	AuthorIDs   []int
+	ISBN        string
+	CoverKey    string
+	CreatedAt   time.Time
+	DeletedAt   time.Time
+	Highlight   BookHighlight
+	Rank        float64
+}
+
+// BookHighlight holds the title and author of a book found by a search query,
+// with the matching words wrapped in <mark> tags.
+type BookHighlight struct {
+	Title  string
+	Author string
+}
+
+// BookFilter narrows down a list of books. Zero values are ignored.
+type BookFilter struct {
+	// CategoryIDs keeps the books in any of the categories, or of their subcategories
+	// with IncludeDescendants.
+	CategoryIDs        []int
+	IncludeDescendants bool
+	// Query is a full-text search query over the title and the author. When it is set,
+	// the books are ordered by relevance unless Sort is set.
+	Query string
+	// Author matches the books whose author contains it, case-insensitively.
+	Author string
+	// AuthorID keeps the books linked to the author.
+	AuthorID int
+	MinPrice int
+	MaxPrice int
+	MinYear  int
+	MaxYear  int
+	// InStock keeps only the books in stock when true and only the sold-out ones when false.
+	// Nil returns both.
+	InStock *bool
+	// Deleted returns the deleted books instead of the others.
+	Deleted bool
+	Sort    BookSort
+	// After skips the books up to and including the one the cursor points to.
+	After *BookCursor
+}
+
+// Validate checks that the ranges are not negative or inverted and that the sort field is known.
+func (f BookFilter) Validate() error {
+	if f.MinPrice < 0 || f.MaxPrice < 0 {
+		return fmt.Errorf("%w: price", ErrNegative)
+	}
+	if f.MaxPrice > 0 && f.MinPrice > f.MaxPrice {
+		return fmt.Errorf("%w: min price %d is greater than max price %d", ErrInvalidRange, f.MinPrice, f.MaxPrice)
+	}
+	if f.MinYear < 0 || f.MaxYear < 0 {
+		return fmt.Errorf("%w: year", ErrNegative)
+	}
+	if f.MaxYear > 0 && f.MinYear > f.MaxYear {
+		return fmt.Errorf("%w: min year %d is greater than max year %d", ErrInvalidRange, f.MinYear, f.MaxYear)
+	}
+	if f.Sort.Field != "" && !f.Sort.Field.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidSortField, f.Sort.Field)
+	}
+	if f.After != nil && (f.After.Sort != f.Sort || f.After.Ranked != f.Ranked()) {
+		return fmt.Errorf("%w: issued for another sort order", ErrInvalidCursor)
+	}
+	return nil
+}
+
+// Ranked reports whether the books are ordered by search relevance.
+func (f BookFilter) Ranked() bool {
+	return f.Query != "" && f.Sort.Field == ""
+}
+
+// CursorAfter returns a cursor pointing to the book, for the next page of the same listing.
+func (f BookFilter) CursorAfter(book Book) BookCursor {
+	cursor := BookCursor{
+		Sort:   f.Sort,
+		Ranked: f.Ranked(),
+		ID:     book.ID(),
+	}
+	switch {
+	case cursor.Ranked:
+		cursor.Value = strconv.FormatFloat(book.Rank(), 'g', -1, 64)
+	case f.Sort.Field == BookSortByPrice:
+		cursor.Value = strconv.Itoa(book.Price())
+	case f.Sort.Field == BookSortByYear:
+		cursor.Value = strconv.Itoa(book.Year())
+	case f.Sort.Field == BookSortByTitle:
+		cursor.Value = book.Title()
+	case f.Sort.Field == BookSortByCreatedAt:
+		cursor.Value = book.CreatedAt().Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+// BookCursor points to a book in a listing ordered by a sort key and then by ID.
+// It is only valid for the sort order it was issued for.
+type BookCursor struct {
+	Sort   BookSort
+	Ranked bool
+	// Value is the sort key of the book, empty when the books are ordered by ID only.
+	Value string
+	ID    int
+}
+
+// BookSortField is a field books can be sorted by.
+type BookSortField string
+
+const (
+	BookSortByPrice     BookSortField = "price"
+	BookSortByYear      BookSortField = "year"
+	BookSortByTitle     BookSortField = "title"
+	BookSortByCreatedAt BookSortField = "created_at"
+)
+
+// Valid reports whether the books can be sorted by the field.
+func (f BookSortField) Valid() bool {
+	switch f {
+	case BookSortByPrice, BookSortByYear, BookSortByTitle, BookSortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// BookSort orders a list of books. An empty field keeps the default order.
+type BookSort struct {
+	Field BookSortField
+	Desc  bool
 }
 
-// NewBook creates a new book.
+// NewBook creates a new book. The ISBN is optional, an ISBN-10 is converted to ISBN-13.
+// The main category is added to the other categories when they don't include it.
 func NewBook(data NewBookData) (Book, error) {
+	var isbn string
+	if data.ISBN != "" {
+		var err error
+		isbn, err = NormalizeISBN(data.ISBN)
+		if err != nil {
+			return Book{}, err
+		}
+	}
+
+	categoryID, categoryIDs := data.CategoryID, data.CategoryIDs
+	if categoryID == 0 && len(categoryIDs) > 0 {
+		categoryID = categoryIDs[0]
+	}
+	if categoryID != 0 && !slices.Contains(categoryIDs, categoryID) {
+		categoryIDs = append([]int{categoryID}, categoryIDs...)
+	}
+
 	return Book{
-		id:         data.ID,
-		title:      data.Title,
-		year:       data.Year,
-		author:     data.Author,
-		price:      data.Price,
-		stock:      data.Stock,
-		categoryID: data.CategoryID,
+		id:          data.ID,
+		title:       data.Title,
+		year:        data.Year,
+		author:      data.Author,
+		price:       data.Price,
+		stock:       data.Stock,
+		categoryID:  categoryID,
+		categoryIDs: categoryIDs,
+		authorIDs:   data.AuthorIDs,
+		isbn:        isbn,
+		coverKey:    data.CoverKey,
+		createdAt:   data.CreatedAt,
+		deletedAt:   data.DeletedAt,
+		highlight:   data.Highlight,
+		rank:        data.Rank,
 	}, nil
 }
 
@@ -64,7 +234,48 @@ func (b Book) Stock() int {
 	return b.stock
 }
 
-// CategoryID returns the book category ID.
+// CategoryID returns the main book category ID.
 func (b Book) CategoryID() int {
 	return b.categoryID
 }
+
+// CategoryIDs returns the IDs of all the book categories, including the main one.
+func (b Book) CategoryIDs() []int {
+	return b.categoryIDs
+}
+
+// AuthorIDs returns the IDs of the book authors in the order they are credited in.
+func (b Book) AuthorIDs() []int {
+	return b.authorIDs
+}
+
+// ISBN returns the ISBN-13 of the book, empty when it is unknown.
+func (b Book) ISBN() string {
+	return b.isbn
+}
+
+// CoverKey returns the blob key of the original cover, empty when the book has no cover.
+// See CoverVariantKey for the thumbnails.
+func (b Book) CoverKey() string {
+	return b.coverKey
+}
+
+// CreatedAt returns the time the book was created.
+func (b Book) CreatedAt() time.Time {
+	return b.createdAt
+}
+
+// DeletedAt returns the time the book was deleted, zero when it is not deleted.
+func (b Book) DeletedAt() time.Time {
+	return b.deletedAt
+}
+
+// Rank returns the search relevance. It is only set for books found by a search query.
+func (b Book) Rank() float64 {
+	return b.rank
+}
+
+// Highlight returns the search highlight. It is only set for books found by a search query.
+func (b Book) Highlight() BookHighlight {
+	return b.highlight
+}
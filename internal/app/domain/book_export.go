@@ -0,0 +1,7 @@
+package domain
+
+// ExportedBook is a book with the names of its categories, ordered by name.
+type ExportedBook struct {
+	Book
+	CategoryNames []string
+}
@@ -0,0 +1,24 @@
+package domain
+
+// BookImportAction is what an import did with a row.
+type BookImportAction string
+
+const (
+	BookImportCreated  BookImportAction = "created"
+	BookImportUpdated  BookImportAction = "updated"
+	BookImportRejected BookImportAction = "rejected"
+	// BookImportFailed is a valid row of a batch that failed to be written.
+	BookImportFailed BookImportAction = "failed"
+)
+
+// BookImportResult is the outcome of importing one book.
+type BookImportResult struct {
+	Action BookImportAction
+	// BookID is the created or updated book, or the book that would be in a dry run.
+	// It is 0 for rejected books and for the books a dry run would create.
+	BookID int
+	// Err is the reason a book was rejected.
+	Err error
+	// Warning tells what of an updated book was not written, e.g. the stock, which can't be edited.
+	Warning string
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBook_Categories(t *testing.T) {
+	tests := map[string]struct {
+		categoryID      int
+		categoryIDs     []int
+		wantCategoryID  int
+		wantCategoryIDs []int
+	}{
+		"main category only":     {2, nil, 2, []int{2}},
+		"categories only":        {0, []int{3, 1}, 3, []int{3, 1}},
+		"main category included": {1, []int{3, 1}, 1, []int{3, 1}},
+		"main category missing":  {2, []int{3, 1}, 2, []int{2, 3, 1}},
+		"no category":            {0, nil, 0, nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			book, err := NewBook(NewBookData{Title: "Title", CategoryID: tt.categoryID, CategoryIDs: tt.categoryIDs})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCategoryID, book.CategoryID())
+			assert.Equal(t, tt.wantCategoryIDs, book.CategoryIDs())
+		})
+	}
+}
@@ -1,44 +1,164 @@
 package domain
 
+import "time"
+
+// CartItem is a number of copies of a book in the cart. The book itself is only
+// loaded when the cart is read, so it holds the book's current price.
+type CartItem struct {
+	bookID   int
+	quantity int
+	book     Book
+}
+
+type NewCartItemData struct {
+	BookID   int
+	Quantity int
+	Book     Book
+}
+
+// NewCartItem creates a new cart item.
+func NewCartItem(data NewCartItemData) (CartItem, error) {
+	if data.BookID <= 0 {
+		return CartItem{}, ErrInvalidBookIDs
+	}
+	if data.Quantity <= 0 {
+		return CartItem{}, ErrInvalidQuantity
+	}
+
+	return CartItem{
+		bookID:   data.BookID,
+		quantity: data.Quantity,
+		book:     data.Book,
+	}, nil
+}
+
+func (i CartItem) BookID() int {
+	return i.bookID
+}
+
+func (i CartItem) Quantity() int {
+	return i.quantity
+}
+
+func (i CartItem) Book() Book {
+	return i.book
+}
+
+// Total returns the price of all copies of the book at its current price.
+func (i CartItem) Total() int {
+	return i.book.Price() * i.quantity
+}
+
 type Cart struct {
-	userID  int
-	bookIDs []int
+	userID    int
+	items     []CartItem
+	updatedAt time.Time
+	expiresAt time.Time
 }
 
+// NewCartData holds the cart contents. BookIDs is the legacy form, where every occurrence
+// of a book adds one copy. Quantities of the same book are summed up.
 type NewCartData struct {
-	UserID  int
-	BookIDs []int
+	UserID    int
+	Items     []CartItem
+	BookIDs   []int
+	UpdatedAt time.Time
 }
 
 func NewCart(data NewCartData) (Cart, error) {
-	return Cart{
-		userID:  data.UserID,
-		bookIDs: data.BookIDs,
-	}, nil
+	cart := Cart{
+		userID:    data.UserID,
+		items:     []CartItem{},
+		updatedAt: data.UpdatedAt,
+	}
+
+	for _, item := range data.Items {
+		if item.bookID <= 0 {
+			return Cart{}, ErrInvalidBookIDs
+		}
+		if item.quantity <= 0 {
+			return Cart{}, ErrInvalidQuantity
+		}
+		cart = cart.addItem(item)
+	}
+	for _, bookID := range data.BookIDs {
+		if bookID <= 0 {
+			return Cart{}, ErrInvalidBookIDs
+		}
+		cart = cart.add(bookID, 1)
+	}
+
+	return cart, nil
 }
 
 func (c Cart) UserID() int {
 	return c.userID
 }
 
+func (c Cart) Items() []CartItem {
+	return c.items
+}
+
+func (c Cart) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+
+// ExpiresAt returns the time when the books reserved by the cart are released.
+// It is zero unless the reservation TTL is set with WithReservationTTL.
+func (c Cart) ExpiresAt() time.Time {
+	return c.expiresAt
+}
+
+// WithReservationTTL returns a copy of the cart that expires ttl after its last update.
+func (c Cart) WithReservationTTL(ttl time.Duration) Cart {
+	if !c.updatedAt.IsZero() {
+		c.expiresAt = c.updatedAt.Add(ttl)
+	}
+	return c
+}
+
+// Subtotal returns the price of the books in the cart at their current prices.
+func (c Cart) Subtotal() int {
+	subtotal := 0
+	for _, item := range c.items {
+		subtotal += item.Total()
+	}
+
+	return subtotal
+}
+
+// BookIDs returns the distinct IDs of the books in the cart.
 func (c Cart) BookIDs() []int {
-	return c.bookIDs
+	bookIDs := make([]int, 0, len(c.items))
+	for _, item := range c.items {
+		bookIDs = append(bookIDs, item.bookID)
+	}
+
+	return bookIDs
 }
 
-func (c Cart) Diff(old Cart) Cart {
-	diff := Cart{
-		userID:  c.userID,
-		bookIDs: []int{},
+// Quantity returns the number of copies of the book in the cart.
+func (c Cart) Quantity(bookID int) int {
+	for _, item := range c.items {
+		if item.bookID == bookID {
+			return item.quantity
+		}
 	}
 
-	oldCartBookIDs := map[int]struct{}{}
-	for _, bookID := range old.bookIDs {
-		oldCartBookIDs[bookID] = struct{}{}
+	return 0
+}
+
+// Diff returns the copies that are in c but not in old, i.e. for every book
+// the positive difference between the quantities.
+func (c Cart) Diff(old Cart) Cart {
+	diff := Cart{
+		userID: c.userID,
+		items:  []CartItem{},
 	}
 
-	for _, bookID := range c.bookIDs {
-		if _, ok := oldCartBookIDs[bookID]; !ok {
-			diff.bookIDs = append(diff.bookIDs, bookID)
+	for _, item := range c.items {
+		if delta := item.quantity - old.Quantity(item.bookID); delta > 0 {
+			diff = diff.add(item.bookID, delta)
 		}
 	}
 
@@ -46,20 +166,21 @@ func (c Cart) Diff(old Cart) Cart {
 }
 
 func (c Cart) HasBooks() bool {
-	return len(c.bookIDs) > 0
+	return len(c.items) > 0
 }
 
+// Equal reports whether both carts belong to the same user and hold the same copies.
 func (c Cart) Equal(other Cart) bool {
 	if c.userID != other.userID {
 		return false
 	}
 
-	if len(c.bookIDs) != len(other.bookIDs) {
+	if len(c.items) != len(other.items) {
 		return false
 	}
 
-	for i, bookID := range c.bookIDs {
-		if bookID != other.bookIDs[i] {
+	for _, item := range c.items {
+		if other.Quantity(item.bookID) != item.quantity {
 			return false
 		}
 	}
@@ -67,24 +188,64 @@ func (c Cart) Equal(other Cart) bool {
 	return true
 }
 
+// Join returns a cart with every book of both carts, taking the larger quantity of the two.
 func (c Cart) Join(other Cart) Cart {
-	bookIDs := map[int]struct{}{}
-	for _, bookID := range c.bookIDs {
-		bookIDs[bookID] = struct{}{}
+	joined := Cart{
+		userID: c.userID,
+		items:  []CartItem{},
 	}
 
-	for _, bookID := range other.bookIDs {
-		bookIDs[bookID] = struct{}{}
+	for _, item := range c.items {
+		joined = joined.add(item.bookID, max(item.quantity, other.Quantity(item.bookID)))
 	}
 
-	joined := Cart{
-		userID:  c.userID,
-		bookIDs: []int{},
+	for _, item := range other.items {
+		if c.Quantity(item.bookID) == 0 {
+			joined = joined.add(item.bookID, item.quantity)
+		}
+	}
+
+	return joined
+}
+
+// WithQuantity returns a copy of the cart holding quantity copies of the book.
+// A quantity of zero or less removes the book from the cart.
+func (c Cart) WithQuantity(bookID, quantity int) Cart {
+	items := make([]CartItem, 0, len(c.items)+1)
+	for _, item := range c.items {
+		if item.bookID != bookID {
+			items = append(items, item)
+		}
 	}
 
-	for bookID := range bookIDs {
-		joined.bookIDs = append(joined.bookIDs, bookID)
+	c.items = items
+	if quantity > 0 {
+		c = c.add(bookID, quantity)
 	}
 
-	return joined
+	return c
+}
+
+// add returns a copy of the cart with quantity more copies of the book.
+func (c Cart) add(bookID, quantity int) Cart {
+	return c.addItem(CartItem{bookID: bookID, quantity: quantity})
+}
+
+// addItem returns a copy of the cart with the item's copies added.
+func (c Cart) addItem(newItem CartItem) Cart {
+	items := make([]CartItem, 0, len(c.items)+1)
+	added := false
+	for _, item := range c.items {
+		if item.bookID == newItem.bookID {
+			item.quantity += newItem.quantity
+			added = true
+		}
+		items = append(items, item)
+	}
+	if !added {
+		items = append(items, newItem)
+	}
+
+	c.items = items
+	return c
 }
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCart(t *testing.T, quantities map[int]int) Cart {
+	t.Helper()
+
+	items := make([]CartItem, 0, len(quantities))
+	for bookID, quantity := range quantities {
+		item, err := NewCartItem(NewCartItemData{BookID: bookID, Quantity: quantity})
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+
+	cart, err := NewCart(NewCartData{UserID: 1, Items: items})
+	require.NoError(t, err)
+
+	return cart
+}
+
+func TestNewCart_MergesLegacyBookIDs(t *testing.T) {
+	item, err := NewCartItem(NewCartItemData{BookID: 1, Quantity: 2})
+	require.NoError(t, err)
+
+	cart, err := NewCart(NewCartData{UserID: 1, Items: []CartItem{item}, BookIDs: []int{1, 2, 2}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, cart.Quantity(1))
+	assert.Equal(t, 2, cart.Quantity(2))
+	assert.Equal(t, []int{1, 2}, cart.BookIDs())
+}
+
+func TestNewCartItem_Invalid(t *testing.T) {
+	_, err := NewCartItem(NewCartItemData{BookID: 0, Quantity: 1})
+	assert.ErrorIs(t, err, ErrInvalidBookIDs)
+
+	_, err = NewCartItem(NewCartItemData{BookID: 1, Quantity: 0})
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+}
+
+func TestCart_Diff(t *testing.T) {
+	oldCart := newTestCart(t, map[int]int{1: 2, 2: 1, 3: 4})
+	newCart := newTestCart(t, map[int]int{1: 5, 2: 1, 4: 1})
+
+	added := newCart.Diff(oldCart)
+	assert.Equal(t, 3, added.Quantity(1))
+	assert.Equal(t, 0, added.Quantity(2))
+	assert.Equal(t, 1, added.Quantity(4))
+	assert.Len(t, added.Items(), 2)
+
+	removed := oldCart.Diff(newCart)
+	assert.Equal(t, 4, removed.Quantity(3))
+	assert.Len(t, removed.Items(), 1)
+}
+
+func TestCart_Join(t *testing.T) {
+	oldCart := newTestCart(t, map[int]int{1: 2, 2: 1})
+	newCart := newTestCart(t, map[int]int{1: 1, 3: 3})
+
+	joined := oldCart.Join(newCart)
+	assert.Equal(t, 2, joined.Quantity(1))
+	assert.Equal(t, 1, joined.Quantity(2))
+	assert.Equal(t, 3, joined.Quantity(3))
+	assert.Len(t, joined.Items(), 3)
+}
+
+func TestCart_Equal(t *testing.T) {
+	cart := newTestCart(t, map[int]int{1: 2, 2: 1})
+
+	assert.True(t, cart.Equal(newTestCart(t, map[int]int{2: 1, 1: 2})))
+	assert.False(t, cart.Equal(newTestCart(t, map[int]int{1: 1, 2: 1})))
+	assert.False(t, cart.Equal(newTestCart(t, map[int]int{1: 2})))
+}
+
+func TestCart_SubtotalAndExpiry(t *testing.T) {
+	book, err := NewBook(NewBookData{ID: 1, Title: "1984", Year: 1949, Author: "George Orwell", Price: 1500})
+	require.NoError(t, err)
+	item, err := NewCartItem(NewCartItemData{BookID: 1, Quantity: 2, Book: book})
+	require.NoError(t, err)
+
+	updatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cart, err := NewCart(NewCartData{UserID: 1, Items: []CartItem{item}, UpdatedAt: updatedAt})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3000, cart.Subtotal())
+	assert.True(t, cart.ExpiresAt().IsZero())
+	assert.Equal(t, updatedAt.Add(time.Minute), cart.WithReservationTTL(time.Minute).ExpiresAt())
+}
+
+func TestCart_WithQuantity(t *testing.T) {
+	cart := newTestCart(t, map[int]int{1: 2, 2: 1})
+
+	updated := cart.WithQuantity(1, 5)
+	assert.Equal(t, 5, updated.Quantity(1))
+	assert.Equal(t, 1, updated.Quantity(2))
+	assert.Equal(t, 2, cart.Quantity(1))
+
+	added := cart.WithQuantity(3, 1)
+	assert.Equal(t, 1, added.Quantity(3))
+	assert.Len(t, added.Items(), 3)
+
+	removed := cart.WithQuantity(2, 0)
+	assert.Equal(t, 0, removed.Quantity(2))
+	assert.Len(t, removed.Items(), 1)
+}
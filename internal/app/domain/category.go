@@ -1,21 +1,57 @@
 package domain
 
+import (
+	"fmt"
+	"time"
+)
+
 // Category is a domain category.
 type Category struct {
-	id   int
-	name string
+	id        int
+	name      string
+	parentID  int
+	stats     *CategoryStats
+	deletedAt time.Time
 }
 
 type NewCategoryData struct {
 	ID   int
 	Name string
+	// ParentID is the parent category ID, 0 for a top-level category.
+	ParentID int
+	Stats    *CategoryStats
+	// DeletedAt is zero for the categories that are not deleted.
+	DeletedAt time.Time
+}
+
+// CategoryStats summarises the books in stock in a category.
+type CategoryStats struct {
+	InStockBooks int
+	// MinPrice and MaxPrice are zero when there are no books in stock.
+	MinPrice int
+	MaxPrice int
+	// NewestBook is the book added last, nil when there are no books in stock.
+	NewestBook *CategoryBook
 }
 
-// NewCategory creates a new category.
+// CategoryBook is a book shown in a category summary.
+type CategoryBook struct {
+	ID    int
+	Title string
+}
+
+// NewCategory creates a new category. A category can't be its own parent.
 func NewCategory(data NewCategoryData) (Category, error) {
+	if data.ParentID != 0 && data.ParentID == data.ID {
+		return Category{}, fmt.Errorf("%w: category %d is its own parent", ErrCategoryCycle, data.ID)
+	}
+
 	return Category{
-		id:   data.ID,
-		name: data.Name,
+		id:        data.ID,
+		name:      data.Name,
+		parentID:  data.ParentID,
+		stats:     data.Stats,
+		deletedAt: data.DeletedAt,
 	}, nil
 }
 
@@ -28,3 +64,66 @@ func (b Category) ID() int {
 func (b Category) Name() string {
 	return b.name
 }
+
+// ParentID returns the parent category ID, 0 for a top-level category.
+func (b Category) ParentID() int {
+	return b.parentID
+}
+
+// Stats returns the summary of the books in the category, nil when it was not asked for.
+func (b Category) Stats() *CategoryStats {
+	return b.stats
+}
+
+// DeletedAt returns the time the category was deleted, zero when it is not deleted.
+func (b Category) DeletedAt() time.Time {
+	return b.deletedAt
+}
+
+// CategoryNotEmptyError is returned when deleting a category that still has books.
+// It matches ErrCategoryNotEmpty with errors.Is.
+type CategoryNotEmptyError struct {
+	CategoryID int
+	Books      int
+}
+
+func (e CategoryNotEmptyError) Error() string {
+	return fmt.Sprintf("%s: category %d has %d books", ErrCategoryNotEmpty, e.CategoryID, e.Books)
+}
+
+func (e CategoryNotEmptyError) Is(target error) bool {
+	return target == ErrCategoryNotEmpty
+}
+
+// CategoryNode is a category with its subcategories.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode
+}
+
+// NewCategoryTree arranges the categories in a tree, keeping their order among siblings.
+// Categories whose parent is not in the list are top-level.
+func NewCategoryTree(categories []Category) []CategoryNode {
+	known := make(map[int]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID()] = true
+	}
+	children := make(map[int][]Category, len(categories))
+	for _, category := range categories {
+		parentID := category.ParentID()
+		if !known[parentID] {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], category)
+	}
+
+	var build func(parentID int) []CategoryNode
+	build = func(parentID int) []CategoryNode {
+		nodes := make([]CategoryNode, 0, len(children[parentID]))
+		for _, category := range children[parentID] {
+			nodes = append(nodes, CategoryNode{Category: category, Children: build(category.ID())})
+		}
+		return nodes
+	}
+	return build(0)
+}
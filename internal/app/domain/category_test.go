@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCategory_OwnParent(t *testing.T) {
+	_, err := NewCategory(NewCategoryData{ID: 1, Name: "Fiction", ParentID: 1})
+	require.ErrorIs(t, err, ErrCategoryCycle)
+}
+
+func TestNewCategoryTree(t *testing.T) {
+	newCategory := func(id, parentID int) Category {
+		category, err := NewCategory(NewCategoryData{ID: id, Name: "Category", ParentID: parentID})
+		require.NoError(t, err)
+		return category
+	}
+	ids := func(nodes []CategoryNode) []int {
+		result := make([]int, 0, len(nodes))
+		for _, node := range nodes {
+			result = append(result, node.ID())
+		}
+		return result
+	}
+
+	// 1 Fiction -> 3 Fantasy -> 4 Epic Fantasy, 2 Science, 5 has a parent that is not listed
+	tree := NewCategoryTree([]Category{newCategory(1, 0), newCategory(2, 0), newCategory(3, 1),
+		newCategory(4, 3), newCategory(5, 42)})
+
+	assert.Equal(t, []int{1, 2, 5}, ids(tree))
+	assert.Equal(t, []int{3}, ids(tree[0].Children))
+	assert.Equal(t, []int{4}, ids(tree[0].Children[0].Children))
+	assert.Empty(t, tree[0].Children[0].Children[0].Children)
+	assert.Empty(t, tree[1].Children)
+}
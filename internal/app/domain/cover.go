@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"path"
+	"strings"
+	"time"
+
+	// Register the decoders of the accepted cover formats.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// CoverSize is a variant of a book cover.
+type CoverSize string
+
+const (
+	CoverSizeOriginal CoverSize = "original"
+	CoverSizeMedium   CoverSize = "medium"
+	CoverSizeSmall    CoverSize = "small"
+)
+
+// CoverThumbnailWidths are the widths of the cover thumbnails, in pixels.
+var CoverThumbnailWidths = map[CoverSize]int{
+	CoverSizeMedium: 600,
+	CoverSizeSmall:  200,
+}
+
+const (
+	// maxCoverPixels guards against small files that decode to huge images, a decoded RGBA cover
+	// takes up to 4 bytes a pixel.
+	maxCoverPixels = 16_000_000
+	// coverThumbnailQuality is the JPEG quality of the thumbnails.
+	coverThumbnailQuality = 85
+)
+
+// coverExtensions are the accepted cover formats, by the name image.Decode gives them.
+var coverExtensions = map[string]string{
+	"jpeg": ".jpg",
+	"png":  ".png",
+	"gif":  ".gif",
+}
+
+// CoverImage is a decoded cover upload.
+type CoverImage struct {
+	data   []byte
+	format string
+	ext    string
+	hash   string
+	bounds image.Rectangle
+	image  image.Image
+}
+
+// DecodeCover decodes a JPEG, PNG or GIF cover.
+func DecodeCover(data []byte) (CoverImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return CoverImage{}, fmt.Errorf("%w: %w", ErrInvalidCover, err)
+	}
+	ext, ok := coverExtensions[format]
+	if !ok {
+		return CoverImage{}, fmt.Errorf("%w: unsupported format %s", ErrInvalidCover, format)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxCoverPixels {
+		return CoverImage{}, fmt.Errorf("%w: bad dimensions %dx%d", ErrInvalidCover, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return CoverImage{}, fmt.Errorf("%w: %w", ErrInvalidCover, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return CoverImage{
+		data:   data,
+		format: format,
+		ext:    ext,
+		hash:   hex.EncodeToString(sum[:8]),
+		bounds: img.Bounds(),
+		image:  img,
+	}, nil
+}
+
+// Data returns the uploaded bytes.
+func (c CoverImage) Data() []byte {
+	return c.data
+}
+
+// ContentType returns the media type of the uploaded bytes.
+func (c CoverImage) ContentType() string {
+	return "image/" + c.format
+}
+
+// Key returns the blob key of the cover of a book. The key changes with the cover content,
+// so a cover can be cached forever.
+func (c CoverImage) Key(bookID int) string {
+	return fmt.Sprintf("book-%d-%s%s", bookID, c.hash, c.ext)
+}
+
+// Thumbnail scales the cover down to the width, keeping its aspect ratio, and encodes it as JPEG.
+// Covers narrower than the width are only re-encoded.
+func (c CoverImage) Thumbnail(width int) ([]byte, error) {
+	srcWidth, srcHeight := c.bounds.Dx(), c.bounds.Dy()
+	if width > srcWidth {
+		width = srcWidth
+	}
+	height := max(srcHeight*width/srcWidth, 1)
+
+	pixel := pixelReader(c.image)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			dst.SetRGBA(x, y, c.average(pixel, x0, y0, x1, y1))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: coverThumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode a thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// average is the mean color of a box of source pixels, composited over white
+// since JPEG has no transparency.
+func (c CoverImage) average(pixel pixelFunc, x0, y0, x1, y1 int) color.RGBA {
+	var r, g, b, n uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := pixel(c.bounds.Min.X+x, c.bounds.Min.Y+y)
+			white := 0xff - uint64(pa)
+			r += uint64(pr) + white
+			g += uint64(pg) + white
+			b += uint64(pb) + white
+			n++
+		}
+	}
+	return color.RGBA{
+		R: uint8(r / n),
+		G: uint8(g / n),
+		B: uint8(b / n),
+		A: 0xff,
+	}
+}
+
+// pixelFunc returns the alpha-premultiplied 8-bit color of a source pixel.
+type pixelFunc func(x, y int) (r, g, b, a uint8)
+
+// pixelReader reads the pixel buffers of the image types the decoders return directly, since
+// image.At allocates a color for every pixel.
+func pixelReader(img image.Image) pixelFunc {
+	switch img := img.(type) {
+	case *image.YCbCr:
+		return func(x, y int) (r, g, b, a uint8) {
+			yi, ci := img.YOffset(x, y), img.COffset(x, y)
+			r, g, b = color.YCbCrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+			return r, g, b, 0xff
+		}
+	case *image.RGBA:
+		return func(x, y int) (r, g, b, a uint8) {
+			p := img.Pix[img.PixOffset(x, y):]
+			return p[0], p[1], p[2], p[3]
+		}
+	case *image.NRGBA:
+		return func(x, y int) (r, g, b, a uint8) {
+			p := img.Pix[img.PixOffset(x, y):]
+			return premultiply(p[0], p[3]), premultiply(p[1], p[3]), premultiply(p[2], p[3]), p[3]
+		}
+	case *image.Gray:
+		return func(x, y int) (r, g, b, a uint8) {
+			v := img.Pix[img.PixOffset(x, y)]
+			return v, v, v, 0xff
+		}
+	case *image.Paletted:
+		palette := make([]color.RGBA, len(img.Palette))
+		for i, c := range img.Palette {
+			palette[i] = color.RGBAModel.Convert(c).(color.RGBA)
+		}
+		return func(x, y int) (r, g, b, a uint8) {
+			i := int(img.Pix[img.PixOffset(x, y)])
+			if i >= len(palette) {
+				return 0, 0, 0, 0
+			}
+			c := palette[i]
+			return c.R, c.G, c.B, c.A
+		}
+	default:
+		return func(x, y int) (r, g, b, a uint8) {
+			cr, cg, cb, ca := img.At(x, y).RGBA()
+			return uint8(cr >> 8), uint8(cg >> 8), uint8(cb >> 8), uint8(ca >> 8)
+		}
+	}
+}
+
+// premultiply scales a non-premultiplied 8-bit color channel by the alpha.
+func premultiply(v, alpha uint8) uint8 {
+	return uint8(uint16(v) * uint16(alpha) / 0xff)
+}
+
+// CoverVariantKey returns the blob key of a cover variant. Thumbnails are always JPEG.
+func CoverVariantKey(coverKey string, size CoverSize) string {
+	if size == CoverSizeOriginal {
+		return coverKey
+	}
+	return strings.TrimSuffix(coverKey, path.Ext(coverKey)) + "-" + string(size) + ".jpg"
+}
+
+// Blob is a stored file.
+type Blob struct {
+	Data        []byte
+	ContentType string
+	ModTime     time.Time
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCover(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 800))
+	for y := 0; y < 800; y++ {
+		for x := 0; x < 400; x++ {
+			img.Set(x, y, color.NRGBA{R: 200, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	cover, err := DecodeCover(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", cover.ContentType())
+	assert.Regexp(t, `^book-7-[0-9a-f]{16}\.png$`, cover.Key(7))
+
+	thumbnail, err := cover.Thumbnail(200)
+	require.NoError(t, err)
+	decoded, err := jpeg.Decode(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 200, 400), decoded.Bounds())
+	r, g, b, _ := decoded.At(100, 200).RGBA()
+	assert.InDelta(t, 200, r>>8, 4)
+	assert.InDelta(t, 0, g>>8, 4)
+	assert.InDelta(t, 0, b>>8, 4)
+
+	// Small covers are not scaled up
+	thumbnail, err = cover.Thumbnail(600)
+	require.NoError(t, err)
+	decoded, err = jpeg.Decode(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 400, 800), decoded.Bounds())
+
+	_, err = DecodeCover([]byte("not an image"))
+	require.ErrorIs(t, err, ErrInvalidCover)
+}
+
+func TestPixelReader(t *testing.T) {
+	rect := image.Rect(1, 2, 5, 6)
+	palette := color.Palette{color.RGBA{R: 10, G: 20, B: 30, A: 0xff}, color.NRGBA{R: 200, G: 100, B: 50, A: 0x80}}
+	images := map[string]image.Image{
+		"ycbcr":    image.NewYCbCr(rect, image.YCbCrSubsampleRatio420),
+		"rgba":     image.NewRGBA(rect),
+		"nrgba":    image.NewNRGBA(rect),
+		"gray":     image.NewGray(rect),
+		"paletted": image.NewPaletted(rect, palette),
+		"gray16":   image.NewGray16(rect),
+	}
+	for name, img := range images {
+		t.Run(name, func(t *testing.T) {
+			// fill the buffers through the generic setters and read them back through the fast path
+			if yCbCr, ok := img.(*image.YCbCr); ok {
+				for i := range yCbCr.Y {
+					yCbCr.Y[i] = uint8(i * 13)
+				}
+				for i := range yCbCr.Cb {
+					yCbCr.Cb[i], yCbCr.Cr[i] = uint8(i*29), uint8(255-i*17)
+				}
+			}
+			settable, ok := img.(interface{ Set(x, y int, c color.Color) })
+			for y := rect.Min.Y; y < rect.Max.Y && ok; y++ {
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+					settable.Set(x, y, color.NRGBA{R: uint8(x * 40), G: uint8(y * 30), B: 90, A: uint8(x*60 + y)})
+				}
+			}
+
+			pixel := pixelReader(img)
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				for x := rect.Min.X; x < rect.Max.X; x++ {
+					wr, wg, wb, wa := img.At(x, y).RGBA()
+					r, g, b, a := pixel(x, y)
+					assert.InDelta(t, wr>>8, r, 1, "red at %d,%d", x, y)
+					assert.InDelta(t, wg>>8, g, 1, "green at %d,%d", x, y)
+					assert.InDelta(t, wb>>8, b, 1, "blue at %d,%d", x, y)
+					assert.InDelta(t, wa>>8, a, 1, "alpha at %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestThumbnail_Allocations(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1000, 1000)), nil))
+	cover, err := DecodeCover(buf.Bytes())
+	require.NoError(t, err)
+
+	// the source pixels are read without allocating, only the thumbnail and its encoder allocate
+	allocs := testing.AllocsPerRun(3, func() {
+		_, err := cover.Thumbnail(100)
+		require.NoError(t, err)
+	})
+	assert.Less(t, allocs, 1000.0)
+}
+
+func TestCoverVariantKey(t *testing.T) {
+	assert.Equal(t, "book-1-abc.png", CoverVariantKey("book-1-abc.png", CoverSizeOriginal))
+	assert.Equal(t, "book-1-abc-small.jpg", CoverVariantKey("book-1-abc.png", CoverSizeSmall))
+	assert.Equal(t, "book-1-abc-medium.jpg", CoverVariantKey("book-1-abc.jpg", CoverSizeMedium))
+}
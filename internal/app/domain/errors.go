@@ -3,11 +3,38 @@ package domain
 import "errors"
 
 var (
-	ErrRequired        = errors.New("required value")
-	ErrNotFound        = errors.New("not found")
-	ErrNil             = errors.New("nil data")
-	ErrNegative        = errors.New("negative value")
-	ErrInvalidUserID   = errors.New("invalid user ID")
-	ErrInvalidBookIDs  = errors.New("invalid book IDs")
-	ErrNoUserInContext = errors.New("no user in context")
+	ErrRequired           = errors.New("required value")
+	ErrNotFound           = errors.New("not found")
+	ErrNil                = errors.New("nil data")
+	ErrNegative           = errors.New("negative value")
+	ErrInvalidUserID      = errors.New("invalid user ID")
+	ErrInvalidBookIDs     = errors.New("invalid book IDs")
+	ErrInvalidAuthorIDs   = errors.New("invalid author IDs")
+	ErrInvalidCategoryIDs = errors.New("invalid category IDs")
+	ErrInvalidQuantity    = errors.New("invalid quantity")
+	ErrNoUserInContext    = errors.New("no user in context")
+
+	ErrInvalidRange     = errors.New("invalid range")
+	ErrInvalidSortField = errors.New("invalid sort field")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+
+	ErrInvalidCover = errors.New("invalid cover image")
+
+	ErrInvalidAuditEntity = errors.New("invalid audit entity")
+
+	ErrIdempotencyKeyNotHeld = errors.New("idempotency key is held by another request")
+
+	ErrInvalidISBN  = errors.New("invalid ISBN")
+	ErrISBNConflict = errors.New("a book with this ISBN already exists")
+
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryNotEmpty = errors.New("category not empty")
+	ErrCategoryCycle    = errors.New("category cycle")
+
+	ErrAuthorNotFound  = errors.New("author not found")
+	ErrAuthorConflict  = errors.New("an author with this name already exists")
+	ErrAuthorSelfMerge = errors.New("an author can't be merged into itself")
+
+	ErrInvalidOrderStatus   = errors.New("invalid order status")
+	ErrPaymentNotAuthorized = errors.New("payment not authorized")
 )
@@ -0,0 +1,112 @@
+package domain
+
+import "time"
+
+// IdempotencyKey is a client-supplied key that makes a mutating request safe to retry.
+// Until the first request completes, the key holds no response, and the request holds the key
+// until its lease runs out.
+type IdempotencyKey struct {
+	key            string
+	userID         int
+	fingerprint    string
+	responseStatus int
+	responseBody   []byte
+	expiresAt      time.Time
+	lockedUntil    time.Time
+	owner          string
+}
+
+type NewIdempotencyKeyData struct {
+	Key            string
+	UserID         int
+	Fingerprint    string
+	ResponseStatus int
+	ResponseBody   []byte
+	ExpiresAt      time.Time
+	LockedUntil    time.Time
+	Owner          string
+}
+
+// NewIdempotencyKey creates a new idempotency key.
+func NewIdempotencyKey(data NewIdempotencyKeyData) (IdempotencyKey, error) {
+	if data.Key == "" {
+		return IdempotencyKey{}, ErrRequired
+	}
+	if data.UserID == 0 {
+		return IdempotencyKey{}, ErrInvalidUserID
+	}
+
+	return IdempotencyKey{
+		key:            data.Key,
+		userID:         data.UserID,
+		fingerprint:    data.Fingerprint,
+		responseStatus: data.ResponseStatus,
+		responseBody:   data.ResponseBody,
+		expiresAt:      data.ExpiresAt,
+		lockedUntil:    data.LockedUntil,
+		owner:          data.Owner,
+	}, nil
+}
+
+// Key returns the key itself.
+func (k IdempotencyKey) Key() string {
+	return k.key
+}
+
+// UserID returns the ID of the user who owns the key.
+func (k IdempotencyKey) UserID() int {
+	return k.userID
+}
+
+// Fingerprint returns the hash of the request the key was first used with.
+func (k IdempotencyKey) Fingerprint() string {
+	return k.fingerprint
+}
+
+// ResponseStatus returns the stored HTTP status.
+func (k IdempotencyKey) ResponseStatus() int {
+	return k.responseStatus
+}
+
+// ResponseBody returns the stored serialized response.
+func (k IdempotencyKey) ResponseBody() []byte {
+	return k.responseBody
+}
+
+// ExpiresAt returns the time after which the key can be reused.
+func (k IdempotencyKey) ExpiresAt() time.Time {
+	return k.expiresAt
+}
+
+// LockedUntil returns the time the lease of the request holding the key runs out.
+func (k IdempotencyKey) LockedUntil() time.Time {
+	return k.lockedUntil
+}
+
+// Owner returns the random ID of the request holding the key.
+func (k IdempotencyKey) Owner() string {
+	return k.owner
+}
+
+// Abandoned reports whether the request holding the key did not complete before its lease ran out.
+func (k IdempotencyKey) Abandoned(now time.Time) bool {
+	return !k.Completed() && k.lockedUntil.Before(now)
+}
+
+// Completed reports whether the first request has finished and its response is stored.
+func (k IdempotencyKey) Completed() bool {
+	return k.responseStatus != 0
+}
+
+// WithLockedUntil returns a copy of the key whose lease runs out at the given time.
+func (k IdempotencyKey) WithLockedUntil(lockedUntil time.Time) IdempotencyKey {
+	k.lockedUntil = lockedUntil
+	return k
+}
+
+// WithResponse returns a copy of the key holding the given response.
+func (k IdempotencyKey) WithResponse(status int, body []byte) IdempotencyKey {
+	k.responseStatus = status
+	k.responseBody = body
+	return k
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeISBN validates an ISBN-10 or ISBN-13 and returns it as 13 digits without separators.
+// Hyphens and spaces are ignored.
+func NormalizeISBN(isbn string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidISBN, isbn)
+		}
+		isbn13 := "978" + digits[:9]
+		return isbn13 + string(isbn13CheckDigit(isbn13)), nil
+	case 13:
+		if !allDigits(digits) || isbn13CheckDigit(digits[:12]) != digits[12] {
+			return "", fmt.Errorf("%w: %q", ErrInvalidISBN, isbn)
+		}
+		return digits, nil
+	default:
+		return "", fmt.Errorf("%w: %q must have 10 or 13 digits", ErrInvalidISBN, isbn)
+	}
+}
+
+// validISBN10 checks the weighted sum of an ISBN-10, whose check digit can be X for 10.
+func validISBN10(digits string) bool {
+	if !allDigits(digits[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(digits[i]-'0')
+	}
+	switch check := digits[9]; {
+	case check == 'X':
+		sum += 10
+	case check >= '0' && check <= '9':
+		sum += int(check - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit computes the check digit of the first 12 digits of an ISBN-13.
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(digits[i]-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		isbn     string
+		expected string
+	}{
+		{"9780306406157", "9780306406157"},
+		{"978-0-306-40615-7", "9780306406157"},
+		{"0-306-40615-2", "9780306406157"},
+		{"0 8044 2957 x", "9780804429573"},
+		{"080442957X", "9780804429573"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.isbn, func(t *testing.T) {
+			isbn, err := NormalizeISBN(tt.isbn)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, isbn)
+		})
+	}
+}
+
+func TestNormalizeISBN_Invalid(t *testing.T) {
+	for _, isbn := range []string{
+		"",
+		"978030640615",
+		"9780306406158",
+		"0-306-40615-3",
+		"97803064061X7",
+		"X306406152",
+		"abcdefghij",
+	} {
+		t.Run(isbn, func(t *testing.T) {
+			_, err := NormalizeISBN(isbn)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidISBN))
+		})
+	}
+}
+
+func TestNewBook_NormalizesISBN(t *testing.T) {
+	book, err := NewBook(NewBookData{Title: "Title", ISBN: "0-306-40615-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "9780306406157", book.ISBN())
+
+	_, err = NewBook(NewBookData{Title: "Title", ISBN: "0-306-40615-3"})
+	require.ErrorIs(t, err, ErrInvalidISBN)
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+)
+
+// OrderStatus is a status of an order.
+type OrderStatus string
+
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusPaid      OrderStatus = "paid"
+	OrderStatusFulfilled OrderStatus = "fulfilled"
+	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusRefunded  OrderStatus = "refunded"
+
+	// An order placed by checkout is capturing until checkout has captured its payment.
+	OrderStatusCapturing OrderStatus = "capturing"
+
+	// A paid order is cancelling or refunding while its payment is being refunded.
+	OrderStatusCancelling OrderStatus = "cancelling"
+	OrderStatusRefunding  OrderStatus = "refunding"
+)
+
+// orderTransitions lists the statuses an order can move to from each status.
+// Cancelled and refunded orders are final. A capturing order is only moved on by checkout,
+// see checkoutTransitions.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusCancelled},
+	OrderStatusCapturing:  {},
+	OrderStatusPaid:       {OrderStatusFulfilled, OrderStatusCancelled, OrderStatusCancelling},
+	OrderStatusFulfilled:  {OrderStatusRefunded, OrderStatusRefunding},
+	OrderStatusCancelling: {OrderStatusCancelled},
+	OrderStatusRefunding:  {OrderStatusRefunded},
+	OrderStatusCancelled:  {},
+	OrderStatusRefunded:   {},
+}
+
+// checkoutTransitions lists the statuses checkout moves a capturing order to, once its payment is
+// captured or the capture failed. Paid orders come from here only.
+var checkoutTransitions = []OrderStatus{OrderStatusPaid, OrderStatusCancelled}
+
+// refundingStatuses maps the statuses that give the money back to the status a paid order is in
+// while its payment is being refunded.
+var refundingStatuses = map[OrderStatus]OrderStatus{
+	OrderStatusCancelled: OrderStatusCancelling,
+	OrderStatusRefunded:  OrderStatusRefunding,
+}
+
+// Valid reports whether the status is a known order status.
+func (s OrderStatus) Valid() bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether an order in this status can be moved to the next one.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// RefundingStatus returns the status a paid order is in while it moves to this status and its
+// payment is refunded, and false for the statuses that don't give the money back.
+func (s OrderStatus) RefundingStatus() (OrderStatus, bool) {
+	refunding, ok := refundingStatuses[s]
+	return refunding, ok
+}
+
+// Refunding reports whether the order payment is being refunded.
+func (s OrderStatus) Refunding() bool {
+	return s == OrderStatusCancelling || s == OrderStatusRefunding
+}
+
+// OrderFilter narrows down a list of orders. Zero values are ignored.
+type OrderFilter struct {
+	UserID int
+	Status OrderStatus
+	From   time.Time
+	To     time.Time
+}
+
+// OrderItem is a domain order item.
+type OrderItem struct {
+	bookID   int
+	title    string
+	author   string
+	price    int
+	quantity int
+}
+
+type NewOrderItemData struct {
+	BookID   int
+	Title    string
+	Author   string
+	Price    int
+	Quantity int
+}
+
+// NewOrderItem creates a new order item.
+func NewOrderItem(data NewOrderItemData) (OrderItem, error) {
+	return OrderItem{
+		bookID:   data.BookID,
+		title:    data.Title,
+		author:   data.Author,
+		price:    data.Price,
+		quantity: data.Quantity,
+	}, nil
+}
+
+// BookID returns the ID of the purchased book.
+func (i OrderItem) BookID() int {
+	return i.bookID
+}
+
+// Title returns the book title at the moment of purchase.
+func (i OrderItem) Title() string {
+	return i.title
+}
+
+// Author returns the book author at the moment of purchase.
+func (i OrderItem) Author() string {
+	return i.author
+}
+
+// Price returns the price paid for a single copy.
+func (i OrderItem) Price() int {
+	return i.price
+}
+
+// Quantity returns the number of purchased copies.
+func (i OrderItem) Quantity() int {
+	return i.quantity
+}
+
+// Order is a domain order.
+type Order struct {
+	id        int
+	userID    int
+	status    OrderStatus
+	total     int
+	items     []OrderItem
+	paymentID string
+	createdAt time.Time
+}
+
+type NewOrderData struct {
+	ID        int
+	UserID    int
+	Status    OrderStatus
+	Items     []OrderItem
+	PaymentID string
+	CreatedAt time.Time
+}
+
+// NewOrder creates a new order. The total is calculated from the items.
+func NewOrder(data NewOrderData) (Order, error) {
+	if data.UserID == 0 {
+		return Order{}, ErrInvalidUserID
+	}
+
+	total := 0
+	for _, item := range data.Items {
+		total += item.price * item.quantity
+	}
+
+	return Order{
+		id:        data.ID,
+		userID:    data.UserID,
+		status:    data.Status,
+		total:     total,
+		items:     data.Items,
+		paymentID: data.PaymentID,
+		createdAt: data.CreatedAt,
+	}, nil
+}
+
+// ID returns the order ID.
+func (o Order) ID() int {
+	return o.id
+}
+
+// UserID returns the ID of the user who placed the order.
+func (o Order) UserID() int {
+	return o.userID
+}
+
+// Status returns the order status.
+func (o Order) Status() OrderStatus {
+	return o.status
+}
+
+// Total returns the order total.
+func (o Order) Total() int {
+	return o.total
+}
+
+// Items returns the order items.
+func (o Order) Items() []OrderItem {
+	return o.items
+}
+
+// PaymentID returns the ID of the payment the order was paid with.
+func (o Order) PaymentID() string {
+	return o.paymentID
+}
+
+// CreatedAt returns the time the order was placed.
+func (o Order) CreatedAt() time.Time {
+	return o.createdAt
+}
+
+// WithStatus returns a copy of the order moved to the given status.
+// Invalid transitions are rejected with a conflict error, and so is any change to a capturing order.
+func (o Order) WithStatus(status OrderStatus) (Order, error) {
+	if !status.Valid() {
+		return Order{}, slugerrors.NewBadRequestError(fmt.Sprintf("unknown order status %q", status),
+			"invalid-order-status")
+	}
+	if o.status == OrderStatusCapturing {
+		return Order{}, slugerrors.NewConflictError("the order payment is being captured",
+			"order-payment-in-progress")
+	}
+	if !o.status.CanTransitionTo(status) {
+		return Order{}, slugerrors.NewConflictError(
+			fmt.Sprintf("order can't be moved from %q to %q", o.status, status), "invalid-order-status-transition")
+	}
+
+	o.status = status
+	return o, nil
+}
+
+// WithCheckoutStatus returns a copy of the capturing order moved to paid or cancelled by checkout.
+func (o Order) WithCheckoutStatus(status OrderStatus) (Order, error) {
+	if o.status != OrderStatusCapturing || !slices.Contains(checkoutTransitions, status) {
+		return Order{}, fmt.Errorf("%w: checkout can't move an order from %q to %q",
+			ErrInvalidOrderStatus, o.status, status)
+	}
+
+	o.status = status
+	return o, nil
+}
+
+// RestoresStock reports whether moving to the given status should put the ordered books back on stock.
+func (o Order) RestoresStock(status OrderStatus) bool {
+	return status == OrderStatusCancelled && o.status != OrderStatusFulfilled
+}
+
+// WithPayment returns a copy of the pending order with the given authorized payment. The order is
+// capturing until checkout captures the payment and moves it to paid.
+func (o Order) WithPayment(payment Payment) (Order, error) {
+	if o.status != OrderStatusPending {
+		return Order{}, fmt.Errorf("%w: a %s order can't be paid", ErrInvalidOrderStatus, o.status)
+	}
+	if payment.Status() != PaymentStatusAuthorized {
+		return Order{}, fmt.Errorf("%w: payment is %s", ErrPaymentNotAuthorized, payment.Status())
+	}
+	if payment.Amount() != o.total {
+		return Order{}, slugerrors.NewConflictError(
+			fmt.Sprintf("the cart changed during checkout, %d was authorized for an order of %d", payment.Amount(), o.total),
+			"cart-changed")
+	}
+
+	o.paymentID = payment.ID()
+	o.status = OrderStatusCapturing
+	return o, nil
+}
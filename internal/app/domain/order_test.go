@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder_WithStatus(t *testing.T) {
+	tests := []struct {
+		from    OrderStatus
+		to      OrderStatus
+		allowed bool
+	}{
+		{OrderStatusPending, OrderStatusPaid, false},
+		{OrderStatusPending, OrderStatusCapturing, false},
+		{OrderStatusPending, OrderStatusCancelled, true},
+		{OrderStatusPending, OrderStatusFulfilled, false},
+		{OrderStatusPaid, OrderStatusFulfilled, true},
+		{OrderStatusPaid, OrderStatusCancelled, true},
+		{OrderStatusPaid, OrderStatusPending, false},
+		{OrderStatusFulfilled, OrderStatusRefunded, true},
+		{OrderStatusFulfilled, OrderStatusCancelled, false},
+		{OrderStatusPaid, OrderStatusCancelling, true},
+		{OrderStatusCancelling, OrderStatusCancelled, true},
+		{OrderStatusCancelling, OrderStatusCancelling, false},
+		{OrderStatusFulfilled, OrderStatusRefunding, true},
+		{OrderStatusRefunding, OrderStatusRefunded, true},
+		{OrderStatusRefunding, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPaid, false},
+		{OrderStatusRefunded, OrderStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			order, err := NewOrder(NewOrderData{UserID: 1, Status: tt.from})
+			require.NoError(t, err)
+
+			updated, err := order.WithStatus(tt.to)
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Equal(t, tt.to, updated.Status())
+				return
+			}
+
+			var slugErr slugerrors.SlugError
+			require.True(t, errors.As(err, &slugErr))
+			assert.Equal(t, slugerrors.ErrorTypeConflict, slugErr.ErrorType())
+			assert.Equal(t, "invalid-order-status-transition", slugErr.Slug())
+		})
+	}
+}
+
+func TestOrder_WithStatus_Capturing(t *testing.T) {
+	order, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusCapturing})
+	require.NoError(t, err)
+
+	// only checkout moves a capturing order on
+	for _, status := range []OrderStatus{OrderStatusPaid, OrderStatusCancelled} {
+		_, err = order.WithStatus(status)
+		var slugErr slugerrors.SlugError
+		require.True(t, errors.As(err, &slugErr))
+		assert.Equal(t, slugerrors.ErrorTypeConflict, slugErr.ErrorType())
+		assert.Equal(t, "order-payment-in-progress", slugErr.Slug())
+
+		updated, err := order.WithCheckoutStatus(status)
+		require.NoError(t, err)
+		assert.Equal(t, status, updated.Status())
+	}
+
+	_, err = order.WithCheckoutStatus(OrderStatusFulfilled)
+	require.ErrorIs(t, err, ErrInvalidOrderStatus)
+
+	pending, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusPending})
+	require.NoError(t, err)
+	_, err = pending.WithCheckoutStatus(OrderStatusPaid)
+	require.ErrorIs(t, err, ErrInvalidOrderStatus)
+}
+
+func TestOrder_WithStatus_Unknown(t *testing.T) {
+	order, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusPaid})
+	require.NoError(t, err)
+
+	_, err = order.WithStatus("shipped")
+
+	var slugErr slugerrors.SlugError
+	require.True(t, errors.As(err, &slugErr))
+	assert.Equal(t, "invalid-order-status", slugErr.Slug())
+}
+
+func TestOrder_RestoresStock(t *testing.T) {
+	paid, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusPaid})
+	require.NoError(t, err)
+	fulfilled, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusFulfilled})
+	require.NoError(t, err)
+
+	assert.True(t, paid.RestoresStock(OrderStatusCancelled))
+	assert.False(t, paid.RestoresStock(OrderStatusFulfilled))
+	assert.False(t, fulfilled.RestoresStock(OrderStatusRefunded))
+
+	cancelling, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusCancelling})
+	require.NoError(t, err)
+	assert.True(t, cancelling.RestoresStock(OrderStatusCancelled))
+}
+
+func TestOrder_WithPayment(t *testing.T) {
+	item, err := NewOrderItem(NewOrderItemData{BookID: 1, Price: 1000, Quantity: 2})
+	require.NoError(t, err)
+	order, err := NewOrder(NewOrderData{UserID: 1, Status: OrderStatusPending, Items: []OrderItem{item}})
+	require.NoError(t, err)
+
+	authorized, err := NewPayment(NewPaymentData{ID: "pay_1", Amount: 2000, Status: PaymentStatusAuthorized})
+	require.NoError(t, err)
+	withPayment, err := order.WithPayment(authorized)
+	require.NoError(t, err)
+	assert.Equal(t, "pay_1", withPayment.PaymentID())
+	assert.Equal(t, OrderStatusCapturing, withPayment.Status())
+
+	_, err = withPayment.WithPayment(authorized)
+	require.ErrorIs(t, err, ErrInvalidOrderStatus)
+
+	requiresAction, err := NewPayment(NewPaymentData{ID: "pay_2", Amount: 2000, Status: PaymentStatusRequiresAction})
+	require.NoError(t, err)
+	_, err = order.WithPayment(requiresAction)
+	require.ErrorIs(t, err, ErrPaymentNotAuthorized)
+
+	// the amount was authorized for the cart before its prices changed
+	stale, err := NewPayment(NewPaymentData{ID: "pay_3", Amount: 1500, Status: PaymentStatusAuthorized})
+	require.NoError(t, err)
+	_, err = order.WithPayment(stale)
+	var slugErr slugerrors.SlugError
+	require.True(t, errors.As(err, &slugErr))
+	assert.Equal(t, "cart-changed", slugErr.Slug())
+}
+
+func TestNewOrder_Total(t *testing.T) {
+	item1, err := NewOrderItem(NewOrderItemData{BookID: 1, Price: 1000, Quantity: 2})
+	require.NoError(t, err)
+	item2, err := NewOrderItem(NewOrderItemData{BookID: 2, Price: 500, Quantity: 1})
+	require.NoError(t, err)
+
+	order, err := NewOrder(NewOrderData{UserID: 1, Items: []OrderItem{item1, item2}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2500, order.Total())
+}
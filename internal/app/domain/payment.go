@@ -0,0 +1,56 @@
+package domain
+
+// PaymentStatus is a status of a payment in the payment gateway.
+type PaymentStatus string
+
+const (
+	PaymentStatusAuthorized     PaymentStatus = "authorized"
+	PaymentStatusRequiresAction PaymentStatus = "requires_action"
+	PaymentStatusCaptured       PaymentStatus = "captured"
+	PaymentStatusRefunded       PaymentStatus = "refunded"
+	PaymentStatusVoided         PaymentStatus = "voided"
+)
+
+// Payment is a domain payment.
+type Payment struct {
+	id     string
+	amount int
+	status PaymentStatus
+}
+
+type NewPaymentData struct {
+	ID     string
+	Amount int
+	Status PaymentStatus
+}
+
+// NewPayment creates a new payment.
+func NewPayment(data NewPaymentData) (Payment, error) {
+	if data.ID == "" {
+		return Payment{}, ErrRequired
+	}
+	if data.Amount < 0 {
+		return Payment{}, ErrNegative
+	}
+
+	return Payment{
+		id:     data.ID,
+		amount: data.Amount,
+		status: data.Status,
+	}, nil
+}
+
+// ID returns the payment ID assigned by the gateway.
+func (p Payment) ID() string {
+	return p.id
+}
+
+// Amount returns the payment amount.
+func (p Payment) Amount() int {
+	return p.amount
+}
+
+// Status returns the payment status.
+func (p Payment) Status() PaymentStatus {
+	return p.status
+}
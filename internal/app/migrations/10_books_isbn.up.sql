@@ -0,0 +1,3 @@
+ALTER TABLE books ADD COLUMN isbn text;
+
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn);
@@ -0,0 +1,38 @@
+CREATE TABLE authors
+(
+    id         serial                                 NOT NULL PRIMARY KEY,
+    name       text                                   NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone
+);
+
+-- "Rob Pike" and "rob pike" are the same author
+CREATE UNIQUE INDEX authors_name_key ON authors (lower(name));
+
+CREATE TABLE book_authors
+(
+    book_id   integer NOT NULL,
+    author_id integer NOT NULL,
+    position  integer NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (book_id, author_id),
+    FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES authors (id) ON DELETE CASCADE
+);
+
+CREATE INDEX book_authors_author_id_idx ON book_authors (author_id);
+
+-- One author per spelling of books.author, ignoring case and extra whitespace.
+-- The most common spelling becomes the author name.
+INSERT INTO authors (name)
+SELECT DISTINCT ON (lower(spelling)) spelling
+FROM (SELECT regexp_replace(trim(author), '\s+', ' ', 'g') AS spelling, count(*) AS books
+      FROM books
+      GROUP BY 1) spellings
+WHERE spelling <> ''
+ORDER BY lower(spelling), books DESC, spelling;
+
+INSERT INTO book_authors (book_id, author_id)
+SELECT books.id, authors.id
+FROM books
+         JOIN authors ON lower(authors.name) = lower(regexp_replace(trim(books.author), '\s+', ' ', 'g'));
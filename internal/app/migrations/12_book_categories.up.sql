@@ -0,0 +1,17 @@
+CREATE TABLE book_categories
+(
+    book_id     integer NOT NULL,
+    category_id integer NOT NULL,
+
+    PRIMARY KEY (book_id, category_id),
+    FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories (id)
+);
+
+CREATE INDEX book_categories_category_id_idx ON book_categories (category_id);
+
+-- books.category_id stays the main category, it is always one of the book categories
+INSERT INTO book_categories (book_id, category_id)
+SELECT id, category_id
+FROM books
+WHERE category_id IS NOT NULL;
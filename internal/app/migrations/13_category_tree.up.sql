@@ -0,0 +1,6 @@
+ALTER TABLE categories
+    ADD COLUMN parent_id integer REFERENCES categories (id),
+    -- longer cycles are prevented by CategoryRepo.UpdateCategory
+    ADD CONSTRAINT categories_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
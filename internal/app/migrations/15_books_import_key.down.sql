@@ -0,0 +1 @@
+DROP INDEX books_title_author_year_idx;
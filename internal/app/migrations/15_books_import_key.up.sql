@@ -0,0 +1,2 @@
+-- books without ISBN are matched by title, author and year on import
+CREATE INDEX books_title_author_year_idx ON books (title, author, year);
@@ -0,0 +1,5 @@
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE categories DROP COLUMN deleted_at;
+ALTER TABLE books DROP COLUMN deleted_at;
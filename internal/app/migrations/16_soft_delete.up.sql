@@ -0,0 +1,6 @@
+ALTER TABLE books ADD COLUMN deleted_at timestamp with time zone;
+ALTER TABLE categories ADD COLUMN deleted_at timestamp with time zone;
+
+-- Only the deleted rows are indexed, for the purge and the admin listings.
+CREATE INDEX books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX categories_deleted_at_idx ON categories (deleted_at) WHERE deleted_at IS NOT NULL;
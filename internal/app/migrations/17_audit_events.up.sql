@@ -0,0 +1,18 @@
+CREATE TABLE audit_events
+(
+    id         bigserial                              NOT NULL PRIMARY KEY,
+    actor_id   integer,
+    entity     text                                   NOT NULL,
+    entity_id  integer                                NOT NULL,
+    action     text                                   NOT NULL,
+    before     jsonb,
+    after      jsonb,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+
+    -- the trail outlives the users and the purged books and categories
+    FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX audit_events_entity_idx ON audit_events (entity, entity_id, created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id, created_at);
@@ -0,0 +1,3 @@
+-- A request holds its key until locked_until, after which a retry can take over the key of a request
+-- that never finished.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until timestamp with time zone;
@@ -0,0 +1,3 @@
+-- The request holding a key is identified by a random owner, so that a request whose key was taken over
+-- can't store or delete the key of the retry.
+ALTER TABLE idempotency_keys ADD COLUMN owner text;
@@ -0,0 +1,3 @@
+DROP INDEX books_isbn_key;
+
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn);
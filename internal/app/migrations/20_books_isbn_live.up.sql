@@ -0,0 +1,4 @@
+-- Deleted books keep their ISBN for a restore, but don't hold it against new books.
+DROP INDEX books_isbn_key;
+
+CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE deleted_at IS NULL;
@@ -0,0 +1,29 @@
+CREATE TABLE orders
+(
+    id         serial                                 NOT NULL PRIMARY KEY,
+    user_id    integer                                NOT NULL,
+    status     text                                   NOT NULL,
+    total      integer                                NOT NULL CHECK (total >= 0),
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone,
+
+    FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX orders_user_id_created_at_idx ON orders (user_id, created_at DESC);
+
+CREATE TABLE order_items
+(
+    id       serial  NOT NULL PRIMARY KEY,
+    order_id integer NOT NULL,
+    book_id  integer,
+    title    text    NOT NULL,
+    author   text    NOT NULL,
+    price    integer NOT NULL CHECK (price > 0),
+    quantity integer NOT NULL DEFAULT 1 CHECK (quantity > 0),
+
+    FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE SET NULL
+);
+
+CREATE INDEX order_items_order_id_idx ON order_items (order_id);
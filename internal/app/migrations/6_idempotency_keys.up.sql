@@ -0,0 +1,15 @@
+CREATE TABLE idempotency_keys
+(
+    key             text                                   NOT NULL,
+    user_id         integer                                NOT NULL,
+    fingerprint     text                                   NOT NULL,
+    response_status integer,
+    response_body   bytea,
+    expires_at      timestamp with time zone               NOT NULL,
+    created_at      timestamp with time zone DEFAULT now() NOT NULL,
+
+    PRIMARY KEY (user_id, key),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
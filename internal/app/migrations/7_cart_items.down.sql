@@ -0,0 +1,11 @@
+ALTER TABLE carts ADD COLUMN book_ids integer[] NOT NULL DEFAULT '{}';
+
+UPDATE carts
+SET book_ids = (SELECT coalesce(array_agg(cart_items.book_id ORDER BY cart_items.book_id), '{}')
+                FROM cart_items,
+                     generate_series(1, cart_items.quantity)
+                WHERE cart_items.user_id = carts.user_id);
+
+ALTER TABLE carts ALTER COLUMN book_ids DROP DEFAULT;
+
+DROP TABLE cart_items;
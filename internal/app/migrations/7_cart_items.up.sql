@@ -0,0 +1,19 @@
+CREATE TABLE cart_items
+(
+    user_id  integer NOT NULL,
+    book_id  integer NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+
+    PRIMARY KEY (user_id, book_id),
+    FOREIGN KEY (user_id) REFERENCES carts (user_id) ON DELETE CASCADE,
+    FOREIGN KEY (book_id) REFERENCES books (id) ON DELETE CASCADE
+);
+
+INSERT INTO cart_items (user_id, book_id, quantity)
+SELECT carts.user_id, book_id, count(*)
+FROM carts,
+     unnest(carts.book_ids) AS book_id
+WHERE EXISTS (SELECT 1 FROM books WHERE books.id = book_id)
+GROUP BY carts.user_id, book_id;
+
+ALTER TABLE carts DROP COLUMN book_ids;
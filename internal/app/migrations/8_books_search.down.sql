@@ -0,0 +1,3 @@
+DROP INDEX books_search_vector_idx;
+
+ALTER TABLE books DROP COLUMN search_vector;
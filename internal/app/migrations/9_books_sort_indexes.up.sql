@@ -0,0 +1,5 @@
+-- keyset pagination walks these indexes instead of sorting the whole table
+CREATE INDEX books_price_id_idx ON books (price, id);
+CREATE INDEX books_year_id_idx ON books (year, id);
+CREATE INDEX books_title_id_idx ON books (title, id);
+CREATE INDEX books_created_at_id_idx ON books (created_at, id);
@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// Magic card tokens understood by the fake gateway. Any other non-empty token is approved.
+const (
+	TokenDecline        = "tok_decline"
+	TokenTimeout        = "tok_timeout"
+	TokenRequiresAction = "tok_3ds"
+	TokenCaptureDecline = "tok_capture_decline"
+	TokenRefundDecline  = "tok_refund_decline"
+)
+
+// FakeGateway is a deterministic in-process payment gateway for local runs and tests.
+type FakeGateway struct {
+	mu       sync.Mutex
+	seq      int
+	payments map[string]fakePayment
+}
+
+type fakePayment struct {
+	token   string
+	payment domain.Payment
+}
+
+// NewFakeGateway creates a new fake payment gateway.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		payments: map[string]fakePayment{},
+	}
+}
+
+// Authorize reserves the amount on the card.
+func (g *FakeGateway) Authorize(ctx context.Context, cardToken string, amount int) (domain.Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Payment{}, err
+	}
+
+	switch cardToken {
+	case "":
+		return domain.Payment{}, slugerrors.NewBadRequestError("payment token is required", "payment-token-required")
+	case TokenDecline:
+		return domain.Payment{}, slugerrors.NewBadRequestError("payment declined", "payment-declined")
+	case TokenTimeout:
+		return domain.Payment{}, slugerrors.NewSlugError("payment gateway timed out", "payment-timeout")
+	}
+
+	status := domain.PaymentStatusAuthorized
+	if cardToken == TokenRequiresAction {
+		status = domain.PaymentStatusRequiresAction
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.seq++
+	payment, err := domain.NewPayment(domain.NewPaymentData{
+		ID:     fmt.Sprintf("fake_pay_%d", g.seq),
+		Amount: amount,
+		Status: status,
+	})
+	if err != nil {
+		return domain.Payment{}, fmt.Errorf("failed to create domain payment: %w", err)
+	}
+	g.payments[payment.ID()] = fakePayment{token: cardToken, payment: payment}
+
+	return payment, nil
+}
+
+// Capture charges a previously authorized payment. Capturing it again returns it unchanged.
+func (g *FakeGateway) Capture(ctx context.Context, paymentID string) (domain.Payment, error) {
+	return g.transition(ctx, paymentID, domain.PaymentStatusAuthorized, domain.PaymentStatusCaptured,
+		TokenCaptureDecline)
+}
+
+// Refund returns a captured payment to the card. Refunding a refunded payment again returns it
+// unchanged, so that a retried refund never gives the money back twice.
+func (g *FakeGateway) Refund(ctx context.Context, paymentID string) (domain.Payment, error) {
+	return g.transition(ctx, paymentID, domain.PaymentStatusCaptured, domain.PaymentStatusRefunded,
+		TokenRefundDecline)
+}
+
+// Void releases the amount held on the card by an authorized payment that won't be captured.
+// Voiding a voided payment again returns it unchanged.
+func (g *FakeGateway) Void(ctx context.Context, paymentID string) (domain.Payment, error) {
+	return g.transition(ctx, paymentID, domain.PaymentStatusAuthorized, domain.PaymentStatusVoided, "")
+}
+
+func (g *FakeGateway) transition(ctx context.Context, paymentID string, from, to domain.PaymentStatus,
+	declineToken string,
+) (domain.Payment, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Payment{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stored, ok := g.payments[paymentID]
+	if !ok {
+		return domain.Payment{}, slugerrors.NewNotFoundError("payment not found", "payment-not-found")
+	}
+	if declineToken != "" && stored.token == declineToken {
+		return domain.Payment{}, slugerrors.NewBadRequestError("payment declined", "payment-declined")
+	}
+	if stored.payment.Status() == to {
+		return stored.payment, nil
+	}
+	if stored.payment.Status() != from {
+		return domain.Payment{}, slugerrors.NewBadRequestError(
+			fmt.Sprintf("payment is %s, expected %s", stored.payment.Status(), from), "invalid-payment-status")
+	}
+
+	payment, err := domain.NewPayment(domain.NewPaymentData{
+		ID:     paymentID,
+		Amount: stored.payment.Amount(),
+		Status: to,
+	})
+	if err != nil {
+		return domain.Payment{}, fmt.Errorf("failed to create domain payment: %w", err)
+	}
+	stored.payment = payment
+	g.payments[paymentID] = stored
+
+	return payment, nil
+}
@@ -0,0 +1,101 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeGateway_AuthorizeCaptureRefund(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+
+	payment, err := gateway.Authorize(ctx, "tok_visa", 2500)
+	require.NoError(t, err)
+	assert.Equal(t, "fake_pay_1", payment.ID())
+	assert.Equal(t, 2500, payment.Amount())
+	assert.Equal(t, domain.PaymentStatusAuthorized, payment.Status())
+
+	payment, err = gateway.Capture(ctx, payment.ID())
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusCaptured, payment.Status())
+
+	payment, err = gateway.Refund(ctx, payment.ID())
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, payment.Status())
+
+	// A retried refund returns the refunded payment without refunding it twice
+	payment, err = gateway.Refund(ctx, payment.ID())
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRefunded, payment.Status())
+
+	// A refunded payment can't be captured again
+	_, err = gateway.Capture(ctx, payment.ID())
+	require.Error(t, err)
+}
+
+func TestFakeGateway_MagicTokens(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+
+	_, err := gateway.Authorize(ctx, "", 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required")
+
+	_, err = gateway.Authorize(ctx, TokenDecline, 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "declined")
+
+	_, err = gateway.Authorize(ctx, TokenTimeout, 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	payment, err := gateway.Authorize(ctx, TokenRequiresAction, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusRequiresAction, payment.Status())
+
+	_, err = gateway.Capture(ctx, payment.ID())
+	require.Error(t, err)
+
+	payment, err = gateway.Authorize(ctx, TokenCaptureDecline, 1000)
+	require.NoError(t, err)
+	_, err = gateway.Capture(ctx, payment.ID())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "declined")
+
+	// The payment that could not be captured is voided, and can't be captured afterwards
+	payment, err = gateway.Void(ctx, payment.ID())
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusVoided, payment.Status())
+	payment, err = gateway.Void(ctx, payment.ID())
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentStatusVoided, payment.Status())
+	_, err = gateway.Capture(ctx, payment.ID())
+	require.Error(t, err)
+}
+
+func TestFakeGateway_VoidCaptured(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+
+	payment, err := gateway.Authorize(ctx, "tok_visa", 1000)
+	require.NoError(t, err)
+	_, err = gateway.Capture(ctx, payment.ID())
+	require.NoError(t, err)
+
+	// A captured payment is refunded, not voided
+	_, err = gateway.Void(ctx, payment.ID())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected authorized")
+}
+
+func TestFakeGateway_UnknownPayment(t *testing.T) {
+	gateway := NewFakeGateway()
+
+	_, err := gateway.Capture(context.Background(), "unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
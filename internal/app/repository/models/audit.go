@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AuditEvent records a change to a book or a category, with JSON snapshots of the entity.
+type AuditEvent struct {
+	bun.BaseModel `bun:"table:audit_events"`
+	ID            int64 `bun:",pk,autoincrement"`
+	ActorID       int   `bun:",nullzero"`
+	Entity        string
+	EntityID      int
+	Action        string
+	// Before is NULL for created entities.
+	Before    json.RawMessage `bun:"type:jsonb,nullzero"`
+	After     json.RawMessage `bun:"type:jsonb,nullzero"`
+	CreatedAt time.Time       `bun:",nullzero,default:current_timestamp"`
+}
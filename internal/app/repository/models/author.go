@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Author struct {
+	bun.BaseModel `bun:"table:authors"`
+	ID            int `bun:",pk,autoincrement"`
+	Name          string
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
+
+// BookAuthor links a book to one of its authors. Position keeps the order the authors are credited in.
+type BookAuthor struct {
+	bun.BaseModel `bun:"table:book_authors"`
+	BookID        int `bun:",pk"`
+	AuthorID      int `bun:",pk"`
+	Position      int
+}
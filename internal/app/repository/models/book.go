@@ -16,6 +16,19 @@ type Book struct {
 	Price         int
 	Stock         int
 	CategoryID    int
+	ISBN          string    `bun:"isbn,nullzero"`
+	CoverKey      string    `bun:",nullzero"`
 	CreatedAt     time.Time `bun:",nullzero"`
 	UpdatedAt     time.Time `bun:",nullzero"`
+	// DeletedAt is set on the deleted books until they are purged.
+	DeletedAt time.Time `bun:",nullzero"`
+	// AuthorIDs and CategoryIDs are stored in book_authors and book_categories.
+	AuthorIDs   []int `bun:"-"`
+	CategoryIDs []int `bun:"-"`
+	// TitleHighlight, AuthorHighlight and Rank are only selected by full-text searches.
+	TitleHighlight  string  `bun:",scanonly"`
+	AuthorHighlight string  `bun:",scanonly"`
+	Rank            float64 `bun:",scanonly"`
+	// CategoryNames is only selected by exports.
+	CategoryNames []string `bun:",array,scanonly"`
 }
@@ -8,8 +8,16 @@ import (
 
 type Cart struct {
 	bun.BaseModel `bun:"table:carts"`
-	UserID        int       `bun:"user_id"`
-	BookIDs       []int     `bun:"book_ids,array"`
-	CreatedAt     time.Time `bun:"created_at,nullzero,default:current_timestamp"`
-	UpdatedAt     time.Time `bun:"updated_at,nullzero"`
+	UserID        int        `bun:"user_id,pk"`
+	Items         []CartItem `bun:"rel:has-many,join:user_id=user_id"`
+	CreatedAt     time.Time  `bun:"created_at,nullzero,default:current_timestamp"`
+	UpdatedAt     time.Time  `bun:"updated_at,nullzero"`
+}
+
+type CartItem struct {
+	bun.BaseModel `bun:"table:cart_items"`
+	UserID        int   `bun:"user_id,pk"`
+	BookID        int   `bun:"book_id,pk"`
+	Quantity      int   `bun:"quantity"`
+	Book          *Book `bun:"rel:belongs-to,join:book_id=id"`
 }
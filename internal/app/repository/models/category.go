@@ -10,6 +10,23 @@ type Category struct {
 	bun.BaseModel `bun:"table:categories"`
 	ID            int `bun:",pk,autoincrement"`
 	Name          string
+	ParentID      int       `bun:",nullzero"`
 	CreatedAt     time.Time `bun:",nullzero"`
 	UpdatedAt     time.Time `bun:",nullzero"`
+	// DeletedAt is set on the deleted categories until they are purged.
+	DeletedAt time.Time `bun:",nullzero"`
+	// InStockBooks and the fields below are only selected with the category stats,
+	// the prices and the newest book are NULL without books in stock.
+	InStockBooks    int     `bun:",scanonly"`
+	MinPrice        *int    `bun:",scanonly"`
+	MaxPrice        *int    `bun:",scanonly"`
+	NewestBookID    *int    `bun:",scanonly"`
+	NewestBookTitle *string `bun:",scanonly"`
+}
+
+// BookCategory links a book to one of its categories.
+type BookCategory struct {
+	bun.BaseModel `bun:"table:book_categories"`
+	BookID        int `bun:",pk"`
+	CategoryID    int `bun:",pk"`
 }
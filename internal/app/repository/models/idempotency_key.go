@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type IdempotencyKey struct {
+	bun.BaseModel  `bun:"table:idempotency_keys"`
+	Key            string `bun:",pk"`
+	UserID         int    `bun:",pk"`
+	Fingerprint    string
+	ResponseStatus int    `bun:",nullzero"`
+	ResponseBody   []byte `bun:",nullzero"`
+	ExpiresAt      time.Time
+	LockedUntil    time.Time `bun:",nullzero"`
+	Owner          string    `bun:",nullzero"`
+	CreatedAt      time.Time `bun:",nullzero,default:current_timestamp"`
+}
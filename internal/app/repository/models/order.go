@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Order struct {
+	bun.BaseModel `bun:"table:orders"`
+	ID            int `bun:",pk,autoincrement"`
+	UserID        int
+	Status        string
+	Total         int
+	PaymentID     string      `bun:",nullzero"`
+	Items         []OrderItem `bun:"rel:has-many,join:id=order_id"`
+	CreatedAt     time.Time   `bun:",nullzero,default:current_timestamp"`
+	UpdatedAt     time.Time   `bun:",nullzero"`
+}
+
+type OrderItem struct {
+	bun.BaseModel `bun:"table:order_items"`
+	ID            int `bun:",pk,autoincrement"`
+	OrderID       int
+	BookID        int `bun:",nullzero"`
+	Title         string
+	Author        string
+	Price         int
+	Quantity      int
+}
@@ -0,0 +1,177 @@
+package pgrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
+	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type AuditRepo struct {
+	db *pg.DB
+}
+
+func NewAuditRepo(db *pg.DB) *AuditRepo {
+	return &AuditRepo{
+		db: db,
+	}
+}
+
+// GetAuditEvents returns the audit events matching the filter, newest first.
+func (r AuditRepo) GetAuditEvents(ctx context.Context, filter domain.AuditFilter, limit, offset int) (
+	[]domain.AuditEvent, error,
+) {
+	var events []models.AuditEvent
+	query := r.db.NewSelect().Model(&events)
+	if filter.Entity != "" {
+		query.Where("entity = ?", string(filter.Entity))
+	}
+	if filter.EntityID != 0 {
+		query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != 0 {
+		query.Where("actor_id = ?", filter.ActorID)
+	}
+	if !filter.From.IsZero() {
+		query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query.Where("created_at < ?", filter.To)
+	}
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	query.Order("created_at DESC", "id DESC")
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	domainEvents := make([]domain.AuditEvent, len(events))
+	for i, event := range events {
+		domainEvent, err := auditEventToDomain(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain audit event: %w", err)
+		}
+
+		domainEvents[i] = domainEvent
+	}
+
+	return domainEvents, nil
+}
+
+// auditBook is the snapshot of a book stored in the audit events.
+type auditBook struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Year        int        `json:"year"`
+	Author      string     `json:"author"`
+	Price       int        `json:"price"`
+	Stock       int        `json:"stock"`
+	CategoryID  int        `json:"categoryId"`
+	CategoryIDs []int      `json:"categoryIds"`
+	AuthorIDs   []int      `json:"authorIds"`
+	ISBN        string     `json:"isbn,omitempty"`
+	CoverKey    string     `json:"coverKey,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
+}
+
+// auditCategory is the snapshot of a category stored in the audit events.
+type auditCategory struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	ParentID  int        `json:"parentId,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// recordBookAudit writes an audit event for a change to a book in the transaction of the change.
+// The book is nil before it is created.
+func recordBookAudit(ctx context.Context, tx bun.Tx, actorID int, action domain.AuditAction,
+	before, after *models.Book,
+) error {
+	snapshot := func(book *models.Book) any {
+		if book == nil {
+			return nil
+		}
+		return auditBook{
+			ID:          book.ID,
+			Title:       book.Title,
+			Year:        book.Year,
+			Author:      book.Author,
+			Price:       book.Price,
+			Stock:       book.Stock,
+			CategoryID:  book.CategoryID,
+			CategoryIDs: book.CategoryIDs,
+			AuthorIDs:   book.AuthorIDs,
+			ISBN:        book.ISBN,
+			CoverKey:    book.CoverKey,
+			DeletedAt:   auditTime(book.DeletedAt),
+		}
+	}
+	return recordAudit(ctx, tx, actorID, domain.AuditEntityBook, after.ID, action, snapshot(before), snapshot(after))
+}
+
+// recordCategoryAudit writes an audit event for a change to a category in the transaction of the change.
+// The category is nil before it is created.
+func recordCategoryAudit(ctx context.Context, tx bun.Tx, actorID int, action domain.AuditAction,
+	before, after *models.Category,
+) error {
+	snapshot := func(category *models.Category) any {
+		if category == nil {
+			return nil
+		}
+		return auditCategory{
+			ID:        category.ID,
+			Name:      category.Name,
+			ParentID:  category.ParentID,
+			DeletedAt: auditTime(category.DeletedAt),
+		}
+	}
+	return recordAudit(ctx, tx, actorID, domain.AuditEntityCategory, after.ID, action,
+		snapshot(before), snapshot(after))
+}
+
+// recordAudit inserts an audit event with the JSON snapshots, a nil snapshot is stored as NULL.
+func recordAudit(ctx context.Context, tx bun.Tx, actorID int, entity domain.AuditEntity, entityID int,
+	action domain.AuditAction, before, after any,
+) error {
+	event := models.AuditEvent{
+		ActorID:  actorID,
+		Entity:   string(entity),
+		EntityID: entityID,
+		Action:   string(action),
+	}
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return fmt.Errorf("failed to marshal an audit snapshot: %w", err)
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return fmt.Errorf("failed to marshal an audit snapshot: %w", err)
+		}
+	}
+
+	_, err = tx.NewInsert().Model(&event).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to insert an audit event: %w", err)
+	}
+	return nil
+}
+
+// auditTime returns nil for the zero time, so that it is left out of the snapshots.
+func auditTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
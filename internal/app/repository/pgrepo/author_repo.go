@@ -0,0 +1,325 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
+	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+// authorsNameKey is the case-insensitive unique index on the author names.
+const authorsNameKey = "authors_name_key"
+
+type AuthorRepo struct {
+	db *pg.DB
+}
+
+func NewAuthorRepo(db *pg.DB) *AuthorRepo {
+	return &AuthorRepo{
+		db: db,
+	}
+}
+
+func (r AuthorRepo) GetAuthor(ctx context.Context, id int) (domain.Author, error) {
+	if id == 0 {
+		return domain.Author{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var author models.Author
+	err := r.db.NewSelect().Model(&author).Where("id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Author{}, domain.ErrNotFound
+		}
+		return domain.Author{}, fmt.Errorf("failed to get an author: %w", err)
+	}
+
+	domainAuthor, err := authorToDomain(author)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+func (r AuthorRepo) CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error) {
+	dbAuthor := domainToAuthor(author)
+
+	var insertedAuthor models.Author
+	err := r.db.NewInsert().Model(&dbAuthor).Returning("*").Scan(ctx, &insertedAuthor)
+	if err != nil {
+		if pg.IsUniqueViolation(err, authorsNameKey) {
+			return domain.Author{}, fmt.Errorf("%w: %s", domain.ErrAuthorConflict, author.Name())
+		}
+		return domain.Author{}, fmt.Errorf("failed to insert an author: %w", err)
+	}
+
+	domainAuthor, err := authorToDomain(insertedAuthor)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// UpdateAuthor renames an author. The linked books whose author text spells the old name get the
+// new one, and their changes are recorded in the audit trail under the actor.
+func (r AuthorRepo) UpdateAuthor(ctx context.Context, actorID int, author domain.Author) (domain.Author, error) {
+	dbAuthor := domainToAuthor(author)
+	dbAuthor.UpdatedAt = time.Now()
+
+	var updatedAuthor models.Author
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		before, err := lockAuthors(ctx, tx, dbAuthor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.NewUpdate().
+			Model(&dbAuthor).
+			Where("id = ?", dbAuthor.ID).
+			ExcludeColumn("created_at").
+			Returning("*").
+			Scan(ctx, &updatedAuthor)
+		if err != nil {
+			if pg.IsUniqueViolation(err, authorsNameKey) {
+				return fmt.Errorf("%w: %s", domain.ErrAuthorConflict, author.Name())
+			}
+			return fmt.Errorf("failed to update an author: %w", err)
+		}
+
+		var bookIDs []int
+		err = tx.NewSelect().Model((*models.Book)(nil)).Column("id").
+			Where("id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", dbAuthor.ID).
+			Where(authorSpellingExpr+" = lower(?)", before[0].Name).
+			Order("id").
+			For("UPDATE").
+			Scan(ctx, &bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock the author books: %w", err)
+		}
+
+		return changeAuthorBooks(ctx, tx, actorID, bookIDs, func() error {
+			return renameBooksAuthor(ctx, tx, bookIDs, before[0].Name, updatedAuthor.Name)
+		})
+	}, r.db)
+	if err != nil {
+		return domain.Author{}, err
+	}
+
+	domainAuthor, err := authorToDomain(updatedAuthor)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// MergeAuthors merges a duplicate author into another one and deletes it. Its books are linked to
+// the other author instead, those whose author text spells the duplicate's name get the other
+// author's name, and their changes are recorded in the audit trail under the actor.
+func (r AuthorRepo) MergeAuthors(ctx context.Context, actorID, id, intoID int) (domain.Author, error) {
+	if id == 0 || intoID == 0 {
+		return domain.Author{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+	if id == intoID {
+		return domain.Author{}, fmt.Errorf("%w: author %d", domain.ErrAuthorSelfMerge, id)
+	}
+
+	var into models.Author
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		authors, err := lockAuthors(ctx, tx, id, intoID)
+		if err != nil {
+			return err
+		}
+		var merged models.Author
+		for _, author := range authors {
+			if author.ID == id {
+				merged = author
+			} else {
+				into = author
+			}
+		}
+
+		var bookIDs []int
+		err = tx.NewSelect().Model((*models.Book)(nil)).Column("id").
+			Where("id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", id).
+			Order("id").
+			For("UPDATE").
+			Scan(ctx, &bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock the author books: %w", err)
+		}
+
+		err = changeAuthorBooks(ctx, tx, actorID, bookIDs, func() error {
+			_, err := tx.NewRaw(`INSERT INTO book_authors (book_id, author_id, position)
+				SELECT book_id, ?, position FROM book_authors WHERE author_id = ?
+				ON CONFLICT DO NOTHING`, intoID, id).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to relink the author books: %w", err)
+			}
+			_, err = tx.NewDelete().Model((*models.BookAuthor)(nil)).Where("author_id = ?", id).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to unlink the author books: %w", err)
+			}
+			return renameBooksAuthor(ctx, tx, bookIDs, merged.Name, into.Name)
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().Model((*models.Author)(nil)).Where("id = ?", id).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete an author: %w", err)
+		}
+		return nil
+	}, r.db)
+	if err != nil {
+		return domain.Author{}, err
+	}
+
+	domainAuthor, err := authorToDomain(into)
+	if err != nil {
+		return domain.Author{}, fmt.Errorf("failed to create domain author: %w", err)
+	}
+
+	return domainAuthor, nil
+}
+
+// authorSpellingExpr is the book author text normalised like the author names, for comparing them.
+const authorSpellingExpr = `lower(regexp_replace(trim(author), '\s+', ' ', 'g'))`
+
+// lockAuthors locks the authors in ID order and returns them. The first ID is reported as
+// ErrNotFound when missing, the others as ErrAuthorNotFound.
+func lockAuthors(ctx context.Context, tx bun.Tx, ids ...int) ([]models.Author, error) {
+	var authors []models.Author
+	err := tx.NewSelect().Model(&authors).Where("id IN (?)", bun.In(ids)).Order("id").For("UPDATE").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock authors: %w", err)
+	}
+
+	found := make(map[int]bool, len(authors))
+	for _, author := range authors {
+		found[author.ID] = true
+	}
+	for i, id := range ids {
+		if found[id] {
+			continue
+		}
+		if i == 0 {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %d", domain.ErrAuthorNotFound, id)
+	}
+
+	return authors, nil
+}
+
+// renameBooksAuthor sets the author text of the books that spell oldName to newName.
+func renameBooksAuthor(ctx context.Context, tx bun.Tx, bookIDs []int, oldName, newName string) error {
+	if len(bookIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.NewUpdate().
+		Model((*models.Book)(nil)).
+		Set("author = ?", newName).
+		Set("updated_at = ?", time.Now()).
+		Where("id IN (?)", bun.In(bookIDs)).
+		Where(authorSpellingExpr+" = lower(?)", oldName).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to rename the book authors: %w", err)
+	}
+	return nil
+}
+
+// changeAuthorBooks applies change to the locked books and records an update event for every book
+// it changed.
+func changeAuthorBooks(ctx context.Context, tx bun.Tx, actorID int, bookIDs []int, change func() error) error {
+	if len(bookIDs) == 0 {
+		return change()
+	}
+
+	before, err := getBooksWithLinks(ctx, tx, bookIDs)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	after, err := getBooksWithLinks(ctx, tx, bookIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range after {
+		if before[i].Author == after[i].Author && slices.Equal(before[i].AuthorIDs, after[i].AuthorIDs) {
+			continue
+		}
+		if err := recordBookAudit(ctx, tx, actorID, domain.AuditActionUpdate, &before[i], &after[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAuthor deletes an author and unlinks their books, which keep their author text. The changes
+// to the books are recorded in the audit trail under the actor.
+func (r AuthorRepo) DeleteAuthor(ctx context.Context, actorID, id int) error {
+	if id == 0 {
+		return fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	return pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		if _, err := lockAuthors(ctx, tx, id); err != nil {
+			return err
+		}
+
+		var bookIDs []int
+		err := tx.NewSelect().Model((*models.Book)(nil)).Column("id").
+			Where("id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", id).
+			Order("id").
+			For("UPDATE").
+			Scan(ctx, &bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock the author books: %w", err)
+		}
+
+		// the links go with the author
+		return changeAuthorBooks(ctx, tx, actorID, bookIDs, func() error {
+			_, err := tx.NewDelete().Model((*models.Author)(nil)).Where("id = ?", id).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to delete an author: %w", err)
+			}
+			return nil
+		})
+	}, r.db)
+}
+
+func (r AuthorRepo) GetAuthors(ctx context.Context) ([]domain.Author, error) {
+	var authors []models.Author
+	err := r.db.NewSelect().Model(&authors).OrderExpr("lower(name)").Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select authors: %w", err)
+	}
+
+	domainAuthors := make([]domain.Author, 0, len(authors))
+	for _, author := range authors {
+		domainAuthor, err := authorToDomain(author)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain author: %w", err)
+		}
+
+		domainAuthors = append(domainAuthors, domainAuthor)
+	}
+
+	return domainAuthors, nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
@@ -29,13 +31,16 @@ func (r BookRepo) GetBook(ctx context.Context, id int) (domain.Book, error) {
 	}
 
 	var book models.Book
-	err := r.db.NewSelect().Model(&book).Where("id = ?", id).Scan(ctx)
+	err := r.db.NewSelect().Model(&book).Where("id = ?", id).Where("deleted_at IS NULL").Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Book{}, domain.ErrNotFound
 		}
 		return domain.Book{}, fmt.Errorf("failed to get a book: %w", err)
 	}
+	if err := loadBookLinks(ctx, r.db, []*models.Book{&book}); err != nil {
+		return domain.Book{}, err
+	}
 
 	domainBook, err := bookToDomain(book)
 	if err != nil {
@@ -45,13 +50,48 @@ func (r BookRepo) GetBook(ctx context.Context, id int) (domain.Book, error) {
 	return domainBook, nil
 }
 
-func (r BookRepo) CreateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	dbBook := domainToBook(book)
+// GetBookByISBN returns the book with the ISBN-13.
+func (r BookRepo) GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	var book models.Book
+	err := r.db.NewSelect().Model(&book).Where("isbn = ?", isbn).Where("deleted_at IS NULL").Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Book{}, domain.ErrNotFound
+		}
+		return domain.Book{}, fmt.Errorf("failed to get a book by ISBN: %w", err)
+	}
+	if err := loadBookLinks(ctx, r.db, []*models.Book{&book}); err != nil {
+		return domain.Book{}, err
+	}
 
+	domainBook, err := bookToDomain(book)
+	if err != nil {
+		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+	}
+
+	return domainBook, nil
+}
+
+const (
+	// booksISBNKey is the unique index on the ISBN of the books that aren't deleted.
+	booksISBNKey = "books_isbn_key"
+	// booksCategoryIDFkey is the foreign key from the main book category to categories.
+	booksCategoryIDFkey = "books_category_id_fkey"
+)
+
+// CreateBook inserts a book and links it to its authors and categories, see linkBookAuthors.
+// The creation is recorded in the audit trail under the actor.
+func (r BookRepo) CreateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
 	var insertedBook models.Book
-	err := r.db.NewInsert().Model(&dbBook).Returning("*").Scan(ctx, &insertedBook)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) (err error) {
+		insertedBook, err = insertBook(ctx, tx, domainToBook(book))
+		if err != nil {
+			return err
+		}
+		return recordBookAudit(ctx, tx, actorID, domain.AuditActionCreate, nil, &insertedBook)
+	}, r.db)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to insert a book: %w", err)
+		return domain.Book{}, err
 	}
 
 	domainBook, err := bookToDomain(insertedBook)
@@ -62,60 +102,416 @@ func (r BookRepo) CreateBook(ctx context.Context, book domain.Book) (domain.Book
 	return domainBook, nil
 }
 
-func (r BookRepo) UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	dbBook := domainToBook(book)
+// UpdateBook updates a book and links it to its authors and categories again, see linkBookAuthors.
+// The stock and the cover are left alone, see UpdateBookCover. It returns ErrNotFound when there is
+// no book with the ID.
+func (r BookRepo) UpdateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
+	var updatedBook models.Book
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		before, err := lockBook(ctx, tx, book.ID(), false)
+		if err != nil {
+			return err
+		}
+		updatedBook, err = updateBook(ctx, tx, domainToBook(book))
+		if err != nil {
+			return err
+		}
+		return recordBookAudit(ctx, tx, actorID, domain.AuditActionUpdate, &before, &updatedBook)
+	}, r.db)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	domainBook, err := bookToDomain(updatedBook)
+	if err != nil {
+		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+	}
+
+	return domainBook, nil
+}
+
+// lockBook locks a book for the rest of the transaction and returns it with its links, as the
+// before snapshot of the audit trail. Only a deleted book is found when deleted is set, and only
+// a live one otherwise.
+func lockBook(ctx context.Context, tx bun.Tx, id int, deleted bool) (models.Book, error) {
+	var book models.Book
+	query := tx.NewSelect().Model(&book).Where("id = ?", id).For("UPDATE")
+	if deleted {
+		query.Where("deleted_at IS NOT NULL")
+	} else {
+		query.Where("deleted_at IS NULL")
+	}
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Book{}, domain.ErrNotFound
+		}
+		return models.Book{}, fmt.Errorf("failed to lock a book: %w", err)
+	}
+	if err := loadBookLinks(ctx, tx, []*models.Book{&book}); err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+// insertBook inserts a book and links it to its authors and categories.
+func insertBook(ctx context.Context, tx bun.Tx, dbBook models.Book) (models.Book, error) {
+	var insertedBook models.Book
+	err := tx.NewInsert().Model(&dbBook).Returning("*").Scan(ctx, &insertedBook)
+	if err != nil {
+		if pg.IsUniqueViolation(err, booksISBNKey) {
+			return models.Book{}, fmt.Errorf("%w: %s", domain.ErrISBNConflict, dbBook.ISBN)
+		}
+		if pg.IsForeignKeyViolation(err, booksCategoryIDFkey) {
+			return models.Book{}, fmt.Errorf("%w: %d", domain.ErrCategoryNotFound, dbBook.CategoryID)
+		}
+		return models.Book{}, fmt.Errorf("failed to insert a book: %w", err)
+	}
+
+	insertedBook.AuthorIDs, err = linkBookAuthors(ctx, tx, insertedBook, dbBook.AuthorIDs)
+	if err != nil {
+		return models.Book{}, err
+	}
+	insertedBook.CategoryIDs, err = linkBookCategories(ctx, tx, insertedBook.ID, dbBook.CategoryIDs)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return insertedBook, nil
+}
+
+// updateBook updates a book and links it to its authors and categories again, keeping its stock, cover
+// and deletion time.
+func updateBook(ctx context.Context, tx bun.Tx, dbBook models.Book) (models.Book, error) {
 	dbBook.UpdatedAt = time.Now()
 
 	var updatedBook models.Book
-	err := r.db.NewUpdate().
+	err := tx.NewUpdate().
 		Model(&dbBook).
 		Where("id = ?", dbBook.ID).
-		ExcludeColumn("created_at", "stock").
+		ExcludeColumn("created_at", "stock", "cover_key", "deleted_at").
 		Returning("*").
 		Scan(ctx, &updatedBook)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to update a book: %w", err)
+		if pg.IsUniqueViolation(err, booksISBNKey) {
+			return models.Book{}, fmt.Errorf("%w: %s", domain.ErrISBNConflict, dbBook.ISBN)
+		}
+		if pg.IsForeignKeyViolation(err, booksCategoryIDFkey) {
+			return models.Book{}, fmt.Errorf("%w: %d", domain.ErrCategoryNotFound, dbBook.CategoryID)
+		}
+		return models.Book{}, fmt.Errorf("failed to update a book: %w", err)
+	}
+
+	updatedBook.AuthorIDs, err = linkBookAuthors(ctx, tx, updatedBook, dbBook.AuthorIDs)
+	if err != nil {
+		return models.Book{}, err
+	}
+	updatedBook.CategoryIDs, err = linkBookCategories(ctx, tx, updatedBook.ID, dbBook.CategoryIDs)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	return updatedBook, nil
+}
+
+// errImportDryRun rolls back the transaction of a dry run import.
+var errImportDryRun = errors.New("import dry run")
+
+// ImportBooks upserts a batch of books in one transaction, see importBook. A dry run reports
+// what would be done and rolls the batch back. Every imported book is recorded in the audit trail
+// under the actor.
+func (r BookRepo) ImportBooks(ctx context.Context, actorID int, books []domain.Book, dryRun bool) (
+	[]domain.BookImportResult, error,
+) {
+	results := make([]domain.BookImportResult, 0, len(books))
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		for _, book := range books {
+			result, err := importBook(ctx, tx, actorID, domainToBook(book))
+			if err != nil {
+				return err
+			}
+			if dryRun && result.Action == domain.BookImportCreated {
+				result.BookID = 0
+			}
+			results = append(results, result)
+		}
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	}, r.db)
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// importBook creates a book or updates the one with the same ISBN or, for a book without ISBN,
+// with the same title, author and year. An updated book keeps its stock, and a row asking for
+// another stock gets a warning. The book is written under a savepoint, so that a book
+// with a taken ISBN or an unknown category or author is rejected without failing the batch.
+// The audit event of a written book is recorded under the same savepoint.
+func importBook(ctx context.Context, tx bun.Tx, actorID int, dbBook models.Book) (domain.BookImportResult, error) {
+	var existing models.Book
+	query := tx.NewSelect().Model(&existing).Where("deleted_at IS NULL").For("UPDATE")
+	if dbBook.ISBN != "" {
+		query.Where("isbn = ?", dbBook.ISBN)
+	} else {
+		query.Where("title = ? AND author = ? AND year = ?", dbBook.Title, dbBook.Author, dbBook.Year).
+			Order("id").
+			Limit(1)
+	}
+	err := query.Scan(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.BookImportResult{}, fmt.Errorf("failed to look up an imported book: %w", err)
+	}
+	found := err == nil
+	if found {
+		if err := loadBookLinks(ctx, tx, []*models.Book{&existing}); err != nil {
+			return domain.BookImportResult{}, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT import_book"); err != nil {
+		return domain.BookImportResult{}, fmt.Errorf("failed to create a savepoint: %w", err)
+	}
+
+	action := domain.BookImportCreated
+	var savedBook models.Book
+	if found {
+		action = domain.BookImportUpdated
+		dbBook.ID = existing.ID
+		// a book matched by title, author and year keeps its ISBN
+		if dbBook.ISBN == "" {
+			dbBook.ISBN = existing.ISBN
+		}
+		savedBook, err = updateBook(ctx, tx, dbBook)
+	} else {
+		savedBook, err = insertBook(ctx, tx, dbBook)
+	}
+	if err != nil {
+		if !errors.Is(err, domain.ErrISBNConflict) && !errors.Is(err, domain.ErrCategoryNotFound) &&
+			!errors.Is(err, domain.ErrAuthorNotFound) {
+			return domain.BookImportResult{}, err
+		}
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_book"); rollbackErr != nil {
+			return domain.BookImportResult{}, fmt.Errorf("failed to roll back to a savepoint: %w", rollbackErr)
+		}
+		return domain.BookImportResult{Action: domain.BookImportRejected, Err: err}, nil
+	}
+	if found {
+		err = recordBookAudit(ctx, tx, actorID, domain.AuditActionUpdate, &existing, &savedBook)
+	} else {
+		err = recordBookAudit(ctx, tx, actorID, domain.AuditActionCreate, nil, &savedBook)
+	}
+	if err != nil {
+		return domain.BookImportResult{}, err
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_book"); err != nil {
+		return domain.BookImportResult{}, fmt.Errorf("failed to release a savepoint: %w", err)
+	}
+
+	result := domain.BookImportResult{Action: action, BookID: savedBook.ID}
+	if found && dbBook.Stock != savedBook.Stock {
+		result.Warning = fmt.Sprintf("stock %d is ignored, the book keeps its stock of %d", dbBook.Stock, savedBook.Stock)
+	}
+
+	return result, nil
+}
+
+// UpdateBookCover sets the cover key of a book and returns the updated book with the previous
+// key, so that the caller can delete the replaced cover. The change is recorded in the audit trail
+// under the actor.
+func (r BookRepo) UpdateBookCover(ctx context.Context, actorID, id int, coverKey string) (
+	domain.Book, string, error,
+) {
+	var (
+		updatedBook models.Book
+		previousKey string
+	)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		current, err := lockBook(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		previousKey = current.CoverKey
+
+		err = tx.NewUpdate().
+			Model((*models.Book)(nil)).
+			Set("cover_key = ?", coverKey).
+			Set("updated_at = ?", time.Now()).
+			Where("id = ?", id).
+			Returning("*").
+			Scan(ctx, &updatedBook)
+		if err != nil {
+			return fmt.Errorf("failed to update a book cover: %w", err)
+		}
+		updatedBook.AuthorIDs, updatedBook.CategoryIDs = current.AuthorIDs, current.CategoryIDs
+		return recordBookAudit(ctx, tx, actorID, domain.AuditActionUpdateCover, &current, &updatedBook)
+	}, r.db)
+	if err != nil {
+		return domain.Book{}, "", err
 	}
 
 	domainBook, err := bookToDomain(updatedBook)
 	if err != nil {
-		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
+		return domain.Book{}, "", fmt.Errorf("failed to create domain book: %w", err)
 	}
 
-	return domainBook, nil
+	return domainBook, previousKey, nil
 }
 
-func (r BookRepo) DeleteBook(ctx context.Context, id int) error {
+// DeleteBook hides a book until it is restored by RestoreBook or purged by PurgeBooks.
+// Carts and orders keep referencing it in the meantime. It returns ErrNotFound when there is no book
+// with the ID, and records the deletion in the audit trail under the actor.
+func (r BookRepo) DeleteBook(ctx context.Context, actorID, id int) error {
 	if id == 0 {
 		return fmt.Errorf("%w: id", domain.ErrRequired)
 	}
 
-	_, err := r.db.NewDelete().Model((*models.Book)(nil)).Where("id = ?", id).Exec(ctx)
+	return pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		before, err := lockBook(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var deletedBook models.Book
+		err = tx.NewUpdate().
+			Model((*models.Book)(nil)).
+			Set("deleted_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", id).
+			Returning("*").
+			Scan(ctx, &deletedBook)
+		if err != nil {
+			return fmt.Errorf("failed to delete a book: %w", err)
+		}
+		deletedBook.AuthorIDs, deletedBook.CategoryIDs = before.AuthorIDs, before.CategoryIDs
+		return recordBookAudit(ctx, tx, actorID, domain.AuditActionDelete, &before, &deletedBook)
+	}, r.db)
+}
+
+// RestoreBook undoes the deletion of a book. It returns ErrNotFound when there is no deleted book
+// with the ID, ErrCategoryNotFound when one of the book categories was deleted since, and
+// ErrISBNConflict when another book took its ISBN meanwhile.
+// The restoration is recorded in the audit trail under the actor.
+func (r BookRepo) RestoreBook(ctx context.Context, actorID, id int) (domain.Book, error) {
+	if id == 0 {
+		return domain.Book{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var restoredBook models.Book
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		before, err := lockBook(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		err = tx.NewUpdate().
+			Model((*models.Book)(nil)).
+			Set("deleted_at = NULL").
+			Set("updated_at = ?", time.Now()).
+			Where("id = ?", id).
+			Returning("*").
+			Scan(ctx, &restoredBook)
+		if err != nil {
+			if pg.IsUniqueViolation(err, booksISBNKey) {
+				return fmt.Errorf("%w: %s", domain.ErrISBNConflict, before.ISBN)
+			}
+			return fmt.Errorf("failed to restore a book: %w", err)
+		}
+		restoredBook.AuthorIDs, restoredBook.CategoryIDs = before.AuthorIDs, before.CategoryIDs
+		if err := lockLiveCategories(ctx, tx, restoredBook.CategoryIDs); err != nil {
+			return err
+		}
+		return recordBookAudit(ctx, tx, actorID, domain.AuditActionRestore, &before, &restoredBook)
+	}, r.db)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	domainBook, err := bookToDomain(restoredBook)
 	if err != nil {
-		return fmt.Errorf("failed to delete a book: %w", err)
+		return domain.Book{}, fmt.Errorf("failed to create domain book: %w", err)
 	}
 
-	return nil
+	return domainBook, nil
+}
+
+// PurgeBooks removes the books deleted before the time for good and returns them, so that
+// the caller can delete their covers. Their order items are kept without a book ID.
+func (r BookRepo) PurgeBooks(ctx context.Context, deletedBefore time.Time) ([]domain.Book, error) {
+	var books []models.Book
+	err := r.db.NewDelete().
+		Model((*models.Book)(nil)).
+		Where("deleted_at < ?", deletedBefore).
+		Returning("*").
+		Scan(ctx, &books)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge books: %w", err)
+	}
+
+	domainBooks := make([]domain.Book, 0, len(books))
+	for _, book := range books {
+		domainBook, err := bookToDomain(book)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain book: %w", err)
+		}
+		domainBooks = append(domainBooks, domainBook)
+	}
+
+	return domainBooks, nil
 }
 
-func (r BookRepo) GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error) {
+// likeEscaper escapes the LIKE wildcards so that user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// searchHeadlineOptions wraps the words matching a search query in <mark> tags.
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+// searchRankExpr is the relevance of a book to a search query.
+const searchRankExpr = "ts_rank(?TableAlias.search_vector, websearch_to_tsquery('english', ?))"
+
+// GetBooks returns the books matching the filter. A search query is matched against
+// the search_vector column, and the results are ordered by relevance unless a sort is requested.
+// The books are always ordered by ID last, so that a cursor can point to any of them.
+func (r BookRepo) GetBooks(ctx context.Context, filter domain.BookFilter, limit, offset int) ([]domain.Book, error) {
 	var books []models.Book
 	query := r.db.NewSelect().Model(&books)
-	query.Where("stock > 0")
-	if len(categoryIDs) > 0 {
-		query.Where("category_id IN (?)", bun.In(categoryIDs))
+	applyBookFilter(query, filter)
+	if filter.Query != "" {
+		query.ColumnExpr("?TableColumns").
+			ColumnExpr("ts_headline('english', ?TableAlias.title, websearch_to_tsquery('english', ?), ?) "+
+				"AS title_highlight", filter.Query, searchHeadlineOptions).
+			ColumnExpr("ts_headline('english', ?TableAlias.author, websearch_to_tsquery('english', ?), ?) "+
+				"AS author_highlight", filter.Query, searchHeadlineOptions).
+			ColumnExpr(searchRankExpr+" AS rank", filter.Query)
+	}
+	if filter.After != nil {
+		if err := applyBookCursor(query, filter); err != nil {
+			return nil, err
+		}
 	}
+	applyBookOrder(query, filter)
 	if limit > 0 {
 		query.Limit(limit)
 	}
 	if offset > 0 {
 		query.Offset(offset)
 	}
-	query.Order("id")
 	err := query.Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get books: %w", err)
 	}
+	bookPtrs := make([]*models.Book, len(books))
+	for i := range books {
+		bookPtrs[i] = &books[i]
+	}
+	if err := loadBookLinks(ctx, r.db, bookPtrs); err != nil {
+		return nil, err
+	}
 
 	domainBooks := make([]domain.Book, len(books))
 	for i, book := range books {
@@ -129,3 +525,342 @@ func (r BookRepo) GetBooks(ctx context.Context, categoryIDs []int, limit, offset
 
 	return domainBooks, nil
 }
+
+// exportFetchSize is the number of books fetched from the export cursor at a time.
+const exportFetchSize = 500
+
+// ExportBooks passes the books matching the filter, in the GetBooks order, to fn a batch at a time.
+// The books are read through a server-side cursor in a read-only repeatable read transaction,
+// so that the export is a consistent snapshot without the whole catalogue being held in memory.
+func (r BookRepo) ExportBooks(ctx context.Context, filter domain.BookFilter,
+	fn func(books []domain.ExportedBook) error,
+) error {
+	return pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		_, err := tx.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY")
+		if err != nil {
+			return fmt.Errorf("failed to set the export isolation level: %w", err)
+		}
+
+		query := tx.NewSelect().
+			Model((*models.Book)(nil)).
+			ColumnExpr("?TableColumns").
+			ColumnExpr("ARRAY(SELECT c.name FROM book_categories AS bc " +
+				"JOIN categories AS c ON c.id = bc.category_id " +
+				"WHERE bc.book_id = ?TableAlias.id ORDER BY c.name) AS category_names")
+		applyBookFilter(query, filter)
+		applyBookOrder(query, filter)
+		if _, err := tx.ExecContext(ctx, "DECLARE book_export NO SCROLL CURSOR FOR ?", query); err != nil {
+			return fmt.Errorf("failed to declare the export cursor: %w", err)
+		}
+
+		for {
+			var books []models.Book
+			if err := tx.NewRaw("FETCH ? FROM book_export", exportFetchSize).Scan(ctx, &books); err != nil {
+				return fmt.Errorf("failed to fetch exported books: %w", err)
+			}
+			if len(books) == 0 {
+				return nil
+			}
+
+			bookPtrs := make([]*models.Book, len(books))
+			for i := range books {
+				bookPtrs[i] = &books[i]
+			}
+			if err := loadBookLinks(ctx, tx, bookPtrs); err != nil {
+				return err
+			}
+			exported := make([]domain.ExportedBook, len(books))
+			for i, book := range books {
+				domainBook, err := bookToDomain(book)
+				if err != nil {
+					return fmt.Errorf("failed to create domain book: %w", err)
+				}
+				exported[i] = domain.ExportedBook{Book: domainBook, CategoryNames: book.CategoryNames}
+			}
+			if err := fn(exported); err != nil {
+				return err
+			}
+			if len(books) < exportFetchSize {
+				return nil
+			}
+		}
+	}, r.db)
+}
+
+// CountBooks returns the number of books matching the filter, ignoring its cursor.
+func (r BookRepo) CountBooks(ctx context.Context, filter domain.BookFilter) (int, error) {
+	query := r.db.NewSelect().Model((*models.Book)(nil))
+	applyBookFilter(query, filter)
+	count, err := query.Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count books: %w", err)
+	}
+
+	return count, nil
+}
+
+// applyBookOrder orders the books by relevance or by the requested sort, and by ID last.
+func applyBookOrder(query *bun.SelectQuery, filter domain.BookFilter) {
+	switch {
+	case filter.Ranked():
+		query.OrderExpr(searchRankExpr+" DESC", filter.Query)
+	case filter.Sort.Field != "":
+		direction := "ASC"
+		if filter.Sort.Desc {
+			direction = "DESC"
+		}
+		query.OrderExpr("?TableAlias.? "+direction, bun.Ident(filter.Sort.Field))
+	}
+	query.OrderExpr("?TableAlias.id")
+}
+
+func applyBookFilter(query *bun.SelectQuery, filter domain.BookFilter) {
+	if filter.Deleted {
+		query.Where("?TableAlias.deleted_at IS NOT NULL")
+	} else {
+		query.Where("?TableAlias.deleted_at IS NULL")
+	}
+	if filter.InStock != nil {
+		if *filter.InStock {
+			query.Where("?TableAlias.stock > 0")
+		} else {
+			query.Where("?TableAlias.stock = 0")
+		}
+	}
+	switch {
+	case len(filter.CategoryIDs) > 0 && filter.IncludeDescendants:
+		// UNION rather than UNION ALL stops at categories already in the subtree.
+		query.Where(`?TableAlias.id IN (SELECT book_id FROM book_categories WHERE category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE id IN (?)
+				UNION
+				SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+			)
+			SELECT id FROM subtree))`, bun.In(filter.CategoryIDs))
+	case len(filter.CategoryIDs) > 0:
+		// A semi-join, so that books in several of the categories are only returned once.
+		query.Where("?TableAlias.id IN (SELECT book_id FROM book_categories WHERE category_id IN (?))",
+			bun.In(filter.CategoryIDs))
+	}
+	if filter.Author != "" {
+		query.Where("?TableAlias.author ILIKE ?", "%"+likeEscaper.Replace(filter.Author)+"%")
+	}
+	if filter.AuthorID != 0 {
+		query.Where("?TableAlias.id IN (SELECT book_id FROM book_authors WHERE author_id = ?)", filter.AuthorID)
+	}
+	if filter.MinPrice > 0 {
+		query.Where("?TableAlias.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		query.Where("?TableAlias.price <= ?", filter.MaxPrice)
+	}
+	if filter.MinYear > 0 {
+		query.Where("?TableAlias.year >= ?", filter.MinYear)
+	}
+	if filter.MaxYear > 0 {
+		query.Where("?TableAlias.year <= ?", filter.MaxYear)
+	}
+	if filter.Query != "" {
+		query.Where("?TableAlias.search_vector @@ websearch_to_tsquery('english', ?)", filter.Query)
+	}
+}
+
+// applyBookCursor keeps the books that come after the cursor in the order of the filter.
+func applyBookCursor(query *bun.SelectQuery, filter domain.BookFilter) error {
+	cursor := filter.After
+	if filter.Ranked() {
+		rank, err := strconv.ParseFloat(cursor.Value, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+		}
+		// Ranked books come in descending order of relevance.
+		query.Where("("+searchRankExpr+" < ? OR ("+searchRankExpr+" = ? AND ?TableAlias.id > ?))",
+			filter.Query, rank, filter.Query, rank, cursor.ID)
+		return nil
+	}
+
+	var (
+		value any
+		err   error
+	)
+	switch filter.Sort.Field {
+	case domain.BookSortByPrice, domain.BookSortByYear:
+		value, err = strconv.Atoi(cursor.Value)
+	case domain.BookSortByTitle:
+		value = cursor.Value
+	case domain.BookSortByCreatedAt:
+		value, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	default:
+		query.Where("?TableAlias.id > ?", cursor.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+
+	comparison := ">"
+	if filter.Sort.Desc {
+		comparison = "<"
+	}
+	column := bun.Ident(filter.Sort.Field)
+	query.Where("(?TableAlias.? "+comparison+" ? OR (?TableAlias.? = ? AND ?TableAlias.id > ?))",
+		column, value, column, value, cursor.ID)
+	return nil
+}
+
+// bookAuthorsAuthorIDFkey is the foreign key from book_authors to authors.
+const bookAuthorsAuthorIDFkey = "book_authors_author_id_fkey"
+
+// linkBookAuthors replaces the authors of a book and returns their IDs. Without author IDs,
+// the book is linked to the author named like its author text, who is created if needed.
+func linkBookAuthors(ctx context.Context, tx bun.Tx, book models.Book, authorIDs []int) ([]int, error) {
+	if len(authorIDs) == 0 {
+		author := models.Author{Name: domain.NormalizeAuthorName(book.Author)}
+		err := tx.NewInsert().
+			Model(&author).
+			On("CONFLICT (lower(name)) DO UPDATE").
+			Set("name = author.name").
+			Returning("id").
+			Scan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert the book author: %w", err)
+		}
+		authorIDs = []int{author.ID}
+	}
+
+	_, err := tx.NewDelete().Model((*models.BookAuthor)(nil)).Where("book_id = ?", book.ID).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlink the book authors: %w", err)
+	}
+	links := make([]models.BookAuthor, 0, len(authorIDs))
+	for i, authorID := range authorIDs {
+		links = append(links, models.BookAuthor{BookID: book.ID, AuthorID: authorID, Position: i})
+	}
+	_, err = tx.NewInsert().Model(&links).Exec(ctx)
+	if err != nil {
+		if pg.IsForeignKeyViolation(err, bookAuthorsAuthorIDFkey) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrAuthorNotFound, authorIDs)
+		}
+		return nil, fmt.Errorf("failed to link the book authors: %w", err)
+	}
+
+	return authorIDs, nil
+}
+
+// bookCategoriesCategoryIDFkey is the foreign key from book_categories to categories.
+const bookCategoriesCategoryIDFkey = "book_categories_category_id_fkey"
+
+// linkBookCategories replaces the categories of a book and returns their IDs.
+func linkBookCategories(ctx context.Context, tx bun.Tx, bookID int, categoryIDs []int) ([]int, error) {
+	_, err := tx.NewDelete().Model((*models.BookCategory)(nil)).Where("book_id = ?", bookID).Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlink the book categories: %w", err)
+	}
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := lockLiveCategories(ctx, tx, categoryIDs); err != nil {
+		return nil, err
+	}
+	links := make([]models.BookCategory, 0, len(categoryIDs))
+	for _, categoryID := range categoryIDs {
+		links = append(links, models.BookCategory{BookID: bookID, CategoryID: categoryID})
+	}
+	_, err = tx.NewInsert().Model(&links).Exec(ctx)
+	if err != nil {
+		if pg.IsForeignKeyViolation(err, bookCategoriesCategoryIDFkey) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrCategoryNotFound, categoryIDs)
+		}
+		return nil, fmt.Errorf("failed to link the book categories: %w", err)
+	}
+
+	return categoryIDs, nil
+}
+
+// loadBookLinks sets the author and category IDs of the books.
+func loadBookLinks(ctx context.Context, db bun.IDB, books []*models.Book) error {
+	if err := loadBookAuthors(ctx, db, books); err != nil {
+		return err
+	}
+	return loadBookCategories(ctx, db, books)
+}
+
+// getBooksWithLinks returns the books with their links ordered by ID, deleted books included.
+func getBooksWithLinks(ctx context.Context, db bun.IDB, ids []int) ([]models.Book, error) {
+	var books []models.Book
+	err := db.NewSelect().Model(&books).Where("id IN (?)", bun.In(ids)).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get books: %w", err)
+	}
+
+	bookPtrs := make([]*models.Book, len(books))
+	for i := range books {
+		bookPtrs[i] = &books[i]
+	}
+	if err := loadBookLinks(ctx, db, bookPtrs); err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// loadBookAuthors sets the author IDs of the books.
+func loadBookAuthors(ctx context.Context, db bun.IDB, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	bookIDs := make([]int, 0, len(books))
+	for _, book := range books {
+		bookIDs = append(bookIDs, book.ID)
+	}
+
+	var links []models.BookAuthor
+	err := db.NewSelect().
+		Model(&links).
+		Where("book_id IN (?)", bun.In(bookIDs)).
+		Order("book_id", "position", "author_id").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get book authors: %w", err)
+	}
+
+	authorIDs := make(map[int][]int, len(books))
+	for _, link := range links {
+		authorIDs[link.BookID] = append(authorIDs[link.BookID], link.AuthorID)
+	}
+	for _, book := range books {
+		book.AuthorIDs = authorIDs[book.ID]
+	}
+	return nil
+}
+
+// loadBookCategories sets the category IDs of the books.
+func loadBookCategories(ctx context.Context, db bun.IDB, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	bookIDs := make([]int, 0, len(books))
+	for _, book := range books {
+		bookIDs = append(bookIDs, book.ID)
+	}
+
+	var links []models.BookCategory
+	err := db.NewSelect().
+		Model(&links).
+		Where("book_id IN (?)", bun.In(bookIDs)).
+		Order("book_id", "category_id").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get book categories: %w", err)
+	}
+
+	categoryIDs := make(map[int][]int, len(books))
+	for _, link := range links {
+		categoryIDs[link.BookID] = append(categoryIDs[link.BookID], link.CategoryID)
+	}
+	for _, book := range books {
+		book.CategoryIDs = categoryIDs[book.ID]
+	}
+	return nil
+}
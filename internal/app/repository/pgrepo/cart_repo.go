@@ -25,8 +25,22 @@ func NewCartRepo(db *pg.DB) *CartRepo {
 }
 
 func (r CartRepo) GetCart(ctx context.Context, userID int) (domain.Cart, error) {
+	return r.getCart(ctx, r.db, userID, false)
+}
+
+// getCart loads the cart with its items and their books, optionally locking the cart row.
+func (r CartRepo) getCart(ctx context.Context, db bun.IDB, userID int, lock bool) (domain.Cart, error) {
 	var cart models.Cart
-	err := r.db.NewSelect().Model(&cart).Where("user_id = ?", userID).Scan(ctx)
+	query := db.NewSelect().Model(&cart).
+		Relation("Items", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.OrderExpr("?TableAlias.book_id")
+		}).
+		Relation("Items.Book").
+		Where("?TableAlias.user_id = ?", userID)
+	if lock {
+		query = query.For("UPDATE")
+	}
+	err := query.Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Cart{}, domain.ErrNotFound
@@ -42,104 +56,207 @@ func (r CartRepo) GetCart(ctx context.Context, userID int) (domain.Cart, error)
 	return domainCart, nil
 }
 
+// UpdateCartAndStocks replaces the cart contents. Stocks are reserved for the copies added
+// to the cart and released for the copies removed from it.
 func (r CartRepo) UpdateCartAndStocks(ctx context.Context, cart domain.Cart) error {
 	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
-		oldCart, err := r.GetCart(ctx, cart.UserID())
-		if err != nil && !errors.Is(err, domain.ErrNotFound) {
-			return fmt.Errorf("failed to get cart: %w", err)
+		oldCart, err := r.lockCart(ctx, tx, cart.UserID())
+		if err != nil {
+			return err
 		}
 
-		if cart.Equal(oldCart) {
+		return saveCart(ctx, tx, oldCart, cart)
+	}, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to update cart and stock: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCartItem sets the number of copies of a single book in the cart, reserving or
+// releasing stock for that book only. A quantity of zero removes the book from the cart.
+func (r CartRepo) UpdateCartItem(ctx context.Context, userID, bookID, quantity int) error {
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		oldCart, err := r.lockCart(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		return saveCart(ctx, tx, oldCart, oldCart.WithQuantity(bookID, quantity))
+	}, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	return nil
+}
+
+// lockCart creates the user's cart if there is none yet and locks it, so that concurrent first
+// writes to a cart are serialised like any other cart update.
+func (r CartRepo) lockCart(ctx context.Context, tx bun.Tx, userID int) (domain.Cart, error) {
+	dbCart := models.Cart{UserID: userID, UpdatedAt: time.Now()}
+	_, err := tx.NewInsert().Model(&dbCart).On("CONFLICT (user_id) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to create cart: %w", err)
+	}
+
+	cart, err := r.getCart(ctx, tx, userID, true)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	return cart, nil
+}
+
+// ClearCart releases the stock reserved by the cart and deletes it.
+func (r CartRepo) ClearCart(ctx context.Context, userID int) error {
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		oldCart, err := r.getCart(ctx, tx, userID, true)
+		if errors.Is(err, domain.ErrNotFound) {
 			return nil
 		}
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
 
-		var cartAdd, cartRemove, cartUnion domain.Cart
-		if oldCart.HasBooks() {
-			cartAdd = cart.Diff(oldCart)
-			cartRemove = oldCart.Diff(cart)
-			cartUnion = oldCart.Join(cart)
-		} else {
-			cartAdd = cart
-			cartUnion = cart
+		if err := releaseStocks(ctx, tx, oldCart); err != nil {
+			return err
 		}
 
-		ok, err := r.CheckStocks(ctx, cartAdd)
+		return deleteCart(ctx, tx, userID)
+	}, r.db)
+	if err != nil {
+		return fmt.Errorf("failed to clear cart: %w", err)
+	}
+
+	return nil
+}
+
+// saveCart turns the locked oldCart into cart. Only the books whose quantities changed are
+// locked, and their stocks are adjusted by the difference.
+func saveCart(ctx context.Context, tx bun.Tx, oldCart, cart domain.Cart) error {
+	if cart.Equal(oldCart) {
+		return nil
+	}
+
+	cartAdd := cart.Diff(oldCart)
+	cartRemove := oldCart.Diff(cart)
+	cartChanged := cartAdd.Join(cartRemove)
+
+	dbStocks := []models.Book{}
+	if cartChanged.HasBooks() {
+		err := tx.NewRaw("SELECT id, stock, deleted_at FROM ? where id in (?) ORDER BY id FOR UPDATE", bun.Ident("books"),
+			bun.In(cartChanged.BookIDs())).Scan(ctx, &dbStocks)
 		if err != nil {
-			return fmt.Errorf("failed to check stocks: %w", err)
-		}
-		if !ok {
-			return slugerrors.NewBadRequestError("some books are out of stock", "out-of-stock")
+			return fmt.Errorf("failed to lock stocks: %w", err)
 		}
+	}
 
-		dbStocks := []models.Book{}
-		if cartUnion.HasBooks() {
-			err := tx.NewRaw("SELECT id, stock FROM ? where id in (?) FOR UPDATE", bun.Ident("books"),
-				bun.In(cartUnion.BookIDs())).Scan(ctx, &dbStocks)
-			if err != nil {
-				return fmt.Errorf("failed to lock stocks: %w", err)
-			}
+	if !hasStocks(dbStocks, cartAdd) {
+		return slugerrors.NewBadRequestError("some books are out of stock", "out-of-stock")
+	}
+
+	for _, item := range cartAdd.Items() {
+		_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock - ?", item.Quantity()).
+			Where("id = ?", item.BookID()).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reduce stock: %w", err)
 		}
+	}
+	if err := releaseStocks(ctx, tx, cartRemove); err != nil {
+		return err
+	}
+
+	dbCart := domainToCart(cart)
+	dbCart.UpdatedAt = time.Now()
 
-		if cartAdd.HasBooks() {
-			_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock - 1").Where("id in (?)",
-				bun.In(cartAdd.BookIDs())).Exec(ctx)
+	_, err := tx.NewInsert().Model(&dbCart).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("updated_at = EXCLUDED.updated_at").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update cart: %w", err)
+	}
+
+	for _, bookID := range cartChanged.BookIDs() {
+		quantity := cart.Quantity(bookID)
+		if quantity == 0 {
+			_, err = tx.NewDelete().Model((*models.CartItem)(nil)).
+				Where("user_id = ?", cart.UserID()).
+				Where("book_id = ?", bookID).
+				Exec(ctx)
 			if err != nil {
-				return fmt.Errorf("failed to reduce stock: %w", err)
+				return fmt.Errorf("failed to delete cart item: %w", err)
 			}
+			continue
 		}
-		if cartRemove.HasBooks() {
-			_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id in (?)",
-				bun.In(cartRemove.BookIDs())).Exec(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to add stock: %w", err)
-			}
+
+		dbItem := models.CartItem{UserID: cart.UserID(), BookID: bookID, Quantity: quantity}
+		_, err = tx.NewInsert().Model(&dbItem).
+			On("CONFLICT (user_id, book_id) DO UPDATE").
+			Set("quantity = EXCLUDED.quantity").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update cart item: %w", err)
 		}
+	}
 
-		dbCart := domainToCart(cart)
-		dbCart.UpdatedAt = time.Now()
+	return nil
+}
 
-		err = tx.NewInsert().Model(&dbCart).
-			On("CONFLICT (user_id) DO UPDATE").
-			Set("book_ids = EXCLUDED.book_ids").
-			Set("updated_at = EXCLUDED.updated_at").
-			Scan(ctx)
+// releaseStocks returns the copies held by the cart to the stock.
+func releaseStocks(ctx context.Context, tx bun.Tx, cart domain.Cart) error {
+	for _, item := range cart.Items() {
+		_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + ?", item.Quantity()).
+			Where("id = ?", item.BookID()).Exec(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to update cart: %w", err)
+			return fmt.Errorf("failed to add stock: %w", err)
 		}
-
-		return nil
-	}, r.db)
-	if err != nil {
-		return fmt.Errorf("failed to update cart and stock: %w", err)
 	}
 
 	return nil
 }
 
+// CheckStocks reports whether there are enough copies in stock for every book in the cart.
 func (r CartRepo) CheckStocks(ctx context.Context, cart domain.Cart) (bool, error) {
+	if !cart.HasBooks() {
+		return true, nil
+	}
+
 	var books []models.Book
 	err := r.db.NewSelect().Model(&books).Where("id in (?)", bun.In(cart.BookIDs())).Scan(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to get stocks: %w", err)
 	}
 
+	return hasStocks(books, cart), nil
+}
+
+// hasStocks reports whether the books have enough copies in stock for the cart. Deleted books are
+// out of stock, they can be removed from a cart but not added to it.
+func hasStocks(books []models.Book, cart domain.Cart) bool {
 	stockMap := make(map[int]int)
 	for _, book := range books {
-		stockMap[book.ID] = book.Stock
+		if book.DeletedAt.IsZero() {
+			stockMap[book.ID] = book.Stock
+		}
 	}
 
-	for _, bookID := range cart.BookIDs() {
-		if stockMap[bookID] == 0 {
-			return false, nil
+	for _, item := range cart.Items() {
+		if stockMap[item.BookID()] < item.Quantity() {
+			return false
 		}
 	}
 
-	return true, nil
+	return true
 }
 
 // DeleteCart deletes a cart.
 func (r CartRepo) DeleteCart(ctx context.Context, userID int) error {
-	_, err := r.db.NewDelete().Model((*models.Cart)(nil)).Where("user_id = ?", userID).Exec(ctx)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		return deleteCart(ctx, tx, userID)
+	}, r.db)
 	if err != nil {
 		return fmt.Errorf("failed to delete cart: %w", err)
 	}
@@ -147,32 +264,107 @@ func (r CartRepo) DeleteCart(ctx context.Context, userID int) error {
 	return nil
 }
 
-func (r CartRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) error {
-	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
-		var expiredCarts []models.Cart
-		err := tx.NewSelect().Model(&expiredCarts).Where("updated_at < ?", time.Now().Add(-ttl)).Scan(ctx)
+// expiredCartsBatchSize is the number of expired carts released in one transaction.
+const expiredCartsBatchSize = 100
+
+// CleanExpiredCarts releases the stock reserved by carts that were not updated within ttl
+// and deletes them. Carts are processed in batches, each in its own transaction, and carts
+// locked by a concurrent update or sweep are skipped. It returns the number of released
+// carts and book copies.
+func (r CartRepo) CleanExpiredCarts(ctx context.Context, ttl time.Duration) (carts, units int, err error) {
+	expiredBefore := time.Now().Add(-ttl)
+	for {
+		batchCarts, batchUnits, err := r.cleanExpiredCartsBatch(ctx, expiredBefore)
+		if err != nil {
+			return carts, units, fmt.Errorf("failed to clean expired carts: %w", err)
+		}
+
+		carts += batchCarts
+		units += batchUnits
+		if batchCarts < expiredCartsBatchSize {
+			return carts, units, nil
+		}
+	}
+}
+
+func (r CartRepo) cleanExpiredCartsBatch(ctx context.Context, expiredBefore time.Time) (carts, units int, err error) {
+	err = pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var userIDs []int
+		err := tx.NewSelect().Model((*models.Cart)(nil)).Column("user_id").
+			Where("updated_at < ?", expiredBefore).
+			OrderExpr("updated_at").
+			Limit(expiredCartsBatchSize).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx, &userIDs)
 		if err != nil {
 			return fmt.Errorf("failed to get expired carts: %w", err)
 		}
+		if len(userIDs) == 0 {
+			return nil
+		}
 
-		for _, cart := range expiredCarts {
-			currentCart := cart
-			for _, bookID := range cart.BookIDs {
-				_, err := tx.NewUpdate().Model((*models.Book)(nil)).Set("stock = stock + 1").Where("id = ?", bookID).Exec(ctx)
-				if err != nil {
-					return fmt.Errorf("failed to return stock: %w", err)
-				}
-			}
-			_, err := tx.NewDelete().Model(&currentCart).Where("user_id = ?", cart.UserID).Exec(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to delete cart: %w", err)
-			}
+		err = tx.NewSelect().Model((*models.CartItem)(nil)).ColumnExpr("coalesce(sum(quantity), 0)").
+			Where("user_id IN (?)", bun.In(userIDs)).
+			Scan(ctx, &units)
+		if err != nil {
+			return fmt.Errorf("failed to count reserved books: %w", err)
 		}
 
+		// lock the books in ID order like a cart update does, so that the sweep can't deadlock with it
+		var bookIDs []int
+		err = tx.NewSelect().Model((*models.Book)(nil)).Column("id").
+			Where("id IN (SELECT book_id FROM cart_items WHERE user_id IN (?))", bun.In(userIDs)).
+			Order("id").
+			For("UPDATE").
+			Scan(ctx, &bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock reserved books: %w", err)
+		}
+
+		released := tx.NewSelect().Model((*models.CartItem)(nil)).
+			ColumnExpr("book_id, sum(quantity) AS quantity").
+			Where("user_id IN (?)", bun.In(userIDs)).
+			Group("book_id")
+		_, err = tx.NewUpdate().Model((*models.Book)(nil)).
+			With("released", released).
+			TableExpr("released").
+			Set("stock = ?TableAlias.stock + released.quantity").
+			Where("?TableAlias.id = released.book_id").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to return stock: %w", err)
+		}
+
+		_, err = tx.NewDelete().Model((*models.CartItem)(nil)).Where("user_id IN (?)", bun.In(userIDs)).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete cart items: %w", err)
+		}
+		_, err = tx.NewDelete().Model((*models.Cart)(nil)).Where("user_id IN (?)", bun.In(userIDs)).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete carts: %w", err)
+		}
+
+		carts = len(userIDs)
+
 		return nil
 	}, r.db)
 	if err != nil {
-		return fmt.Errorf("failed to clean expired carts: %w", err)
+		return 0, 0, err
+	}
+
+	return carts, units, nil
+}
+
+// deleteCart deletes the cart and its items without touching the stocks.
+func deleteCart(ctx context.Context, db bun.IDB, userID int) error {
+	_, err := db.NewDelete().Model((*models.CartItem)(nil)).Where("user_id = ?", userID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart items: %w", err)
+	}
+
+	_, err = db.NewDelete().Model((*models.Cart)(nil)).Where("user_id = ?", userID).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete cart: %w", err)
 	}
 
 	return nil
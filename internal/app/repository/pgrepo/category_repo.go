@@ -10,8 +10,15 @@ import (
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
 	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
+	"github.com/uptrace/bun"
 )
 
+// categoriesParentIDFkey is the foreign key from a category to its parent.
+const categoriesParentIDFkey = "categories_parent_id_fkey"
+
+// categoryTreeLockKey serialises the category moves, so that two of them can't make a cycle together.
+var categoryTreeLockKey = pg.LockKey("bookshop-category-tree")
+
 type CategoryRepo struct {
 	db *pg.DB
 }
@@ -28,7 +35,7 @@ func (r CategoryRepo) GetCategory(ctx context.Context, id int) (domain.Category,
 	}
 
 	var category models.Category
-	err := r.db.NewSelect().Model(&category).Where("id = ?", id).Scan(ctx)
+	err := r.db.NewSelect().Model(&category).Where("id = ?", id).Where("deleted_at IS NULL").Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.Category{}, domain.ErrNotFound
@@ -44,13 +51,31 @@ func (r CategoryRepo) GetCategory(ctx context.Context, id int) (domain.Category,
 	return domainCategory, nil
 }
 
-func (r CategoryRepo) CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
+// CreateCategory inserts a category and records it in the audit trail under the actor.
+func (r CategoryRepo) CreateCategory(ctx context.Context, actorID int, category domain.Category) (
+	domain.Category, error,
+) {
 	dbCategory := domainToCategory(category)
 
 	var insertedCategory models.Category
-	err := r.db.NewInsert().Model(&dbCategory).Returning("*").Scan(ctx, &insertedCategory)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		if dbCategory.ParentID != 0 {
+			if err := lockLiveCategories(ctx, tx, []int{dbCategory.ParentID}); err != nil {
+				return err
+			}
+		}
+
+		err := tx.NewInsert().Model(&dbCategory).Returning("*").Scan(ctx, &insertedCategory)
+		if err != nil {
+			if pg.IsForeignKeyViolation(err, categoriesParentIDFkey) {
+				return fmt.Errorf("%w: parent %d", domain.ErrCategoryNotFound, dbCategory.ParentID)
+			}
+			return fmt.Errorf("failed to insert a category: %w", err)
+		}
+		return recordCategoryAudit(ctx, tx, actorID, domain.AuditActionCreate, nil, &insertedCategory)
+	}, r.db)
 	if err != nil {
-		return domain.Category{}, fmt.Errorf("failed to insert a category: %w", err)
+		return domain.Category{}, err
 	}
 
 	domainCategory, err := categoryToDomain(insertedCategory)
@@ -61,19 +86,65 @@ func (r CategoryRepo) CreateCategory(ctx context.Context, category domain.Catego
 	return domainCategory, nil
 }
 
-func (r CategoryRepo) UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
+// UpdateCategory updates a category. It returns ErrCategoryCycle when the new parent is
+// the category itself or one of its subcategories, and ErrNotFound when there is no category with the ID.
+// The change is recorded in the audit trail under the actor.
+func (r CategoryRepo) UpdateCategory(ctx context.Context, actorID int, category domain.Category) (
+	domain.Category, error,
+) {
 	dbCategory := domainToCategory(category)
 	dbCategory.UpdatedAt = time.Now()
 
 	var updatedCategory models.Category
-	err := r.db.NewUpdate().
-		Model(&dbCategory).
-		Where("id = ?", dbCategory.ID).
-		ExcludeColumn("created_at").
-		Returning("*").
-		Scan(ctx, &updatedCategory)
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		if dbCategory.ParentID != 0 {
+			_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(?)", categoryTreeLockKey)
+			if err != nil {
+				return fmt.Errorf("failed to lock the category tree: %w", err)
+			}
+
+			var cycle bool
+			err = tx.NewRaw(`WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id FROM categories WHERE id = ?
+					UNION
+					SELECT categories.id, categories.parent_id
+					FROM categories JOIN ancestors ON categories.id = ancestors.parent_id
+				)
+				SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?)`, dbCategory.ParentID, dbCategory.ID).
+				Scan(ctx, &cycle)
+			if err != nil {
+				return fmt.Errorf("failed to check the category ancestors: %w", err)
+			}
+			if cycle {
+				return fmt.Errorf("%w: category %d is an ancestor of %d",
+					domain.ErrCategoryCycle, dbCategory.ID, dbCategory.ParentID)
+			}
+			if err := lockLiveCategories(ctx, tx, []int{dbCategory.ParentID}); err != nil {
+				return err
+			}
+		}
+
+		before, err := lockCategory(ctx, tx, dbCategory.ID, false)
+		if err != nil {
+			return err
+		}
+
+		err = tx.NewUpdate().
+			Model(&dbCategory).
+			Where("id = ?", dbCategory.ID).
+			ExcludeColumn("created_at", "deleted_at").
+			Returning("*").
+			Scan(ctx, &updatedCategory)
+		if err != nil {
+			if pg.IsForeignKeyViolation(err, categoriesParentIDFkey) {
+				return fmt.Errorf("%w: parent %d", domain.ErrCategoryNotFound, dbCategory.ParentID)
+			}
+			return fmt.Errorf("failed to update a category: %w", err)
+		}
+		return recordCategoryAudit(ctx, tx, actorID, domain.AuditActionUpdate, &before, &updatedCategory)
+	}, r.db)
 	if err != nil {
-		return domain.Category{}, fmt.Errorf("failed to update a category: %w", err)
+		return domain.Category{}, err
 	}
 
 	domainCategory, err := categoryToDomain(updatedCategory)
@@ -84,26 +155,309 @@ func (r CategoryRepo) UpdateCategory(ctx context.Context, category domain.Catego
 	return domainCategory, nil
 }
 
-func (r CategoryRepo) DeleteCategory(ctx context.Context, id int) error {
+// DeleteCategory deletes a category without books, until it is restored by RestoreCategory or purged
+// by PurgeCategories. With a non-zero reassignTo, the books are moved to that category first, deleted
+// books included, otherwise a CategoryNotEmptyError is returned for a category with books.
+// The subcategories are moved up to the parent of the deleted category, and the deletion is recorded
+// in the audit trail under the actor.
+func (r CategoryRepo) DeleteCategory(ctx context.Context, actorID, id, reassignTo int) error {
 	if id == 0 {
 		return fmt.Errorf("%w: id", domain.ErrRequired)
 	}
 
-	_, err := r.db.NewDelete().Model((*models.Category)(nil)).Where("id = ?", id).Exec(ctx)
+	return pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		// Locking the category blocks the books being added to it until it is deleted.
+		before, err := lockCategory(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		if reassignTo != 0 {
+			if err := reassignCategoryBooks(ctx, tx, actorID, id, reassignTo); err != nil {
+				return err
+			}
+		} else {
+			books, err := tx.NewSelect().
+				Model((*models.Book)(nil)).
+				Where("deleted_at IS NULL").
+				WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+					return q.Where("category_id = ?", id).
+						WhereOr("id IN (SELECT book_id FROM book_categories WHERE category_id = ?)", id)
+				}).
+				Count(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to count the category books: %w", err)
+			}
+			if books > 0 {
+				return domain.CategoryNotEmptyError{CategoryID: id, Books: books}
+			}
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*models.Category)(nil)).
+			Set("parent_id = (SELECT parent_id FROM categories WHERE id = ?)", id).
+			Set("updated_at = ?", time.Now()).
+			Where("parent_id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to move the subcategories up: %w", err)
+		}
+
+		now := time.Now()
+		var deletedCategory models.Category
+		err = tx.NewUpdate().
+			Model((*models.Category)(nil)).
+			Set("deleted_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", id).
+			Returning("*").
+			Scan(ctx, &deletedCategory)
+		if err != nil {
+			return fmt.Errorf("failed to delete a category: %w", err)
+		}
+		return recordCategoryAudit(ctx, tx, actorID, domain.AuditActionDelete, &before, &deletedCategory)
+	}, r.db)
+}
+
+// RestoreCategory undoes the deletion of a category. It returns ErrNotFound when there is
+// no deleted category with the ID. The subcategories moved up on deletion stay where they are.
+// The restoration is recorded in the audit trail under the actor.
+func (r CategoryRepo) RestoreCategory(ctx context.Context, actorID, id int) (domain.Category, error) {
+	if id == 0 {
+		return domain.Category{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var restoredCategory models.Category
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		before, err := lockCategory(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		err = tx.NewUpdate().
+			Model((*models.Category)(nil)).
+			Set("deleted_at = NULL").
+			Set("updated_at = ?", time.Now()).
+			Where("id = ?", id).
+			Returning("*").
+			Scan(ctx, &restoredCategory)
+		if err != nil {
+			return fmt.Errorf("failed to restore a category: %w", err)
+		}
+		// The parent was live when the category was deleted, since deleting the parent
+		// would have moved the category up, but it is checked in case the tree changed.
+		if restoredCategory.ParentID != 0 {
+			if err := lockLiveCategories(ctx, tx, []int{restoredCategory.ParentID}); err != nil {
+				return err
+			}
+		}
+		return recordCategoryAudit(ctx, tx, actorID, domain.AuditActionRestore, &before, &restoredCategory)
+	}, r.db)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	domainCategory, err := categoryToDomain(restoredCategory)
+	if err != nil {
+		return domain.Category{}, fmt.Errorf("failed to create domain category: %w", err)
+	}
+
+	return domainCategory, nil
+}
+
+// PurgeCategories removes the categories deleted before the time for good and returns how many
+// were removed. The categories still referenced by a deleted book are kept until it is purged.
+func (r CategoryRepo) PurgeCategories(ctx context.Context, deletedBefore time.Time) (int, error) {
+	result, err := r.db.NewDelete().
+		Model((*models.Category)(nil)).
+		Where("?TableAlias.deleted_at < ?", deletedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM books WHERE books.category_id = ?TableAlias.id)").
+		Where("NOT EXISTS (SELECT 1 FROM book_categories AS bc WHERE bc.category_id = ?TableAlias.id)").
+		Where("NOT EXISTS (SELECT 1 FROM categories AS child WHERE child.parent_id = ?TableAlias.id)").
+		Exec(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete a category: %w", err)
+		return 0, fmt.Errorf("failed to purge categories: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purged categories: %w", err)
+	}
+
+	return int(purged), nil
+}
+
+// lockCategory locks a category for the rest of the transaction and returns it, as the before snapshot
+// of the audit trail. Only a deleted category is found when deleted is set, and only a live one otherwise.
+func lockCategory(ctx context.Context, tx bun.Tx, id int, deleted bool) (models.Category, error) {
+	var category models.Category
+	query := tx.NewSelect().Model(&category).Where("id = ?", id).For("UPDATE")
+	if deleted {
+		query.Where("deleted_at IS NOT NULL")
+	} else {
+		query.Where("deleted_at IS NULL")
+	}
+	if err := query.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Category{}, domain.ErrNotFound
+		}
+		return models.Category{}, fmt.Errorf("failed to lock a category: %w", err)
 	}
 
+	return category, nil
+}
+
+// lockLiveCategories locks the categories against deletion until the end of the transaction and
+// returns ErrCategoryNotFound when some of them are deleted. Missing categories are left to the foreign keys.
+func lockLiveCategories(ctx context.Context, tx bun.Tx, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var categories []models.Category
+	err := tx.NewSelect().
+		Model(&categories).
+		Column("id", "deleted_at").
+		Where("id IN (?)", bun.In(ids)).
+		Order("id").
+		For("SHARE").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to lock categories: %w", err)
+	}
+
+	var deleted []int
+	for _, category := range categories {
+		if !category.DeletedAt.IsZero() {
+			deleted = append(deleted, category.ID)
+		}
+	}
+	if len(deleted) > 0 {
+		return fmt.Errorf("%w: %v deleted", domain.ErrCategoryNotFound, deleted)
+	}
+	return nil
+}
+
+// reassignCategoryBooks moves the books of a category to another one, as their main category and
+// in book_categories, where books already in the other category are only unlinked. Every moved book
+// gets an update event in the audit trail under the actor.
+func reassignCategoryBooks(ctx context.Context, tx bun.Tx, actorID, from, to int) error {
+	err := tx.NewSelect().Model((*models.Category)(nil)).Column("id").
+		Where("id = ?", to).
+		Where("deleted_at IS NULL").
+		For("SHARE").
+		Scan(ctx, new(int))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %d", domain.ErrCategoryNotFound, to)
+		}
+		return fmt.Errorf("failed to get the category to reassign the books to: %w", err)
+	}
+
+	var bookIDs []int
+	err = tx.NewSelect().Model((*models.Book)(nil)).Column("id").
+		Where("category_id = ?", from).
+		WhereOr("id IN (SELECT book_id FROM book_categories WHERE category_id = ?)", from).
+		Order("id").
+		For("UPDATE").
+		Scan(ctx, &bookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to lock the books to reassign: %w", err)
+	}
+	if len(bookIDs) == 0 {
+		return nil
+	}
+	before, err := getBooksWithLinks(ctx, tx, bookIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*models.Book)(nil)).
+		Set("category_id = ?", to).
+		Set("updated_at = ?", time.Now()).
+		Where("category_id = ?", from).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reassign the books: %w", err)
+	}
+
+	_, err = tx.NewRaw(`INSERT INTO book_categories (book_id, category_id)
+		SELECT book_id, ? FROM book_categories WHERE category_id = ?
+		ON CONFLICT DO NOTHING`, to, from).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reassign the book categories: %w", err)
+	}
+	_, err = tx.NewDelete().Model((*models.BookCategory)(nil)).Where("category_id = ?", from).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to unlink the book categories: %w", err)
+	}
+
+	after, err := getBooksWithLinks(ctx, tx, bookIDs)
+	if err != nil {
+		return err
+	}
+	for i := range after {
+		if err := recordBookAudit(ctx, tx, actorID, domain.AuditActionUpdate, &before[i], &after[i]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r CategoryRepo) GetCategories(ctx context.Context) ([]domain.Category, error) {
+// GetCategories returns all the categories but the deleted ones. With stats, it also summarises
+// their books in stock in the same query.
+func (r CategoryRepo) GetCategories(ctx context.Context, withStats bool) ([]domain.Category, error) {
 	var categories []models.Category
-	err := r.db.NewSelect().Model(&categories).Order("id").Scan(ctx)
+	query := r.db.NewSelect().Model(&categories).Where("?TableAlias.deleted_at IS NULL").OrderExpr("?TableAlias.id")
+	if withStats {
+		// The newest book is the first of the in-stock books aggregated by creation time.
+		query.ColumnExpr("?TableColumns").
+			ColumnExpr("count(b.id) AS in_stock_books").
+			ColumnExpr("min(b.price) AS min_price").
+			ColumnExpr("max(b.price) AS max_price").
+			ColumnExpr("(array_agg(b.id ORDER BY b.created_at DESC, b.id DESC) " +
+				"FILTER (WHERE b.id IS NOT NULL))[1] AS newest_book_id").
+			ColumnExpr("(array_agg(b.title ORDER BY b.created_at DESC, b.id DESC) " +
+				"FILTER (WHERE b.id IS NOT NULL))[1] AS newest_book_title").
+			Join("LEFT JOIN book_categories AS bc ON bc.category_id = ?TableAlias.id").
+			Join("LEFT JOIN books AS b ON b.id = bc.book_id AND b.stock > 0 AND b.deleted_at IS NULL").
+			GroupExpr("?TableAlias.id")
+	}
+	err := query.Scan(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select categories: %w", err)
 	}
 
+	toDomain := categoryToDomain
+	if withStats {
+		toDomain = categoryWithStatsToDomain
+	}
+	domainCategories := make([]domain.Category, 0, len(categories))
+	for _, category := range categories {
+		domainCategory, err := toDomain(category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain category: %w", err)
+		}
+
+		domainCategories = append(domainCategories, domainCategory)
+	}
+
+	return domainCategories, nil
+}
+
+// GetDeletedCategories returns the deleted categories that were not purged yet, the last deleted first.
+func (r CategoryRepo) GetDeletedCategories(ctx context.Context) ([]domain.Category, error) {
+	var categories []models.Category
+	err := r.db.NewSelect().
+		Model(&categories).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC", "id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select deleted categories: %w", err)
+	}
+
 	domainCategories := make([]domain.Category, 0, len(categories))
 	for _, category := range categories {
 		domainCategory, err := categoryToDomain(category)
@@ -0,0 +1,180 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
+	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type IdempotencyRepo struct {
+	db *pg.DB
+}
+
+func NewIdempotencyRepo(db *pg.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		db: db,
+	}
+}
+
+// CreateIdempotencyKey stores the key unless a live key with the same name already exists
+// for the user. It returns the stored key and whether it was created by this call.
+func (r IdempotencyRepo) CreateIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) (
+	domain.IdempotencyKey, bool, error,
+) {
+	var stored domain.IdempotencyKey
+	var created bool
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*models.IdempotencyKey)(nil)).
+			Where("user_id = ?", key.UserID()).
+			Where("key = ?", key.Key()).
+			Where("expires_at < ?", time.Now()).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired idempotency key: %w", err)
+		}
+
+		dbKey := domainToIdempotencyKey(key)
+		res, err := tx.NewInsert().Model(&dbKey).On("CONFLICT (user_id, key) DO NOTHING").Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert idempotency key: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to insert idempotency key: %w", err)
+		}
+		if affected > 0 {
+			stored, created = key, true
+			return nil
+		}
+
+		var existing models.IdempotencyKey
+		err = tx.NewSelect().Model(&existing).
+			Where("user_id = ?", key.UserID()).
+			Where("key = ?", key.Key()).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		stored, err = idempotencyKeyToDomain(existing)
+		if err != nil {
+			return fmt.Errorf("failed to create domain idempotency key: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		return domain.IdempotencyKey{}, false, fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	return stored, created, nil
+}
+
+// TakeOverIdempotencyKey gives the key of an abandoned request to a retry of it, unless the request
+// has completed or another retry took the key over first. It reports whether the key was taken over.
+func (r IdempotencyRepo) TakeOverIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) (bool, error) {
+	res, err := r.db.NewUpdate().Model((*models.IdempotencyKey)(nil)).
+		Set("locked_until = ?", key.LockedUntil()).
+		Set("owner = ?", key.Owner()).
+		Where("user_id = ?", key.UserID()).
+		Where("key = ?", key.Key()).
+		Where("fingerprint = ?", key.Fingerprint()).
+		Where("response_status IS NULL").
+		Where("(locked_until IS NULL OR locked_until < ?)", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to take over idempotency key: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to take over idempotency key: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RenewIdempotencyKey extends the lease of the request holding the key. It returns
+// domain.ErrIdempotencyKeyNotHeld when the key was taken over or completed.
+func (r IdempotencyRepo) RenewIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) error {
+	res, err := r.db.NewUpdate().Model((*models.IdempotencyKey)(nil)).
+		Set("locked_until = ?", key.LockedUntil()).
+		Where("user_id = ?", key.UserID()).
+		Where("key = ?", key.Key()).
+		Where("owner = ?", key.Owner()).
+		Where("response_status IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to renew idempotency key: %w", err)
+	}
+
+	return checkIdempotencyKeyHeld(res)
+}
+
+// SaveIdempotentResponse stores the response of the request made with the key. It returns
+// domain.ErrIdempotencyKeyNotHeld when the key was taken over by a retry.
+func (r IdempotencyRepo) SaveIdempotentResponse(ctx context.Context, key domain.IdempotencyKey) error {
+	res, err := r.db.NewUpdate().Model((*models.IdempotencyKey)(nil)).
+		Set("response_status = ?", key.ResponseStatus()).
+		Set("response_body = ?", key.ResponseBody()).
+		Where("user_id = ?", key.UserID()).
+		Where("key = ?", key.Key()).
+		Where("owner = ?", key.Owner()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotent response: %w", err)
+	}
+
+	return checkIdempotencyKeyHeld(res)
+}
+
+// DeleteIdempotencyKey deletes a key so that the request can be retried with it. It returns
+// domain.ErrIdempotencyKeyNotHeld when the key was taken over by a retry.
+func (r IdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) error {
+	res, err := r.db.NewDelete().Model((*models.IdempotencyKey)(nil)).
+		Where("user_id = ?", key.UserID()).
+		Where("key = ?", key.Key()).
+		Where("owner = ?", key.Owner()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return checkIdempotencyKeyHeld(res)
+}
+
+// checkIdempotencyKeyHeld returns domain.ErrIdempotencyKeyNotHeld when a change to a key
+// filtered by its owner found no row.
+func checkIdempotencyKeyHeld(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrIdempotencyKeyNotHeld
+	}
+
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes expired keys and returns how many were deleted.
+func (r IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	res, err := r.db.NewDelete().Model((*models.IdempotencyKey)(nil)).
+		Where("expires_at < ?", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,275 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
+	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
+	"github.com/uptrace/bun"
+)
+
+type OrderRepo struct {
+	db *pg.DB
+}
+
+func NewOrderRepo(db *pg.DB) *OrderRepo {
+	return &OrderRepo{
+		db: db,
+	}
+}
+
+func (r OrderRepo) GetOrder(ctx context.Context, id int) (domain.Order, error) {
+	if id == 0 {
+		return domain.Order{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var order models.Order
+	err := r.db.NewSelect().Model(&order).Relation("Items").Where("?TableAlias.id = ?", id).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Order{}, domain.ErrNotFound
+		}
+		return domain.Order{}, fmt.Errorf("failed to get an order: %w", err)
+	}
+
+	domainOrder, err := orderToDomain(order)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create domain order: %w", err)
+	}
+
+	return domainOrder, nil
+}
+
+// GetOrders returns orders matching the filter, newest first.
+func (r OrderRepo) GetOrders(ctx context.Context, filter domain.OrderFilter, limit, offset int) (
+	[]domain.Order, error,
+) {
+	var orders []models.Order
+	query := r.db.NewSelect().Model(&orders).Relation("Items")
+	if filter.UserID != 0 {
+		query.Where("?TableAlias.user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query.Where("?TableAlias.status = ?", string(filter.Status))
+	}
+	if !filter.From.IsZero() {
+		query.Where("?TableAlias.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query.Where("?TableAlias.created_at < ?", filter.To)
+	}
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	if offset > 0 {
+		query.Offset(offset)
+	}
+	query.Order("created_at DESC", "id DESC")
+	err := query.Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	domainOrders := make([]domain.Order, len(orders))
+	for i, order := range orders {
+		domainOrder, err := orderToDomain(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create domain order: %w", err)
+		}
+
+		domainOrders[i] = domainOrder
+	}
+
+	return domainOrders, nil
+}
+
+// CreateOrderFromCart turns the user's cart into a pending order and hands it to prepare, which
+// returns the order to insert, e.g. capturing with its payment. The books' current prices are copied into
+// the order items, and the cart is deleted in the same transaction. If prepare fails, nothing is
+// written. prepare runs while the cart is locked, so it must not call out.
+// Stocks are not touched because they were already reserved when the books were put in the cart.
+// A cart holding a deleted book can't be checked out.
+func (r OrderRepo) CreateOrderFromCart(ctx context.Context, userID int,
+	prepare func(order domain.Order) (domain.Order, error),
+) (domain.Order, error) {
+	var order domain.Order
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var cart models.Cart
+		err := tx.NewSelect().Model(&cart).Relation("Items").
+			Where("?TableAlias.user_id = ?", userID).For("UPDATE").Scan(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if len(cart.Items) == 0 {
+			return slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+		}
+
+		quantities := make(map[int]int, len(cart.Items))
+		bookIDs := make([]int, 0, len(cart.Items))
+		for _, item := range cart.Items {
+			quantities[item.BookID] = item.Quantity
+			bookIDs = append(bookIDs, item.BookID)
+		}
+
+		var books []models.Book
+		err = tx.NewSelect().Model(&books).Where("id IN (?)", bun.In(bookIDs)).Order("id").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get books: %w", err)
+		}
+
+		items := make([]domain.OrderItem, 0, len(books))
+		for _, book := range books {
+			if !book.DeletedAt.IsZero() {
+				return slugerrors.NewBadRequestError(
+					fmt.Sprintf("book %d is no longer sold, remove it from the cart", book.ID), "book-deleted")
+			}
+			item, err := domain.NewOrderItem(domain.NewOrderItemData{
+				BookID:   book.ID,
+				Title:    book.Title,
+				Author:   book.Author,
+				Price:    book.Price,
+				Quantity: quantities[book.ID],
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create domain order item: %w", err)
+			}
+			items = append(items, item)
+		}
+
+		pendingOrder, err := domain.NewOrder(domain.NewOrderData{
+			UserID: userID,
+			Status: domain.OrderStatusPending,
+			Items:  items,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create domain order: %w", err)
+		}
+
+		newOrder, err := prepare(pendingOrder)
+		if err != nil {
+			return err
+		}
+
+		dbOrder := domainToOrder(newOrder)
+		err = tx.NewInsert().Model(&dbOrder).Returning("*").Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to insert an order: %w", err)
+		}
+
+		for i := range dbOrder.Items {
+			dbOrder.Items[i].OrderID = dbOrder.ID
+		}
+		if len(dbOrder.Items) > 0 {
+			_, err = tx.NewInsert().Model(&dbOrder.Items).Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to insert order items: %w", err)
+			}
+		}
+
+		if err := deleteCart(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		order, err = orderToDomain(dbOrder)
+		if err != nil {
+			return fmt.Errorf("failed to create domain order: %w", err)
+		}
+
+		return nil
+	}, r.db)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to create order from cart: %w", err)
+	}
+
+	return order, nil
+}
+
+// UpdateOrderStatus moves the order to the given status. Cancelling an order that has not been
+// fulfilled puts the ordered books back on stock in the same transaction. A capturing order is
+// left to checkout, see FinishCheckout.
+func (r OrderRepo) UpdateOrderStatus(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error) {
+	return r.updateOrderStatus(ctx, id, func(order domain.Order) (domain.Order, error) {
+		return order.WithStatus(status)
+	})
+}
+
+// FinishCheckout moves a capturing order to paid once checkout has captured its payment, or to
+// cancelled when the capture failed, which puts the ordered books back on stock.
+func (r OrderRepo) FinishCheckout(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error) {
+	return r.updateOrderStatus(ctx, id, func(order domain.Order) (domain.Order, error) {
+		return order.WithCheckoutStatus(status)
+	})
+}
+
+// updateOrderStatus moves the locked order with the given move and updates the stocks for it.
+func (r OrderRepo) updateOrderStatus(ctx context.Context, id int,
+	move func(order domain.Order) (domain.Order, error),
+) (domain.Order, error) {
+	if id == 0 {
+		return domain.Order{}, fmt.Errorf("%w: id", domain.ErrRequired)
+	}
+
+	var order domain.Order
+	err := pg.HandleBunTransaction(ctx, func(tx bun.Tx) error {
+		var dbOrder models.Order
+		err := tx.NewSelect().Model(&dbOrder).Where("id = ?", id).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("failed to get an order: %w", err)
+		}
+		err = tx.NewSelect().Model(&dbOrder.Items).Where("order_id = ?", id).Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get order items: %w", err)
+		}
+
+		currentOrder, err := orderToDomain(dbOrder)
+		if err != nil {
+			return fmt.Errorf("failed to create domain order: %w", err)
+		}
+
+		updatedOrder, err := move(currentOrder)
+		if err != nil {
+			return err
+		}
+
+		if currentOrder.RestoresStock(updatedOrder.Status()) {
+			for _, item := range currentOrder.Items() {
+				if item.BookID() == 0 {
+					continue
+				}
+				_, err := tx.NewUpdate().Model((*models.Book)(nil)).
+					Set("stock = stock + ?", item.Quantity()).
+					Where("id = ?", item.BookID()).
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to return stock: %w", err)
+				}
+			}
+		}
+
+		_, err = tx.NewUpdate().Model((*models.Order)(nil)).
+			Set("status = ?", string(updatedOrder.Status())).
+			Set("updated_at = ?", time.Now()).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		order = updatedOrder
+		return nil
+	}, r.db)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	return order, nil
+}
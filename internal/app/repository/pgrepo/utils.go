@@ -7,39 +7,93 @@ import (
 
 func domainToBook(book domain.Book) models.Book {
 	return models.Book{
-		ID:         book.ID(),
-		Title:      book.Title(),
-		Year:       book.Year(),
-		Author:     book.Author(),
-		Price:      book.Price(),
-		Stock:      book.Stock(),
-		CategoryID: book.CategoryID(),
+		ID:          book.ID(),
+		Title:       book.Title(),
+		Year:        book.Year(),
+		Author:      book.Author(),
+		Price:       book.Price(),
+		Stock:       book.Stock(),
+		CategoryID:  book.CategoryID(),
+		CategoryIDs: book.CategoryIDs(),
+		ISBN:        book.ISBN(),
+		CoverKey:    book.CoverKey(),
+		AuthorIDs:   book.AuthorIDs(),
 	}
 }
 
 func bookToDomain(book models.Book) (domain.Book, error) {
 	return domain.NewBook(domain.NewBookData{
-		ID:         book.ID,
-		Title:      book.Title,
-		Year:       book.Year,
-		Author:     book.Author,
-		Price:      book.Price,
-		Stock:      book.Stock,
-		CategoryID: book.CategoryID,
+		ID:          book.ID,
+		Title:       book.Title,
+		Year:        book.Year,
+		Author:      book.Author,
+		Price:       book.Price,
+		Stock:       book.Stock,
+		CategoryID:  book.CategoryID,
+		CategoryIDs: book.CategoryIDs,
+		AuthorIDs:   book.AuthorIDs,
+		ISBN:        book.ISBN,
+		CoverKey:    book.CoverKey,
+		CreatedAt:   book.CreatedAt,
+		DeletedAt:   book.DeletedAt,
+		Highlight: domain.BookHighlight{
+			Title:  book.TitleHighlight,
+			Author: book.AuthorHighlight,
+		},
+		Rank: book.Rank,
+	})
+}
+
+func domainToAuthor(author domain.Author) models.Author {
+	return models.Author{
+		ID:   author.ID(),
+		Name: author.Name(),
+	}
+}
+
+func authorToDomain(author models.Author) (domain.Author, error) {
+	return domain.NewAuthor(domain.NewAuthorData{
+		ID:   author.ID,
+		Name: author.Name,
 	})
 }
 
 func domainToCategory(category domain.Category) models.Category {
 	return models.Category{
-		ID:   category.ID(),
-		Name: category.Name(),
+		ID:       category.ID(),
+		Name:     category.Name(),
+		ParentID: category.ParentID(),
 	}
 }
 
 func categoryToDomain(category models.Category) (domain.Category, error) {
 	return domain.NewCategory(domain.NewCategoryData{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:        category.ID,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		DeletedAt: category.DeletedAt,
+	})
+}
+
+func categoryWithStatsToDomain(category models.Category) (domain.Category, error) {
+	stats := domain.CategoryStats{
+		InStockBooks: category.InStockBooks,
+	}
+	if category.MinPrice != nil && category.MaxPrice != nil {
+		stats.MinPrice, stats.MaxPrice = *category.MinPrice, *category.MaxPrice
+	}
+	if category.NewestBookID != nil && category.NewestBookTitle != nil {
+		stats.NewestBook = &domain.CategoryBook{
+			ID:    *category.NewestBookID,
+			Title: *category.NewestBookTitle,
+		}
+	}
+
+	return domain.NewCategory(domain.NewCategoryData{
+		ID:       category.ID,
+		Name:     category.Name,
+		ParentID: category.ParentID,
+		Stats:    &stats,
 	})
 }
 
@@ -62,15 +116,135 @@ func userToDomain(user models.User) (domain.User, error) {
 }
 
 func domainToCart(cart domain.Cart) models.Cart {
+	items := make([]models.CartItem, 0, len(cart.Items()))
+	for _, item := range cart.Items() {
+		items = append(items, models.CartItem{
+			UserID:   cart.UserID(),
+			BookID:   item.BookID(),
+			Quantity: item.Quantity(),
+		})
+	}
+
 	return models.Cart{
-		UserID:  cart.UserID(),
-		BookIDs: cart.BookIDs(),
+		UserID: cart.UserID(),
+		Items:  items,
 	}
 }
 
 func cartToDomain(cart models.Cart) (domain.Cart, error) {
+	items := make([]domain.CartItem, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		var book domain.Book
+		if item.Book != nil {
+			var err error
+			book, err = bookToDomain(*item.Book)
+			if err != nil {
+				return domain.Cart{}, err
+			}
+		}
+
+		domainItem, err := domain.NewCartItem(domain.NewCartItemData{
+			BookID:   item.BookID,
+			Quantity: item.Quantity,
+			Book:     book,
+		})
+		if err != nil {
+			return domain.Cart{}, err
+		}
+		items = append(items, domainItem)
+	}
+
 	return domain.NewCart(domain.NewCartData{
-		UserID:  cart.UserID,
-		BookIDs: cart.BookIDs,
+		UserID:    cart.UserID,
+		Items:     items,
+		UpdatedAt: cart.UpdatedAt,
+	})
+}
+
+func domainToOrder(order domain.Order) models.Order {
+	items := make([]models.OrderItem, 0, len(order.Items()))
+	for _, item := range order.Items() {
+		items = append(items, models.OrderItem{
+			OrderID:  order.ID(),
+			BookID:   item.BookID(),
+			Title:    item.Title(),
+			Author:   item.Author(),
+			Price:    item.Price(),
+			Quantity: item.Quantity(),
+		})
+	}
+
+	return models.Order{
+		ID:        order.ID(),
+		UserID:    order.UserID(),
+		Status:    string(order.Status()),
+		Total:     order.Total(),
+		PaymentID: order.PaymentID(),
+		Items:     items,
+	}
+}
+
+func orderToDomain(order models.Order) (domain.Order, error) {
+	items := make([]domain.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		domainItem, err := domain.NewOrderItem(domain.NewOrderItemData{
+			BookID:   item.BookID,
+			Title:    item.Title,
+			Author:   item.Author,
+			Price:    item.Price,
+			Quantity: item.Quantity,
+		})
+		if err != nil {
+			return domain.Order{}, err
+		}
+		items = append(items, domainItem)
+	}
+
+	return domain.NewOrder(domain.NewOrderData{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Status:    domain.OrderStatus(order.Status),
+		Items:     items,
+		PaymentID: order.PaymentID,
+		CreatedAt: order.CreatedAt,
+	})
+}
+
+func domainToIdempotencyKey(key domain.IdempotencyKey) models.IdempotencyKey {
+	return models.IdempotencyKey{
+		Key:            key.Key(),
+		UserID:         key.UserID(),
+		Fingerprint:    key.Fingerprint(),
+		ResponseStatus: key.ResponseStatus(),
+		ResponseBody:   key.ResponseBody(),
+		ExpiresAt:      key.ExpiresAt(),
+		LockedUntil:    key.LockedUntil(),
+		Owner:          key.Owner(),
+	}
+}
+
+func idempotencyKeyToDomain(key models.IdempotencyKey) (domain.IdempotencyKey, error) {
+	return domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{
+		Key:            key.Key,
+		UserID:         key.UserID,
+		Fingerprint:    key.Fingerprint,
+		ResponseStatus: key.ResponseStatus,
+		ResponseBody:   key.ResponseBody,
+		ExpiresAt:      key.ExpiresAt,
+		LockedUntil:    key.LockedUntil,
+		Owner:          key.Owner,
+	})
+}
+
+func auditEventToDomain(event models.AuditEvent) (domain.AuditEvent, error) {
+	return domain.NewAuditEvent(domain.NewAuditEventData{
+		ID:        event.ID,
+		ActorID:   event.ActorID,
+		Entity:    domain.AuditEntity(event.Entity),
+		EntityID:  event.EntityID,
+		Action:    domain.AuditAction(event.Action),
+		Before:    event.Before,
+		After:     event.After,
+		CreatedAt: event.CreatedAt,
 	})
 }
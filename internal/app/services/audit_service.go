@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// AuditService reads the audit trail of the admin changes to books and categories.
+type AuditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) AuditService {
+	return AuditService{
+		repo: repo,
+	}
+}
+
+func (s AuditService) GetAuditEvents(ctx context.Context, filter domain.AuditFilter, limit, offset int) (
+	[]domain.AuditEvent, error,
+) {
+	return s.repo.GetAuditEvents(ctx, filter, limit, offset)
+}
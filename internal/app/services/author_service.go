@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+type AuthorService struct {
+	repo AuthorRepository
+}
+
+func NewAuthorService(repo AuthorRepository) AuthorService {
+	return AuthorService{
+		repo: repo,
+	}
+}
+
+func (s AuthorService) GetAuthor(ctx context.Context, id int) (domain.Author, error) {
+	return s.repo.GetAuthor(ctx, id)
+}
+
+func (s AuthorService) CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error) {
+	return s.repo.CreateAuthor(ctx, author)
+}
+
+func (s AuthorService) UpdateAuthor(ctx context.Context, actorID int, author domain.Author) (domain.Author, error) {
+	return s.repo.UpdateAuthor(ctx, actorID, author)
+}
+
+// MergeAuthors merges the author into the one with intoID and returns it.
+func (s AuthorService) MergeAuthors(ctx context.Context, actorID, id, intoID int) (domain.Author, error) {
+	return s.repo.MergeAuthors(ctx, actorID, id, intoID)
+}
+
+// DeleteAuthor deletes an author on behalf of the actor, their books are kept.
+func (s AuthorService) DeleteAuthor(ctx context.Context, actorID, id int) error {
+	return s.repo.DeleteAuthor(ctx, actorID, id)
+}
+
+func (s AuthorService) GetAuthors(ctx context.Context) ([]domain.Author, error) {
+	return s.repo.GetAuthors(ctx)
+}
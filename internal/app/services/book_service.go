@@ -2,19 +2,32 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 )
 
 // BookService is a book service.
 type BookService struct {
-	repo BookRepository
+	repo         BookRepository
+	covers       BlobStore
+	cursorSecret []byte
 }
 
-// NewBookService creates a new book service.
-func NewBookService(repo BookRepository) BookService {
+// NewBookService creates a new book service, the covers are kept in the blob store and the page
+// cursors are signed with the secret.
+func NewBookService(repo BookRepository, covers BlobStore, cursorSecret []byte) BookService {
 	return BookService{
-		repo: repo,
+		repo:         repo,
+		covers:       covers,
+		cursorSecret: cursorSecret,
 	}
 }
 
@@ -22,18 +35,178 @@ func (s BookService) GetBook(ctx context.Context, id int) (domain.Book, error) {
 	return s.repo.GetBook(ctx, id)
 }
 
-func (s BookService) CreateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	return s.repo.CreateBook(ctx, book)
+func (s BookService) GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	return s.repo.GetBookByISBN(ctx, isbn)
 }
 
-func (s BookService) UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	return s.repo.UpdateBook(ctx, book)
+func (s BookService) CreateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
+	return s.repo.CreateBook(ctx, actorID, book)
 }
 
-func (s BookService) DeleteBook(ctx context.Context, id int) error {
-	return s.repo.DeleteBook(ctx, id)
+func (s BookService) UpdateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
+	return s.repo.UpdateBook(ctx, actorID, book)
 }
 
-func (s BookService) GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error) {
-	return s.repo.GetBooks(ctx, categoryIDs, limit, offset)
+// DeleteBook deletes a book until it is restored or purged. Its cover is kept for a restore.
+func (s BookService) DeleteBook(ctx context.Context, actorID, id int) error {
+	return s.repo.DeleteBook(ctx, actorID, id)
+}
+
+// RestoreBook undoes the deletion of a book, see BookRepo.RestoreBook.
+func (s BookService) RestoreBook(ctx context.Context, actorID, id int) (domain.Book, error) {
+	return s.repo.RestoreBook(ctx, actorID, id)
+}
+
+// PurgeDeletedBooks removes the books deleted before the time for good, with their covers,
+// and returns how many were removed.
+func (s BookService) PurgeDeletedBooks(ctx context.Context, deletedBefore time.Time) (int, error) {
+	books, err := s.repo.PurgeBooks(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	for _, book := range books {
+		if key := book.CoverKey(); key != "" {
+			s.deleteCover(ctx, key)
+		}
+	}
+
+	return len(books), nil
+}
+
+func (s BookService) GetBooks(ctx context.Context, filter domain.BookFilter, limit, offset int) (
+	[]domain.Book, error,
+) {
+	return s.repo.GetBooks(ctx, filter, limit, offset)
+}
+
+func (s BookService) CountBooks(ctx context.Context, filter domain.BookFilter) (int, error) {
+	return s.repo.CountBooks(ctx, filter)
+}
+
+// ImportBooks creates or updates a batch of books, see BookRepo.ImportBooks.
+func (s BookService) ImportBooks(ctx context.Context, actorID int, books []domain.Book, dryRun bool) (
+	[]domain.BookImportResult, error,
+) {
+	return s.repo.ImportBooks(ctx, actorID, books, dryRun)
+}
+
+// ExportBooks passes the books matching the filter to fn a batch at a time, see BookRepo.ExportBooks.
+func (s BookService) ExportBooks(ctx context.Context, filter domain.BookFilter,
+	fn func(books []domain.ExportedBook) error,
+) error {
+	return s.repo.ExportBooks(ctx, filter, fn)
+}
+
+// coverSizes are the stored variants of each cover.
+var coverSizes = []domain.CoverSize{domain.CoverSizeOriginal, domain.CoverSizeMedium, domain.CoverSizeSmall}
+
+// UploadCover stores a cover with its thumbnails and sets it on the book.
+// The replaced cover is deleted once the book points to the new one.
+func (s BookService) UploadCover(ctx context.Context, actorID, bookID int, data []byte) (domain.Book, error) {
+	cover, err := domain.DecodeCover(data)
+	if err != nil {
+		return domain.Book{}, err
+	}
+	current, err := s.repo.GetBook(ctx, bookID)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	key := cover.Key(bookID)
+	for _, size := range coverSizes {
+		variant, contentType := cover.Data(), cover.ContentType()
+		if size != domain.CoverSizeOriginal {
+			variant, err = cover.Thumbnail(domain.CoverThumbnailWidths[size])
+			if err != nil {
+				return domain.Book{}, err
+			}
+			contentType = "image/jpeg"
+		}
+		if err := s.covers.Put(ctx, domain.CoverVariantKey(key, size), variant, contentType); err != nil {
+			return domain.Book{}, fmt.Errorf("failed to store a cover: %w", err)
+		}
+	}
+
+	book, previousKey, err := s.repo.UpdateBookCover(ctx, actorID, bookID, key)
+	if err != nil {
+		if current.CoverKey() != key {
+			s.deleteCover(ctx, key)
+		}
+		return domain.Book{}, err
+	}
+	if previousKey != "" && previousKey != key {
+		s.deleteCover(ctx, previousKey)
+	}
+
+	return book, nil
+}
+
+// GetCover returns a stored cover variant by its key.
+func (s BookService) GetCover(ctx context.Context, key string) (domain.Blob, error) {
+	return s.covers.Get(ctx, key)
+}
+
+// deleteCover deletes a cover with its thumbnails. Failures only leave unused files behind,
+// so they are logged.
+func (s BookService) deleteCover(ctx context.Context, key string) {
+	for _, size := range coverSizes {
+		if err := s.covers.Delete(ctx, domain.CoverVariantKey(key, size)); err != nil {
+			log.Printf("failed to delete cover %s: %v", domain.CoverVariantKey(key, size), err)
+		}
+	}
+}
+
+// bookCursorPayload is the signed part of a cursor token.
+type bookCursorPayload struct {
+	SortField domain.BookSortField `json:"f,omitempty"`
+	SortDesc  bool                 `json:"d,omitempty"`
+	Ranked    bool                 `json:"r,omitempty"`
+	Value     string               `json:"v,omitempty"`
+	ID        int                  `json:"i"`
+}
+
+// EncodeCursor turns a cursor into an opaque token signed with HMAC-SHA256.
+func (s BookService) EncodeCursor(cursor domain.BookCursor) string {
+	payload, _ := json.Marshal(bookCursorPayload{
+		SortField: cursor.Sort.Field,
+		SortDesc:  cursor.Sort.Desc,
+		Ranked:    cursor.Ranked,
+		Value:     cursor.Value,
+		ID:        cursor.ID,
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signCursor(encoded))
+}
+
+// DecodeCursor checks the signature of a token made by EncodeCursor and returns its cursor.
+func (s BookService) DecodeCursor(token string) (domain.BookCursor, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return domain.BookCursor{}, fmt.Errorf("%w: malformed token", domain.ErrInvalidCursor)
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, s.signCursor(encoded)) {
+		return domain.BookCursor{}, fmt.Errorf("%w: bad signature", domain.ErrInvalidCursor)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return domain.BookCursor{}, fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+	var payload bookCursorPayload
+	if err := json.Unmarshal(decoded, &payload); err != nil {
+		return domain.BookCursor{}, fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+
+	return domain.BookCursor{
+		Sort:   domain.BookSort{Field: payload.SortField, Desc: payload.SortDesc},
+		Ranked: payload.Ranked,
+		Value:  payload.Value,
+		ID:     payload.ID,
+	}, nil
+}
+
+func (s BookService) signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.cursorSecret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
 }
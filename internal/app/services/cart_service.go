@@ -2,24 +2,51 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 	"github.com/davecgh/go-spew/spew"
 )
 
 // CartService is a cart service.
 type CartService struct {
-	cartRepo CartRepository
+	cartRepo       CartRepository
+	orderRepo      OrderRepository
+	paymentGateway PaymentGateway
+	reservationTTL time.Duration
 }
 
-// NewCartService creates a new cart service.
-func NewCartService(repo CartRepository) CartService {
+// NewCartService creates a new cart service. Books stay reserved by a cart for reservationTTL
+// after its last update.
+func NewCartService(cartRepo CartRepository, orderRepo OrderRepository, paymentGateway PaymentGateway,
+	reservationTTL time.Duration,
+) CartService {
 	return CartService{
-		cartRepo: repo,
+		cartRepo:       cartRepo,
+		orderRepo:      orderRepo,
+		paymentGateway: paymentGateway,
+		reservationTTL: reservationTTL,
 	}
 }
 
+// GetCart returns the user's cart priced at the current book prices. A user without a cart
+// gets an empty one.
+func (s CartService) GetCart(ctx context.Context, userID int) (domain.Cart, error) {
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.NewCart(domain.NewCartData{UserID: userID})
+	}
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	return cart.WithReservationTTL(s.reservationTTL), nil
+}
+
 // UpdateCart updates a cart.
 func (s CartService) UpdateCartAndStocks(ctx context.Context, cart domain.Cart) (domain.Cart, error) {
 	err := s.cartRepo.UpdateCartAndStocks(ctx, cart)
@@ -33,10 +60,108 @@ func (s CartService) UpdateCartAndStocks(ctx context.Context, cart domain.Cart)
 		return domain.Cart{}, fmt.Errorf("failed to get updated cart: %w", err)
 	}
 
-	return updatedCart, nil
+	return updatedCart.WithReservationTTL(s.reservationTTL), nil
+}
+
+// SetCartItem puts quantity copies of the book in the cart, leaving the other books as they are.
+func (s CartService) SetCartItem(ctx context.Context, userID, bookID, quantity int) (domain.Cart, error) {
+	if quantity <= 0 {
+		return domain.Cart{}, domain.ErrInvalidQuantity
+	}
+
+	err := s.cartRepo.UpdateCartItem(ctx, userID, bookID, quantity)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// RemoveCartItem removes the book from the cart, leaving the other books as they are.
+func (s CartService) RemoveCartItem(ctx context.Context, userID, bookID int) (domain.Cart, error) {
+	err := s.cartRepo.UpdateCartItem(ctx, userID, bookID, 0)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// ClearCart removes all books from the cart.
+func (s CartService) ClearCart(ctx context.Context, userID int) (domain.Cart, error) {
+	err := s.cartRepo.ClearCart(ctx, userID)
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to clear cart: %w", err)
+	}
+
+	return s.GetCart(ctx, userID)
+}
+
+// Checkout charges the card for the books in the cart and turns the cart into a paid order.
+// The payment is authorized before the order is placed and captured after, so that the cart
+// is not locked while the payment gateway is called. The order is capturing in between, so that
+// it can't be cancelled under the capture. An authorization whose order can't be placed is voided,
+// an order whose payment can't be captured is cancelled, which puts its books back on stock, and
+// its authorization voided, and a captured payment whose order can't be marked paid is refunded.
+func (s CartService) Checkout(ctx context.Context, userID int, cardToken string) (domain.Order, error) {
+	cart, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Order{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+	if !cart.HasBooks() {
+		return domain.Order{}, slugerrors.NewBadRequestError("cart is empty", "empty-cart")
+	}
+
+	payment, err := s.paymentGateway.Authorize(ctx, cardToken, cart.Subtotal())
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("failed to authorize payment: %w", err)
+	}
+	if payment.Status() == domain.PaymentStatusRequiresAction {
+		return domain.Order{}, slugerrors.NewBadRequestError("payment requires additional action",
+			"payment-requires-action")
+	}
+
+	order, err := s.orderRepo.CreateOrderFromCart(ctx, userID, func(order domain.Order) (domain.Order, error) {
+		return order.WithPayment(payment)
+	})
+	if err != nil {
+		s.voidPayment(context.WithoutCancel(ctx), payment)
+		return domain.Order{}, fmt.Errorf("failed to checkout: %w", err)
+	}
+
+	// the order is placed, finish it even if the client goes away
+	ctx = context.WithoutCancel(ctx)
+	if _, err := s.paymentGateway.Capture(ctx, payment.ID()); err != nil {
+		s.cancelCheckout(ctx, order)
+		s.voidPayment(ctx, payment)
+		return domain.Order{}, fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	paid, err := s.orderRepo.FinishCheckout(ctx, order.ID(), domain.OrderStatusPaid)
+	if err != nil {
+		if _, refundErr := s.paymentGateway.Refund(ctx, payment.ID()); refundErr != nil {
+			log.Printf("failed to refund payment %s of order %d: %v", payment.ID(), order.ID(), refundErr)
+		} else {
+			s.cancelCheckout(ctx, order)
+		}
+		return domain.Order{}, fmt.Errorf("failed to mark order %d paid: %w", order.ID(), err)
+	}
+
+	return paid, nil
 }
 
-// Checkout does nothing but cleans up the cart as per the spec.
-func (s CartService) Checkout(ctx context.Context, userID int) error {
-	return s.cartRepo.DeleteCart(ctx, userID)
+// cancelCheckout cancels the order whose payment was not taken. A failure is only logged, the order
+// is left capturing.
+func (s CartService) cancelCheckout(ctx context.Context, order domain.Order) {
+	if _, err := s.orderRepo.FinishCheckout(ctx, order.ID(), domain.OrderStatusCancelled); err != nil {
+		log.Printf("failed to cancel order %d: %v", order.ID(), err)
+	}
+}
+
+// voidPayment releases the hold of a payment that won't be captured. A failure is only logged,
+// since the authorization expires on its own.
+func (s CartService) voidPayment(ctx context.Context, payment domain.Payment) {
+	if _, err := s.paymentGateway.Void(ctx, payment.ID()); err != nil {
+		log.Printf("failed to void payment %s: %v", payment.ID(), err)
+	}
 }
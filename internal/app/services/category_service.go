@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 )
@@ -20,18 +21,38 @@ func (s CategoryService) GetCategory(ctx context.Context, id int) (domain.Catego
 	return s.repo.GetCategory(ctx, id)
 }
 
-func (s CategoryService) CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
-	return s.repo.CreateCategory(ctx, category)
+func (s CategoryService) CreateCategory(ctx context.Context, actorID int, category domain.Category) (
+	domain.Category, error,
+) {
+	return s.repo.CreateCategory(ctx, actorID, category)
 }
 
-func (s CategoryService) UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
-	return s.repo.UpdateCategory(ctx, category)
+func (s CategoryService) UpdateCategory(ctx context.Context, actorID int, category domain.Category) (
+	domain.Category, error,
+) {
+	return s.repo.UpdateCategory(ctx, actorID, category)
 }
 
-func (s CategoryService) DeleteCategory(ctx context.Context, id int) error {
-	return s.repo.DeleteCategory(ctx, id)
+func (s CategoryService) DeleteCategory(ctx context.Context, actorID, id, reassignTo int) error {
+	return s.repo.DeleteCategory(ctx, actorID, id, reassignTo)
 }
 
-func (s CategoryService) GetCategories(ctx context.Context) ([]domain.Category, error) {
-	return s.repo.GetCategories(ctx)
+func (s CategoryService) GetCategories(ctx context.Context, withStats bool) ([]domain.Category, error) {
+	return s.repo.GetCategories(ctx, withStats)
+}
+
+// GetDeletedCategories returns the deleted categories that can still be restored.
+func (s CategoryService) GetDeletedCategories(ctx context.Context) ([]domain.Category, error) {
+	return s.repo.GetDeletedCategories(ctx)
+}
+
+// RestoreCategory undoes the deletion of a category, see CategoryRepo.RestoreCategory.
+func (s CategoryService) RestoreCategory(ctx context.Context, actorID, id int) (domain.Category, error) {
+	return s.repo.RestoreCategory(ctx, actorID, id)
+}
+
+// PurgeDeletedCategories removes the categories deleted before the time for good and returns
+// how many were removed.
+func (s CategoryService) PurgeDeletedCategories(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return s.repo.PurgeCategories(ctx, deletedBefore)
 }
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/google/uuid"
+)
+
+// idempotencyKeyLease is how long a request holds its key unless it renews the lease. A retry takes over
+// the key of a request that did not complete within the lease, e.g. because the server died while handling it.
+const idempotencyKeyLease = time.Minute
+
+// idempotentResponseAttempts is how many times storing a response is tried before giving up on it.
+const idempotentResponseAttempts = 3
+
+// IdempotencyService keeps track of idempotency keys and the responses stored for them.
+type IdempotencyService struct {
+	repo IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service. Keys expire after ttl.
+func NewIdempotencyService(repo IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	return IdempotencyService{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Begin claims the key for a request with the given fingerprint. If the key was already used
+// for the same request, the stored key is returned along with replay set to true. The key of
+// a request that was abandoned is claimed again.
+func (s IdempotencyService) Begin(ctx context.Context, userID int, key, fingerprint string) (
+	idempotencyKey domain.IdempotencyKey, replay bool, err error,
+) {
+	newKey, err := domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{
+		Key:         key,
+		UserID:      userID,
+		Fingerprint: fingerprint,
+		ExpiresAt:   time.Now().Add(s.ttl),
+		LockedUntil: time.Now().Add(idempotencyKeyLease),
+		Owner:       uuid.NewString(),
+	})
+	if err != nil {
+		return domain.IdempotencyKey{}, false, fmt.Errorf("failed to create domain idempotency key: %w", err)
+	}
+
+	stored, created, err := s.repo.CreateIdempotencyKey(ctx, newKey)
+	if err != nil {
+		return domain.IdempotencyKey{}, false, err
+	}
+	if created {
+		return stored, false, nil
+	}
+
+	if stored.Fingerprint() != fingerprint {
+		return domain.IdempotencyKey{}, false, slugerrors.NewUnprocessableError(
+			"idempotency key was already used with a different request", "idempotency-key-reused")
+	}
+	if stored.Abandoned(time.Now()) {
+		takenOver, err := s.repo.TakeOverIdempotencyKey(ctx, newKey)
+		if err != nil {
+			return domain.IdempotencyKey{}, false, err
+		}
+		if takenOver {
+			return newKey, false, nil
+		}
+	}
+	if !stored.Completed() {
+		return domain.IdempotencyKey{}, false, slugerrors.NewConflictError(
+			"a request with this idempotency key is still in progress", "idempotency-key-in-progress")
+	}
+
+	return stored, true, nil
+}
+
+// Renew extends the lease of the request holding the key and returns the renewed key.
+func (s IdempotencyService) Renew(ctx context.Context, key domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	renewed := key.WithLockedUntil(time.Now().Add(idempotencyKeyLease))
+	if err := s.repo.RenewIdempotencyKey(ctx, renewed); err != nil {
+		return domain.IdempotencyKey{}, err
+	}
+	return renewed, nil
+}
+
+// Complete stores the response of the request made with the key, retrying a failed attempt.
+func (s IdempotencyService) Complete(ctx context.Context, key domain.IdempotencyKey, status int, body []byte) error {
+	key = key.WithResponse(status, body)
+	var err error
+	for attempt := 1; attempt <= idempotentResponseAttempts; attempt++ {
+		err = s.repo.SaveIdempotentResponse(ctx, key)
+		if err == nil || errors.Is(err, domain.ErrIdempotencyKeyNotHeld) {
+			return err
+		}
+		if attempt < idempotentResponseAttempts {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			}
+		}
+	}
+	return err
+}
+
+// Release forgets the key, so that a failed request can be retried with it.
+func (s IdempotencyService) Release(ctx context.Context, key domain.IdempotencyKey) error {
+	return s.repo.DeleteIdempotencyKey(ctx, key)
+}
+
+// DeleteExpired deletes expired keys and returns how many were deleted.
+func (s IdempotencyService) DeleteExpired(ctx context.Context) (int, error) {
+	return s.repo.DeleteExpiredIdempotencyKeys(ctx)
+}
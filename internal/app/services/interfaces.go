@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 )
@@ -14,23 +15,84 @@ type UserRepository interface {
 
 type BookRepository interface {
 	GetBook(ctx context.Context, id int) (domain.Book, error)
-	GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error)
-	CreateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	DeleteBook(ctx context.Context, id int) error
+	GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetBooks(ctx context.Context, filter domain.BookFilter, limit, offset int) ([]domain.Book, error)
+	CountBooks(ctx context.Context, filter domain.BookFilter) (int, error)
+	CreateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error)
+	UpdateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error)
+	DeleteBook(ctx context.Context, actorID, id int) error
+	RestoreBook(ctx context.Context, actorID, id int) (domain.Book, error)
+	PurgeBooks(ctx context.Context, deletedBefore time.Time) ([]domain.Book, error)
+	UpdateBookCover(ctx context.Context, actorID, id int, coverKey string) (domain.Book, string, error)
+	ImportBooks(ctx context.Context, actorID int, books []domain.Book, dryRun bool) (
+		[]domain.BookImportResult, error)
+	ExportBooks(ctx context.Context, filter domain.BookFilter, fn func(books []domain.ExportedBook) error) error
+}
+
+// BlobStore stores files such as book covers by key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns domain.ErrNotFound for unknown keys.
+	Get(ctx context.Context, key string) (domain.Blob, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type CategoryRepository interface {
 	GetCategory(ctx context.Context, id int) (domain.Category, error)
-	GetCategories(ctx context.Context) ([]domain.Category, error)
-	CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	DeleteCategory(ctx context.Context, id int) error
+	GetCategories(ctx context.Context, withStats bool) ([]domain.Category, error)
+	CreateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error)
+	UpdateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error)
+	DeleteCategory(ctx context.Context, actorID, id, reassignTo int) error
+	GetDeletedCategories(ctx context.Context) ([]domain.Category, error)
+	RestoreCategory(ctx context.Context, actorID, id int) (domain.Category, error)
+	PurgeCategories(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+type AuthorRepository interface {
+	GetAuthor(ctx context.Context, id int) (domain.Author, error)
+	GetAuthors(ctx context.Context) ([]domain.Author, error)
+	CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error)
+	UpdateAuthor(ctx context.Context, actorID int, author domain.Author) (domain.Author, error)
+	MergeAuthors(ctx context.Context, actorID, id, intoID int) (domain.Author, error)
+	DeleteAuthor(ctx context.Context, actorID, id int) error
 }
 
 type CartRepository interface {
 	GetCart(ctx context.Context, userID int) (domain.Cart, error)
 	DeleteCart(ctx context.Context, userID int) error
 	UpdateCartAndStocks(ctx context.Context, cart domain.Cart) error
+	UpdateCartItem(ctx context.Context, userID, bookID, quantity int) error
+	ClearCart(ctx context.Context, userID int) error
 	CheckStocks(ctx context.Context, cart domain.Cart) (bool, error)
 }
+
+type OrderRepository interface {
+	GetOrder(ctx context.Context, id int) (domain.Order, error)
+	GetOrders(ctx context.Context, filter domain.OrderFilter, limit, offset int) ([]domain.Order, error)
+	CreateOrderFromCart(ctx context.Context, userID int,
+		prepare func(order domain.Order) (domain.Order, error)) (domain.Order, error)
+	UpdateOrderStatus(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error)
+	FinishCheckout(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error)
+}
+
+// PaymentGateway is an external payment provider. Capture, Refund and Void must be idempotent: repeating
+// them for a payment that is already captured, refunded or voided returns it without moving any money.
+type PaymentGateway interface {
+	Authorize(ctx context.Context, cardToken string, amount int) (domain.Payment, error)
+	Capture(ctx context.Context, paymentID string) (domain.Payment, error)
+	Refund(ctx context.Context, paymentID string) (domain.Payment, error)
+	Void(ctx context.Context, paymentID string) (domain.Payment, error)
+}
+
+type AuditRepository interface {
+	GetAuditEvents(ctx context.Context, filter domain.AuditFilter, limit, offset int) ([]domain.AuditEvent, error)
+}
+
+type IdempotencyRepository interface {
+	CreateIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) (domain.IdempotencyKey, bool, error)
+	TakeOverIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) (bool, error)
+	RenewIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) error
+	SaveIdempotentResponse(ctx context.Context, key domain.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error)
+}
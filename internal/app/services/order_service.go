@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// OrderService is an order service.
+type OrderService struct {
+	repo           OrderRepository
+	paymentGateway PaymentGateway
+}
+
+// NewOrderService creates a new order service.
+func NewOrderService(repo OrderRepository, paymentGateway PaymentGateway) OrderService {
+	return OrderService{
+		repo:           repo,
+		paymentGateway: paymentGateway,
+	}
+}
+
+func (s OrderService) GetOrder(ctx context.Context, id int) (domain.Order, error) {
+	return s.repo.GetOrder(ctx, id)
+}
+
+// GetUserOrder returns an order only if it belongs to the given user.
+func (s OrderService) GetUserOrder(ctx context.Context, userID, id int) (domain.Order, error) {
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	if order.UserID() != userID {
+		return domain.Order{}, domain.ErrNotFound
+	}
+
+	return order, nil
+}
+
+func (s OrderService) GetOrders(ctx context.Context, filter domain.OrderFilter, limit, offset int) (
+	[]domain.Order, error,
+) {
+	return s.repo.GetOrders(ctx, filter, limit, offset)
+}
+
+// UpdateOrderStatus moves the order to the given status. Cancelling a paid order or
+// refunding a fulfilled one returns the money through the payment gateway first.
+func (s OrderService) UpdateOrderStatus(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error) {
+	if status.Refunding() {
+		return domain.Order{}, slugerrors.NewBadRequestError(
+			fmt.Sprintf("order status %q is set while the payment is refunded", status), "invalid-order-status")
+	}
+
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	return s.updateOrderStatus(ctx, order, status)
+}
+
+// CancelUserOrder cancels an order on behalf of its owner.
+func (s OrderService) CancelUserOrder(ctx context.Context, userID, id int) (domain.Order, error) {
+	order, err := s.GetUserOrder(ctx, userID, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	return s.updateOrderStatus(ctx, order, domain.OrderStatusCancelled)
+}
+
+// updateOrderStatus moves the order to the given status. A paid order that gives the money back is
+// first moved to its refunding status under the order lock, so that a concurrent cancel or refund is
+// rejected, and only then refunded. If the refund or the final update fails, the order is left
+// refunding and a retry refunds it again, which the gateway ignores for a refunded payment.
+func (s OrderService) updateOrderStatus(ctx context.Context, order domain.Order, status domain.OrderStatus) (
+	domain.Order, error,
+) {
+	// reject invalid transitions before any money is moved
+	if _, err := order.WithStatus(status); err != nil {
+		return domain.Order{}, err
+	}
+
+	refunding, refunds := status.RefundingStatus()
+	if !refunds || order.PaymentID() == "" {
+		return s.repo.UpdateOrderStatus(ctx, order.ID(), status)
+	}
+
+	if order.Status() != refunding {
+		var err error
+		order, err = s.repo.UpdateOrderStatus(ctx, order.ID(), refunding)
+		if err != nil {
+			return domain.Order{}, err
+		}
+	}
+
+	if _, err := s.paymentGateway.Refund(ctx, order.PaymentID()); err != nil {
+		return domain.Order{}, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	return s.repo.UpdateOrderStatus(ctx, order.ID(), status)
+}
@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// @Summary GetAuditEvents
+// @Security ApiKeyAuth
+// @Tags audit
+// @Description get the audit trail of the admin changes to books and categories, newest first.
+// @Description Each event holds the entity before and after the change; before is null on create.
+// @ID get-audit-events
+// @Accept  json
+// @Produce  json
+// @Param entity query string false "book or category"
+// @Param entity_id query int false "entity ID, requires entity"
+// @Param actor_id query int false "ID of the admin who made the change"
+// @Param from query string false "created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "created before (RFC 3339 or YYYY-MM-DD, inclusive day)"
+// @Param page query int false "page number"
+// @Success 200 {array} AuditEventResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /admin/audit [get]
+func (h HTTPServer) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var filter domain.AuditFilter
+	if entity := query.Get("entity"); entity != "" {
+		filter.Entity = domain.AuditEntity(entity)
+		if !filter.Entity.Valid() {
+			server.BadRequest("invalid-entity", nil, w, r)
+			return
+		}
+	}
+	if entityID := query.Get("entity_id"); entityID != "" {
+		id, err := strconv.Atoi(entityID)
+		if err != nil {
+			server.BadRequest("invalid-entity-id", err, w, r)
+			return
+		}
+		filter.EntityID = id
+	}
+	if actorID := query.Get("actor_id"); actorID != "" {
+		id, err := strconv.Atoi(actorID)
+		if err != nil {
+			server.BadRequest("invalid-actor-id", err, w, r)
+			return
+		}
+		filter.ActorID = id
+	}
+	from, err := parseTimeParam(query.Get("from"), false)
+	if err != nil {
+		server.BadRequest("invalid-from", err, w, r)
+		return
+	}
+	filter.From = from
+	to, err := parseTimeParam(query.Get("to"), true)
+	if err != nil {
+		server.BadRequest("invalid-to", err, w, r)
+		return
+	}
+	filter.To = to
+
+	if err := filter.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	limit, offset := pageToLimitOffset(r, auditPageSize)
+
+	events, err := h.auditService.GetAuditEvents(r.Context(), filter, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, toResponseAuditEvent(event))
+	}
+
+	server.RespondOK(response, w, r)
+}
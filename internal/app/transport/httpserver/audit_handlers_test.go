@@ -0,0 +1,87 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuditEvents_Filters(t *testing.T) {
+	auditServiceMock := mocks.NewAuditService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, nil, auditServiceMock)
+
+	event, err := domain.NewAuditEvent(domain.NewAuditEventData{
+		ID:        7,
+		ActorID:   1,
+		Entity:    domain.AuditEntityBook,
+		EntityID:  3,
+		Action:    domain.AuditActionCreate,
+		After:     json.RawMessage(`{"id":3,"title":"Book"}`),
+		CreatedAt: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	expectedFilter := domain.AuditFilter{
+		Entity:   domain.AuditEntityBook,
+		EntityID: 3,
+		ActorID:  1,
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	auditServiceMock.On("GetAuditEvents", mock.Anything, expectedFilter, 20, 20).
+		Return([]domain.AuditEvent{event}, nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/admin/audit?entity=book&entity_id=3&actor_id=1&from=2024-01-01&to=2024-01-31&page=2", nil)
+	rr := httptest.NewRecorder()
+
+	httpServer.GetAuditEvents(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response []map[string]any
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&response))
+	require.Len(t, response, 1)
+	assert.Equal(t, "create", response[0]["action"])
+	assert.Nil(t, response[0]["before"])
+	assert.Equal(t, map[string]any{"id": float64(3), "title": "Book"}, response[0]["after"])
+}
+
+func TestGetAuditEvents_ReturnsBadRequest(t *testing.T) {
+	tests := []struct {
+		target string
+		slug   string
+	}{
+		{target: "/admin/audit?entity=order", slug: "invalid-entity"},
+		{target: "/admin/audit?entity=book&entity_id=x", slug: "invalid-entity-id"},
+		{target: "/admin/audit?actor_id=x", slug: "invalid-actor-id"},
+		{target: "/admin/audit?from=yesterday", slug: "invalid-from"},
+		{target: "/admin/audit?to=tomorrow", slug: "invalid-to"},
+		{target: "/admin/audit?entity_id=3", slug: "invalid-request"},
+		{target: "/admin/audit?from=2024-02-01&to=2024-01-01", slug: "invalid-request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			auditServiceMock := mocks.NewAuditService(t)
+			httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, nil, auditServiceMock)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rr := httptest.NewRecorder()
+
+			httpServer.GetAuditEvents(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var errorResponse server.ErrorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
@@ -16,7 +16,7 @@ import (
 func TestSignUp_Success(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	reqBody := AuthRequest{
 		Username: "testuser",
@@ -44,7 +44,7 @@ func TestSignUp_Success(t *testing.T) {
 func TestSignUp_Validate(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, nil, nil, nil, nil, nil)
 
 	t.Run("empty username", func(t *testing.T) {
 		reqBody := AuthRequest{
@@ -102,7 +102,7 @@ func TestSignIn_Success(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	reqBody := AuthRequest{
 		Username: "testuser",
@@ -133,7 +133,7 @@ func TestSignIn_Validate(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	t.Run("empty username", func(t *testing.T) {
 		reqBody := AuthRequest{
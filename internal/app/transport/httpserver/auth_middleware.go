@@ -52,3 +52,14 @@ func (h HTTPServer) CheckAuthorizedUser(next http.HandlerFunc) http.HandlerFunc
 		next(w, r.WithContext(ctx))
 	}
 }
+
+// isAdmin reports whether the request carries a valid admin token. Public endpoints use it
+// to show administrators more than anonymous users get.
+func (h HTTPServer) isAdmin(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get(AuthorizationHeader), BearerPrefix)
+	if token == "" {
+		return false
+	}
+	user, err := h.tokenService.GetUser(token)
+	return err == nil && user.Admin
+}
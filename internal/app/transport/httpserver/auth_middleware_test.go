@@ -16,7 +16,7 @@ import (
 func TestCheckAdmin_ValidAdminToken(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -38,7 +38,7 @@ func TestCheckAdmin_ValidAdminToken(t *testing.T) {
 func TestCheckAdmin_InvalidToken(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -59,7 +59,7 @@ func TestCheckAdmin_InvalidToken(t *testing.T) {
 func TestCheckAdmin_EmptyUsername(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -81,7 +81,7 @@ func TestCheckAdmin_EmptyUsername(t *testing.T) {
 func TestCheckAdmin_NotAdminUser(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -103,7 +103,7 @@ func TestCheckAdmin_NotAdminUser(t *testing.T) {
 func TestCheckAuthorizedUser_ValidToken(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -125,7 +125,7 @@ func TestCheckAuthorizedUser_ValidToken(t *testing.T) {
 func TestCheckAuthorizedUser_InvalidToken(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
@@ -146,7 +146,7 @@ func TestCheckAuthorizedUser_InvalidToken(t *testing.T) {
 func TestCheckAuthorizedUser_EmptyName(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	tokenServiceMock := mocks.NewTokenService(t)
-	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil)
+	httpServer := NewHTTPServer(userServiceMock, tokenServiceMock, nil, nil, nil, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), "", "", nil)
 	require.NoError(t, err)
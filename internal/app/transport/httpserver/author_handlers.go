@@ -0,0 +1,320 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/gorilla/mux"
+)
+
+// @Summary GetAuthor
+// @Tags author
+// @Description get author by ID
+// @ID get-author
+// @Accept  json
+// @Produce  json
+// @Param author_id path int true "author ID"
+// @Success 200 {object} AuthorResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Router /author/{author_id} [get]
+// GetAuthor returns an author by ID
+func (h HTTPServer) GetAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+	author, err := h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseAuthor(author)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary CreateAuthor
+// @Security ApiKeyAuth
+// @Tags author
+// @Description create author
+// @ID create-author
+// @Accept  json
+// @Produce  json
+// @Param input body AuthorRequest true "author info"
+// @Success 200 {object} AuthorResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /author [post]
+// CreateAuthor creates a new author
+func (h HTTPServer) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	var authorRequest AuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&authorRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	if err := authorRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	author, err := domain.NewAuthor(domain.NewAuthorData{
+		Name: authorRequest.Name,
+	})
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	insertedAuthor, err := h.authorService.CreateAuthor(r.Context(), author)
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorConflict) {
+			server.Conflict("author-conflict", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseAuthor(insertedAuthor)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary UpdateAuthor
+// @Security ApiKeyAuth
+// @Tags author
+// @Description rename author by ID, the author text of their books is renamed too
+// @ID update-author
+// @Accept  json
+// @Produce  json
+// @Param author_id path int true "author ID"
+// @Param input body AuthorRequest true "author info"
+// @Success 200 {object} AuthorResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /author/{author_id} [patch]
+func (h HTTPServer) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	var authorRequest AuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&authorRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	if err := authorRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	_, err = h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	author, err := domain.NewAuthor(domain.NewAuthorData{
+		ID:   authorID,
+		Name: authorRequest.Name,
+	})
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	updatedAuthor, err := h.authorService.UpdateAuthor(r.Context(), user.ID, author)
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorConflict) {
+			server.Conflict("author-conflict", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseAuthor(updatedAuthor)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary MergeAuthors
+// @Security ApiKeyAuth
+// @Tags author
+// @Description merge a duplicate author into another one, their books are moved to it and the duplicate is deleted
+// @ID merge-authors
+// @Accept  json
+// @Produce  json
+// @Param author_id path int true "ID of the author to merge"
+// @Param into query int true "ID of the author to merge into"
+// @Success 200 {object} AuthorResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /author/{author_id}/merge [post]
+func (h HTTPServer) MergeAuthors(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	intoID, err := strconv.Atoi(r.URL.Query().Get("into"))
+	if err != nil || intoID <= 0 {
+		server.BadRequest("invalid-into", err, w, r)
+		return
+	}
+
+	into, err := h.authorService.MergeAuthors(r.Context(), user.ID, authorID, intoID)
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorSelfMerge) {
+			server.BadRequest("invalid-into", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrAuthorNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(toResponseAuthor(into), w, r)
+}
+
+// @Summary DeleteAuthor
+// @Security ApiKeyAuth
+// @Tags author
+// @Description delete author by ID, their books are kept
+// @ID delete-author
+// @Accept  json
+// @Produce  json
+// @Param author_id path int true "author ID"
+// @Success 200 {object} map[string]bool
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /author/{author_id} [delete]
+func (h HTTPServer) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	err = h.authorService.DeleteAuthor(r.Context(), user.ID, authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	server.RespondOK(map[string]bool{"deleted": true}, w, r)
+}
+
+// @Summary GetAuthors
+// @Tags author
+// @Description get all authors, by name
+// @ID get-authors
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} AuthorResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /authors [get]
+func (h HTTPServer) GetAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.authorService.GetAuthors(r.Context())
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]AuthorResponse, 0, len(authors))
+	for _, author := range authors {
+		response = append(response, toResponseAuthor(author))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary GetAuthorBooks
+// @Tags author
+// @Description get books of an author, with the filters, sorting and pagination of GET /books
+// @ID get-author-books
+// @Accept  json
+// @Produce  json
+// @Param author_id path int true "author ID"
+// @Param page query int false "page number, can't be combined with cursor"
+// @Param cursor query string false "next page cursor from the X-Next-Cursor header"
+// @Param limit query int false "page size, 10 by default and 100 at most"
+// @Success 200 {array} BookResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Router /authors/{author_id}/books [get]
+func (h HTTPServer) GetAuthorBooks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	authorID, err := strconv.Atoi(vars["author_id"])
+	if err != nil {
+		server.BadRequest("invalid-author-id", err, w, r)
+		return
+	}
+
+	_, err = h.authorService.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	h.listBooks(w, r, authorID)
+}
@@ -0,0 +1,288 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAuthor_Success(t *testing.T) {
+	authorServiceMock := mocks.NewAuthorService(t)
+
+	testCreatedAuthor, err := domain.NewAuthor(domain.NewAuthorData{ID: 1, Name: "George Orwell"})
+	require.NoError(t, err)
+
+	authorServiceMock.On("CreateAuthor", mock.Anything, mock.MatchedBy(func(author domain.Author) bool {
+		return author.Name() == "George Orwell"
+	})).Return(testCreatedAuthor, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/author", bytes.NewBufferString(`{"name": "  George   Orwell "}`))
+	w := httptest.NewRecorder()
+
+	httpServer.CreateAuthor(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var authorResponse AuthorResponse
+	err = json.NewDecoder(res.Body).Decode(&authorResponse)
+	require.NoError(t, err)
+	assert.Equal(t, AuthorResponse{ID: 1, Name: "George Orwell"}, authorResponse)
+}
+
+func TestCreateAuthor_Errors(t *testing.T) {
+	tests := map[string]struct {
+		body   string
+		err    error
+		status int
+		slug   string
+	}{
+		"invalid json": {"invalid json", nil, http.StatusBadRequest, "invalid-json"},
+		"empty name":   {`{"name": "  "}`, nil, http.StatusBadRequest, "invalid-request"},
+		"conflict":     {`{"name": "George Orwell"}`, domain.ErrAuthorConflict, http.StatusConflict, "author-conflict"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			authorServiceMock := mocks.NewAuthorService(t)
+			if tt.err != nil {
+				authorServiceMock.On("CreateAuthor", mock.Anything, mock.Anything).Return(domain.Author{}, tt.err)
+			}
+
+			httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/author", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			httpServer.CreateAuthor(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestGetAuthor_NotFound(t *testing.T) {
+	authorServiceMock := mocks.NewAuthorService(t)
+	authorServiceMock.On("GetAuthor", mock.Anything, 42).Return(domain.Author{}, domain.ErrNotFound).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/author/42", nil)
+	req = mux.SetURLVars(req, map[string]string{"author_id": "42"})
+	w := httptest.NewRecorder()
+
+	httpServer.GetAuthor(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var errorResponse server.ErrorResponse
+	err := json.NewDecoder(res.Body).Decode(&errorResponse)
+	require.NoError(t, err)
+	require.Equal(t, "author-not-found", errorResponse.Slug)
+}
+
+func TestUpdateAuthor_ReturnsBadRequestForInvalidID(t *testing.T) {
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, mocks.NewAuthorService(t), nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/author/invalid", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.UpdateAuthor(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestMergeAuthors_Success(t *testing.T) {
+	authorServiceMock := mocks.NewAuthorService(t)
+
+	into, err := domain.NewAuthor(domain.NewAuthorData{ID: 7, Name: "George Orwell"})
+	require.NoError(t, err)
+	authorServiceMock.On("MergeAuthors", mock.Anything, testAdmin.ID, 8, 7).Return(into, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/author/8/merge?into=7", nil)
+	req = mux.SetURLVars(req, map[string]string{"author_id": "8"})
+	w := httptest.NewRecorder()
+
+	httpServer.MergeAuthors(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var authorResponse AuthorResponse
+	err = json.NewDecoder(res.Body).Decode(&authorResponse)
+	require.NoError(t, err)
+	assert.Equal(t, AuthorResponse{ID: 7, Name: "George Orwell"}, authorResponse)
+}
+
+func TestMergeAuthors_Errors(t *testing.T) {
+	selfMerge := fmt.Errorf("failed to merge authors: %w: author 8", domain.ErrAuthorSelfMerge)
+	tests := map[string]struct {
+		into   string
+		err    error
+		status int
+		slug   string
+	}{
+		"missing into":     {"", nil, http.StatusBadRequest, "invalid-into"},
+		"into itself":      {"8", selfMerge, http.StatusBadRequest, "invalid-into"},
+		"author not found": {"7", domain.ErrNotFound, http.StatusBadRequest, "author-not-found"},
+		"into not found":   {"7", domain.ErrAuthorNotFound, http.StatusBadRequest, "author-not-found"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			authorServiceMock := mocks.NewAuthorService(t)
+			if tt.err != nil {
+				intoID, err := strconv.Atoi(tt.into)
+				require.NoError(t, err)
+				authorServiceMock.On("MergeAuthors", mock.Anything, testAdmin.ID, 8, intoID).
+					Return(domain.Author{}, tt.err)
+			}
+
+			httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/author/8/merge?into="+tt.into, nil)
+			req = mux.SetURLVars(req, map[string]string{"author_id": "8"})
+			w := httptest.NewRecorder()
+
+			httpServer.MergeAuthors(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestDeleteAuthor(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		status int
+	}{
+		"deleted":   {nil, http.StatusOK},
+		"not found": {fmt.Errorf("failed to delete author: %w", domain.ErrNotFound), http.StatusBadRequest},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			authorServiceMock := mocks.NewAuthorService(t)
+			authorServiceMock.On("DeleteAuthor", mock.Anything, testAdmin.ID, 7).Return(tt.err)
+
+			httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, nil, authorServiceMock, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/author/7", nil)
+			req = mux.SetURLVars(req, map[string]string{"author_id": "7"})
+			w := httptest.NewRecorder()
+
+			httpServer.DeleteAuthor(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+			if tt.err != nil {
+				var errorResponse server.ErrorResponse
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+				assert.Equal(t, "author-not-found", errorResponse.Slug)
+			}
+		})
+	}
+}
+
+func TestGetAuthorBooks(t *testing.T) {
+	authorServiceMock := mocks.NewAuthorService(t)
+	bookServiceMock := mocks.NewBookService(t)
+
+	author, err := domain.NewAuthor(domain.NewAuthorData{ID: 7, Name: "George Orwell"})
+	require.NoError(t, err)
+	book, err := domain.NewBook(domain.NewBookData{
+		ID: 1, Title: "1984", Year: 1949, Author: "George Orwell", Price: 1500, Stock: 1, AuthorIDs: []int{7},
+	})
+	require.NoError(t, err)
+
+	authorServiceMock.On("GetAuthor", mock.Anything, 7).Return(author, nil).Once()
+	bookServiceMock.On("GetBooks", mock.Anything, mock.MatchedBy(func(filter domain.BookFilter) bool {
+		return filter.AuthorID == 7
+	}), booksPageSize+1, 0).Return([]domain.Book{book}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, authorServiceMock, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/authors/7/books", nil)
+	req = mux.SetURLVars(req, map[string]string{"author_id": "7"})
+	w := httptest.NewRecorder()
+
+	httpServer.GetAuthorBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var booksResponse []BookResponse
+	err = json.NewDecoder(res.Body).Decode(&booksResponse)
+	require.NoError(t, err)
+	require.Len(t, booksResponse, 1)
+	assert.Equal(t, []int{7}, booksResponse[0].AuthorIDs)
+}
+
+func TestGetAuthorBooks_AuthorNotFound(t *testing.T) {
+	authorServiceMock := mocks.NewAuthorService(t)
+	authorServiceMock.On("GetAuthor", mock.Anything, 7).Return(domain.Author{}, domain.ErrNotFound).Once()
+
+	httpServer := NewHTTPServer(nil, nil, mocks.NewBookService(t), nil, nil, nil, nil, authorServiceMock, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/authors/7/books", nil)
+	req = mux.SetURLVars(req, map[string]string{"author_id": "7"})
+	w := httptest.NewRecorder()
+
+	httpServer.GetAuthorBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var errorResponse server.ErrorResponse
+	err := json.NewDecoder(res.Body).Decode(&errorResponse)
+	require.NoError(t, err)
+	require.Equal(t, "author-not-found", errorResponse.Slug)
+}
@@ -0,0 +1,229 @@
+package httpserver
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// bookExportFormat is a format of GET /admin/books/export.
+type bookExportFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer) (bookExportWriter, error)
+}
+
+var bookExportFormats = map[string]bookExportFormat{
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		newWriter:   newCSVBookExportWriter,
+	},
+	"ndjson": {
+		contentType: "application/x-ndjson",
+		extension:   "ndjson",
+		newWriter:   newNDJSONBookExportWriter,
+	},
+	"xlsx": {
+		contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		extension:   "xlsx",
+		newWriter:   newXLSXBookExportWriter,
+	},
+}
+
+// bookExportWriter writes the exported books one at a time. Flush sends what is buffered,
+// Close ends the file after the last book.
+type bookExportWriter interface {
+	Write(book domain.ExportedBook) error
+	Flush() error
+	Close() error
+}
+
+// bookExportColumns are the columns of the CSV and XLSX exports. The CSV export can be imported back.
+var bookExportColumns = []string{
+	"id", "title", "author", "year", "price", "stock", "isbn",
+	"category_id", "category_ids", "category_names", "author_ids", "created_at",
+}
+
+// bookExportRecord returns the values of the export columns, ints or strings.
+func bookExportRecord(book domain.ExportedBook) []any {
+	return []any{
+		book.ID(), book.Title(), book.Author(), book.Year(), book.Price(), book.Stock(), book.ISBN(),
+		book.CategoryID(), joinExportIDs(book.CategoryIDs()), strings.Join(book.CategoryNames, ";"),
+		joinExportIDs(book.AuthorIDs()), book.CreatedAt().UTC().Format(time.RFC3339),
+	}
+}
+
+// joinExportIDs joins IDs like "1;2;3", see parseImportIDs.
+func joinExportIDs(ids []int) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.Itoa(id)
+	}
+	return strings.Join(values, ";")
+}
+
+type csvBookExportWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVBookExportWriter(w io.Writer) (bookExportWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(bookExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvBookExportWriter{writer: writer, record: make([]string, len(bookExportColumns))}, nil
+}
+
+func (c *csvBookExportWriter) Write(book domain.ExportedBook) error {
+	for i, value := range bookExportRecord(book) {
+		c.record[i] = fmt.Sprint(value)
+	}
+	return c.writer.Write(c.record)
+}
+
+func (c *csvBookExportWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvBookExportWriter) Close() error {
+	return c.Flush()
+}
+
+// ndjsonBookExportWriter writes a BookExportResponse per line.
+type ndjsonBookExportWriter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONBookExportWriter(w io.Writer) (bookExportWriter, error) {
+	return &ndjsonBookExportWriter{encoder: json.NewEncoder(w)}, nil
+}
+
+func (n *ndjsonBookExportWriter) Write(book domain.ExportedBook) error {
+	return n.encoder.Encode(toResponseExportedBook(book))
+}
+
+func (n *ndjsonBookExportWriter) Flush() error {
+	return nil
+}
+
+func (n *ndjsonBookExportWriter) Close() error {
+	return nil
+}
+
+// xlsxBookExportWriter streams a single sheet workbook. The cells hold inline strings,
+// so the workbook needs no shared string table and can be written row by row.
+type xlsxBookExportWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+// xlsxParts are the fixed parts of the workbook.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ` +
+		`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+		`Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Books" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" ` +
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" ` +
+		`Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXLSXBookExportWriter(w io.Writer) (bookExportWriter, error) {
+	zipWriter := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		file, err := zipWriter.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zipWriter.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, xml.Header+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &xlsxBookExportWriter{zip: zipWriter, sheet: sheet}
+	header := make([]any, len(bookExportColumns))
+	for i, column := range bookExportColumns {
+		header[i] = column
+	}
+	if err := x.writeRow(header); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxBookExportWriter) Write(book domain.ExportedBook) error {
+	return x.writeRow(bookExportRecord(book))
+}
+
+func (x *xlsxBookExportWriter) writeRow(values []any) error {
+	x.row++
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, x.row)
+	for i, value := range values {
+		// the export has fewer than 26 columns, so a column is a single letter
+		ref := string(rune('A'+i)) + strconv.Itoa(x.row)
+		switch value := value.(type) {
+		case int:
+			fmt.Fprintf(&row, `<c r="%s"><v>%d</v></c>`, ref, value)
+		default:
+			fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(&row, []byte(fmt.Sprint(value))); err != nil {
+				return err
+			}
+			row.WriteString(`</t></is></c>`)
+		}
+	}
+	row.WriteString(`</row>`)
+
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
+
+func (x *xlsxBookExportWriter) Flush() error {
+	return x.zip.Flush()
+}
+
+func (x *xlsxBookExportWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+// @Summary ExportBooks
+// @Security ApiKeyAuth
+// @Tags book
+// @Description export the books with their category names as a CSV, NDJSON or XLSX file streamed in GET /books order.
+// @Description The filters are those of GET /books, but sold-out books are included unless in_stock is set.
+// @ID export-books
+// @Produce  text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default), ndjson or xlsx"
+// @Param category_id query []int false "category ID, repeat it to match books in any of the categories"
+// @Param include_descendants query bool false "also match the books of the subcategories, true by default"
+// @Param q query string false "full-text search over title and author, results are ordered by relevance"
+// @Param author query string false "author name or a part of it"
+// @Param min_price query int false "minimum price"
+// @Param max_price query int false "maximum price"
+// @Param min_year query int false "minimum year"
+// @Param max_year query int false "maximum year"
+// @Param in_stock query string false "true, false or any (default)"
+// @Param sort query string false "price, year, title or created_at"
+// @Param order query string false "asc (default) or desc"
+// @Success 200 {file} file
+// @Failure 400 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /admin/books/export [get]
+func (h HTTPServer) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	formatName := query.Get("format")
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := bookExportFormats[formatName]
+	if !ok {
+		server.BadRequest("invalid-export-format",
+			fmt.Errorf("unknown export format %q: must be csv, ndjson or xlsx", formatName), w, r)
+		return
+	}
+
+	categoryIDs, err := parseCategoryIDParams(query)
+	if err != nil {
+		server.BadRequest("invalid-category-id", err, w, r)
+		return
+	}
+	filter, err := parseBookFilter(query)
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	filter.CategoryIDs = categoryIDs
+	if !query.Has("in_stock") {
+		filter.InStock = nil
+	}
+
+	// the response starts with the first batch, so that a failing query can still be reported as JSON
+	var (
+		writer  bookExportWriter
+		started bool
+	)
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="books-%s.%s"`,
+			time.Now().UTC().Format("20060102"), format.extension))
+		writer, err = format.newWriter(w)
+		return err
+	}
+	err = h.bookService.ExportBooks(r.Context(), filter, func(books []domain.ExportedBook) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, book := range books {
+			if err := writer.Write(book); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !started {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		// the status is already sent, aborting tells the client the file is incomplete
+		log.Printf("book export failed: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,212 @@
+package httpserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testExportedBooks(t *testing.T) []domain.ExportedBook {
+	t.Helper()
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orwell, err := domain.NewBook(domain.NewBookData{
+		ID: 1, Title: "1984", Year: 1949, Author: "George Orwell", Price: 1000, Stock: 5,
+		CategoryIDs: []int{2, 1}, AuthorIDs: []int{7}, ISBN: "9780451524935", CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+	austen, err := domain.NewBook(domain.NewBookData{
+		ID: 2, Title: `Pride & "Prejudice"`, Year: 1813, Author: "Jane Austen", Price: 900, CategoryID: 1,
+		AuthorIDs: []int{8}, CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+	return []domain.ExportedBook{
+		{Book: orwell, CategoryNames: []string{"Dystopia", "Fiction"}},
+		{Book: austen, CategoryNames: []string{"Fiction"}},
+	}
+}
+
+// exportInBatches makes the ExportBooks mock pass each book to fn in its own batch.
+func exportInBatches(books []domain.ExportedBook) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func([]domain.ExportedBook) error)
+		for _, book := range books {
+			if err := fn([]domain.ExportedBook{book}); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+func TestExportBooks_CSV(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	books := testExportedBooks(t)
+	bookServiceMock.On("ExportBooks", mock.Anything, mock.MatchedBy(func(filter domain.BookFilter) bool {
+		return filter.InStock == nil && filter.Author == "orwell" && assert.ObjectsAreEqual([]int{1}, filter.CategoryIDs)
+	}), mock.Anything).Run(exportInBatches(books)).Return(nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/books/export?author=orwell&category_id=1", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.ExportBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+	assert.Contains(t, res.Header.Get("Content-Disposition"), ".csv")
+
+	records, err := csv.NewReader(res.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		bookExportColumns,
+		{"1", "1984", "George Orwell", "1949", "1000", "5", "9780451524935", "2", "2;1", "Dystopia;Fiction", "7",
+			"2024-05-01T12:00:00Z"},
+		{"2", `Pride & "Prejudice"`, "Jane Austen", "1813", "900", "0", "", "1", "1", "Fiction", "8",
+			"2024-05-01T12:00:00Z"},
+	}, records)
+}
+
+func TestExportBooks_NDJSON(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	bookServiceMock.On("ExportBooks", mock.Anything, mock.Anything, mock.Anything).
+		Run(exportInBatches(testExportedBooks(t))).Return(nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/books/export?format=ndjson", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.ExportBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+	decoder := json.NewDecoder(res.Body)
+	var first, second BookExportResponse
+	require.NoError(t, decoder.Decode(&first))
+	require.NoError(t, decoder.Decode(&second))
+	assert.Equal(t, "1984", first.Title)
+	assert.Equal(t, []string{"Dystopia", "Fiction"}, first.CategoryNames)
+	assert.Equal(t, []int{2, 1}, first.CategoryIDs)
+	assert.Equal(t, `Pride & "Prejudice"`, second.Title)
+	require.ErrorIs(t, decoder.Decode(&BookExportResponse{}), io.EOF)
+}
+
+func TestExportBooks_XLSX(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	bookServiceMock.On("ExportBooks", mock.Anything, mock.Anything, mock.Anything).
+		Run(exportInBatches(testExportedBooks(t))).Return(nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/books/export?format=xlsx", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.ExportBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	names := make([]string, 0, len(archive.File))
+	var sheet string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+		if file.Name == "xl/worksheets/sheet1.xml" {
+			reader, err := file.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			sheet = string(data)
+		}
+	}
+	assert.ElementsMatch(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"}, names)
+	assert.Equal(t, 3, strings.Count(sheet, "<row "))
+	assert.Contains(t, sheet,
+		`<c r="B3" t="inlineStr"><is><t xml:space="preserve">Pride &amp; &#34;Prejudice&#34;</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="E2"><v>1000</v></c>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
+
+func TestExportBooks_Errors(t *testing.T) {
+	t.Run("invalid format", func(t *testing.T) {
+		httpServer := NewHTTPServer(nil, nil, mocks.NewBookService(t), nil, nil, nil, nil, nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/books/export?format=pdf", nil)
+		w := httptest.NewRecorder()
+
+		httpServer.ExportBooks(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		var errorResponse server.ErrorResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+		assert.Equal(t, "invalid-export-format", errorResponse.Slug)
+	})
+
+	t.Run("failed before the first batch", func(t *testing.T) {
+		bookServiceMock := mocks.NewBookService(t)
+		bookServiceMock.On("ExportBooks", mock.Anything, mock.Anything, mock.Anything).
+			Return(errors.New("db is down")).Once()
+
+		httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/books/export", nil)
+		w := httptest.NewRecorder()
+
+		httpServer.ExportBooks(w, req)
+
+		res := w.Result()
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		assert.Equal(t, "application/json; charset=utf-8", res.Header.Get("Content-Type"))
+	})
+
+	t.Run("failed after the first batch", func(t *testing.T) {
+		bookServiceMock := mocks.NewBookService(t)
+		bookServiceMock.On("ExportBooks", mock.Anything, mock.Anything, mock.Anything).
+			Run(exportInBatches(testExportedBooks(t)[:1])).Return(errors.New("db is down")).Once()
+
+		httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/books/export", nil)
+		w := httptest.NewRecorder()
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			httpServer.ExportBooks(w, req)
+		})
+	})
+}
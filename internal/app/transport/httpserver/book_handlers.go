@@ -3,8 +3,11 @@ package httpserver
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
@@ -44,6 +47,39 @@ func (h HTTPServer) GetBook(w http.ResponseWriter, r *http.Request) {
 	server.RespondOK(response, w, r)
 }
 
+// @Summary GetBookByISBN
+// @Tags book
+// @Description get book by ISBN-10 or ISBN-13
+// @ID get-book-by-isbn
+// @Accept  json
+// @Produce  json
+// @Param isbn path string true "ISBN-10 or ISBN-13, hyphens are allowed"
+// @Success 200 {object} BookResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Router /books/isbn/{isbn} [get]
+// GetBookByISBN returns a book by ISBN
+func (h HTTPServer) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	isbn, err := domain.NormalizeISBN(vars["isbn"])
+	if err != nil {
+		server.BadRequest("invalid-isbn", err, w, r)
+		return
+	}
+	book, err := h.bookService.GetBookByISBN(r.Context(), isbn)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseBook(book)
+
+	server.RespondOK(response, w, r)
+}
+
 // @Summary CreateBook
 // @Security ApiKeyAuth
 // @Tags book
@@ -55,10 +91,17 @@ func (h HTTPServer) GetBook(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} BookResponse
 // @Failure 400,404 {object} server.ErrorResponse
 // @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
 // @Failure 500 {object} server.ErrorResponse
 // @Router /book [post]
 // CreateBook creates a new book
 func (h HTTPServer) CreateBook(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	var bookRequest BookRequest
 	if err := json.NewDecoder(r.Body).Decode(&bookRequest); err != nil {
 		server.BadRequest("invalid-json", err, w, r)
@@ -72,12 +115,24 @@ func (h HTTPServer) CreateBook(w http.ResponseWriter, r *http.Request) {
 
 	book, err := toDomainBook(bookRequest)
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.BadRequest("invalid-request", err, w, r)
 		return
 	}
 
-	insertedBook, err := h.bookService.CreateBook(r.Context(), book)
+	insertedBook, err := h.bookService.CreateBook(r.Context(), user.ID, book)
 	if err != nil {
+		if errors.Is(err, domain.ErrISBNConflict) {
+			server.Conflict("isbn-conflict", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrAuthorNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.NotFound("category-not-found", err, w, r)
+			return
+		}
 		server.RespondWithError(err, w, r)
 		return
 	}
@@ -99,10 +154,17 @@ func (h HTTPServer) CreateBook(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} BookResponse
 // @Failure 400,404 {object} server.ErrorResponse
 // @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
 // @Failure 500 {object} server.ErrorResponse
 // @Router /book/{book_id} [patch]
 // UpdateBook updates a book by ID
 func (h HTTPServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	vars := mux.Vars(r)
 	bookID, err := strconv.Atoi(vars["book_id"])
 	if err != nil {
@@ -132,20 +194,39 @@ func (h HTTPServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	book, err := domain.NewBook(domain.NewBookData{
-		ID:         bookID,
-		Title:      bookRequest.Title,
-		Year:       bookRequest.Year,
-		Author:     bookRequest.Author,
-		Price:      bookRequest.Price,
-		CategoryID: bookRequest.CategoryID,
+		ID:          bookID,
+		Title:       bookRequest.Title,
+		Year:        bookRequest.Year,
+		Author:      bookRequest.Author,
+		Price:       bookRequest.Price,
+		CategoryID:  bookRequest.CategoryID,
+		CategoryIDs: bookRequest.CategoryIDs,
+		ISBN:        bookRequest.ISBN,
+		AuthorIDs:   bookRequest.AuthorIDs,
 	})
 	if err != nil {
-		server.RespondWithError(err, w, r)
+		server.BadRequest("invalid-request", err, w, r)
 		return
 	}
 
-	updatedBook, err := h.bookService.UpdateBook(r.Context(), book)
+	updatedBook, err := h.bookService.UpdateBook(r.Context(), user.ID, book)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrISBNConflict) {
+			server.Conflict("isbn-conflict", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrAuthorNotFound) {
+			server.NotFound("author-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.NotFound("category-not-found", err, w, r)
+			return
+		}
 		server.RespondWithError(err, w, r)
 		return
 	}
@@ -158,7 +239,7 @@ func (h HTTPServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 // @Summary DeleteBook
 // @Security ApiKeyAuth
 // @Tags book
-// @Description delete book by ID
+// @Description delete book by ID, it can be restored until it is purged
 // @ID delete-book
 // @Accept  json
 // @Produce  json
@@ -170,6 +251,12 @@ func (h HTTPServer) UpdateBook(w http.ResponseWriter, r *http.Request) {
 // @Router /book/{book_id} [delete]
 // DeleteBook deletes a book by ID
 func (h HTTPServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	vars := mux.Vars(r)
 	bookID, err := strconv.Atoi(vars["book_id"])
 	if err != nil {
@@ -187,7 +274,7 @@ func (h HTTPServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.bookService.DeleteBook(r.Context(), bookID)
+	err = h.bookService.DeleteBook(r.Context(), user.ID, bookID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("book-not-found", err, w, r)
@@ -200,50 +287,267 @@ func (h HTTPServer) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	server.RespondOK(map[string]bool{"deleted": true}, w, r)
 }
 
+// @Summary RestoreBook
+// @Security ApiKeyAuth
+// @Tags book
+// @Description restore a deleted book by ID, unless one of its categories was deleted too
+// @ID restore-book
+// @Accept  json
+// @Produce  json
+// @Param book_id path int true "book ID"
+// @Success 200 {object} BookResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /book/{book_id}/restore [post]
+func (h HTTPServer) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	book, err := h.bookService.RestoreBook(r.Context(), user.ID, bookID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("deleted-book-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.Conflict("book-category-deleted", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrISBNConflict) {
+			server.Conflict("isbn-conflict", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseBook(book)
+
+	server.RespondOK(response, w, r)
+}
+
 // @Summary GetBooks
 // @Tags book
 // @Description get books
 // @ID get-books
 // @Accept  json
 // @Produce  json
-// @Param category_id query []int false "category ID"
-// @Param page query int false "page number"
+// @Param category_id query []int false "category ID, repeat it to match books in any of the categories"
+// @Param include_descendants query bool false "also match the books of the subcategories, true by default"
+// @Param q query string false "full-text search over title and author, results are ordered by relevance"
+// @Param author query string false "author name or a part of it"
+// @Param min_price query int false "minimum price"
+// @Param max_price query int false "maximum price"
+// @Param min_year query int false "minimum year"
+// @Param max_year query int false "maximum year"
+// @Param in_stock query string false "true (default), false or any; anything but true is for admins only"
+// @Param deleted query bool false "list the deleted books instead, for admins only; in_stock defaults to any"
+// @Param sort query string false "sort field: price, year, title or created_at"
+// @Param order query string false "sort direction: asc (default) or desc"
+// @Param page query int false "page number, can't be combined with cursor"
+// @Param cursor query string false "next page cursor from the X-Next-Cursor header"
+// @Param limit query int false "page size, 10 by default and 100 at most"
+// @Param with_total query bool false "return the number of matching books in the X-Total-Count header"
+// @Param envelope query bool false "wrap the books in a paginated envelope, like the urn:bookshop:paginated profile"
 // @Success 200 {array} BookResponse
+// @Header 200 {string} X-Next-Cursor "cursor of the next page, absent on the last page"
+// @Header 200 {int} X-Total-Count "number of matching books, only with with_total=true"
 // @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
 // @Router /books [get]
 func (h HTTPServer) GetBooks(w http.ResponseWriter, r *http.Request) {
+	h.listBooks(w, r, 0)
+}
+
+// listBooks responds with the books matching the query string, linked to the author unless authorID is 0.
+func (h HTTPServer) listBooks(w http.ResponseWriter, r *http.Request, authorID int) {
 	// filter by category IDs
-	queryCategoryIDs := r.URL.Query()["category_id"]
-	categoryIDs := make([]int, 0, len(queryCategoryIDs))
-	for _, id := range queryCategoryIDs {
-		categoryID, err := strconv.Atoi(id)
+	categoryIDs, err := parseCategoryIDParams(r.URL.Query())
+	if err != nil {
+		server.BadRequest("invalid-category-id", err, w, r)
+		return
+	}
+	query := r.URL.Query()
+	filter, err := parseBookFilter(query)
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	filter.CategoryIDs = categoryIDs
+	filter.AuthorID = authorID
+	if (filter.InStock == nil || !*filter.InStock || filter.Deleted) && !h.isAdmin(r) {
+		server.Unauthorised("not-admin", nil, w, r)
+		return
+	}
+	pageSize, err := parseLimitParam(query.Get("limit"), booksPageSize, maxBooksPageSize)
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	withTotal, err := parseBoolParam(query.Get("with_total"))
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	// page or cursor
+	limit, offset := pageToLimitOffset(r, pageSize)
+	if token := query.Get("cursor"); token != "" {
+		if query.Has("page") {
+			server.BadRequest("invalid-request", errors.New("cursor and page can't be combined"), w, r)
+			return
+		}
+		cursor, err := h.bookService.DecodeCursor(token)
 		if err != nil {
-			server.BadRequest("invalid-category-id", err, w, r)
+			server.BadRequest("invalid-cursor", err, w, r)
 			return
 		}
-		categoryIDs = append(categoryIDs, categoryID)
-	}
-	// page
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil {
-		page = 1
+		filter.After = &cursor
+		if err := filter.Validate(); err != nil {
+			server.BadRequest("invalid-cursor", err, w, r)
+			return
+		}
+		limit, offset = pageSize, 0
 	}
-	var limit, offset int
-	if page > 0 {
-		limit = 10
-		offset = (page - 1) * limit
+
+	envelope := server.WantsPage(r)
+	var total int
+	if withTotal || envelope {
+		total, err = h.bookService.CountBooks(r.Context(), filter)
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		w.Header().Set(TotalCountHeader, strconv.Itoa(total))
 	}
 
-	books, err := h.bookService.GetBooks(r.Context(), categoryIDs, limit, offset)
+	// one more book tells whether there is a next page
+	fetchLimit := limit
+	if limit > 0 {
+		fetchLimit++
+	}
+	books, err := h.bookService.GetBooks(r.Context(), filter, fetchLimit, offset)
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
+	var nextCursor string
+	if limit > 0 && len(books) > limit {
+		books = books[:limit]
+		nextCursor = h.bookService.EncodeCursor(filter.CursorAfter(books[limit-1]))
+		w.Header().Set(NextCursorHeader, nextCursor)
+	}
 
 	response := make([]BookResponse, 0, len(books))
 	for _, book := range books {
 		response = append(response, toResponseBook(book))
 	}
 
-	server.RespondOK(response, w, r)
+	switch {
+	case !envelope:
+		server.RespondOK(response, w, r)
+	case filter.After != nil:
+		server.RespondPage(server.NewCursorPage(r, response, total, limit, nextCursor), w, r)
+	default:
+		page := 1
+		if limit > 0 {
+			page = offset/limit + 1
+		}
+		server.RespondPage(server.NewNumberedPage(r, response, total, page, limit), w, r)
+	}
+}
+
+// parseCategoryIDParams parses the repeated category_id parameter.
+func parseCategoryIDParams(query url.Values) ([]int, error) {
+	categoryIDs := make([]int, 0, len(query["category_id"]))
+	for _, id := range query["category_id"] {
+		categoryID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs = append(categoryIDs, categoryID)
+	}
+	return categoryIDs, nil
+}
+
+// parseBookFilter reads the book filter and sort from the query string, leaving the category IDs out.
+// Only the books in stock are returned unless in_stock says otherwise or deleted books are asked for.
+func parseBookFilter(query url.Values) (domain.BookFilter, error) {
+	filter := domain.BookFilter{
+		Query:  strings.TrimSpace(query.Get("q")),
+		Author: strings.TrimSpace(query.Get("author")),
+	}
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+		{"min_year", &filter.MinYear},
+		{"max_year", &filter.MaxYear},
+	} {
+		if query.Get(param.name) == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(query.Get(param.name))
+		if err != nil {
+			return domain.BookFilter{}, fmt.Errorf("invalid %s: %w", param.name, err)
+		}
+		*param.value = parsed
+	}
+
+	deleted, err := parseBoolParam(query.Get("deleted"))
+	if err != nil {
+		return domain.BookFilter{}, fmt.Errorf("invalid deleted: %w", err)
+	}
+	filter.Deleted = deleted
+
+	switch inStock := query.Get("in_stock"); inStock {
+	case "any":
+	case "":
+		if !filter.Deleted {
+			inStockOnly := true
+			filter.InStock = &inStockOnly
+		}
+	default:
+		parsed, err := strconv.ParseBool(inStock)
+		if err != nil {
+			return domain.BookFilter{}, fmt.Errorf("invalid in_stock: %w", err)
+		}
+		filter.InStock = &parsed
+	}
+
+	filter.IncludeDescendants = true
+	if includeDescendants := query.Get("include_descendants"); includeDescendants != "" {
+		parsed, err := strconv.ParseBool(includeDescendants)
+		if err != nil {
+			return domain.BookFilter{}, fmt.Errorf("invalid include_descendants: %w", err)
+		}
+		filter.IncludeDescendants = parsed
+	}
+
+	filter.Sort.Field = domain.BookSortField(query.Get("sort"))
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.Sort.Desc = true
+	default:
+		return domain.BookFilter{}, fmt.Errorf("invalid order %q: must be asc or desc", order)
+	}
+
+	if err := filter.Validate(); err != nil {
+		return domain.BookFilter{}, err
+	}
+	return filter, nil
 }
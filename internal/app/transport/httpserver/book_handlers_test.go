@@ -2,17 +2,30 @@ package httpserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// testAdmin is the admin the admin handlers are called as.
+var testAdmin = domain.User{ID: 1, Username: "admin", Admin: true}
+
+// withAdmin puts testAdmin in the request context, like CheckAdmin does.
+func withAdmin(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), ContextUserKey, testAdmin))
+}
+
 func TestHttpServer_GetBook(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
 
@@ -26,9 +39,9 @@ func TestHttpServer_GetBook(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	bookServiceMock.On("CreateBook", mock.Anything, mock.Anything).Return(testCreatedBook, nil)
+	bookServiceMock.On("CreateBook", mock.Anything, testAdmin.ID, mock.Anything).Return(testCreatedBook, nil)
 
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	newBookRequest := []byte(`{
 		  "title": "The history of Golang",
@@ -43,7 +56,7 @@ func TestHttpServer_GetBook(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBuffer(newBookRequest))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateBook(w, req)
+	httpServer.CreateBook(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -66,7 +79,7 @@ func TestHttpServer_GetBook(t *testing.T) {
 
 func TestGetBook_ReturnsBadRequestForInvalidID(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/book/invalid", nil)
 	w := httptest.NewRecorder()
@@ -81,13 +94,13 @@ func TestGetBook_ReturnsBadRequestForInvalidID(t *testing.T) {
 
 func TestHttpServer_CreateBook_ReturnsBadRequestForInvalidJSON(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	invalidJSON := []byte(`{ "title": "The history of Golang", "year": "invalid" }`)
 	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBuffer(invalidJSON))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateBook(w, req)
+	httpServer.CreateBook(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -97,14 +110,14 @@ func TestHttpServer_CreateBook_ReturnsBadRequestForInvalidJSON(t *testing.T) {
 
 func TestHttpServer_CreateBook_ReturnsBadRequestForInvalidRequest(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	invalidRequest := []byte(
 		`{ "title": "", "year": 2024, "author": "Rob Pike", "price": 1000, "stock": 100, "categoryId": 1 }`)
 	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBuffer(invalidRequest))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateBook(w, req)
+	httpServer.CreateBook(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -114,12 +127,12 @@ func TestHttpServer_CreateBook_ReturnsBadRequestForInvalidRequest(t *testing.T)
 
 func TestHttpServer_UpdateBook_ReturnsBadRequestForInvalidID(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPatch, "/book/invalid", nil)
 	w := httptest.NewRecorder()
 
-	httpServer.UpdateBook(w, req)
+	httpServer.UpdateBook(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -129,15 +142,674 @@ func TestHttpServer_UpdateBook_ReturnsBadRequestForInvalidID(t *testing.T) {
 
 func TestHttpServer_DeleteBook_ReturnsBadRequestForInvalidID(t *testing.T) {
 	bookServiceMock := mocks.NewBookService(t)
-	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/book/invalid", nil)
 	w := httptest.NewRecorder()
 
-	httpServer.DeleteBook(w, req)
+	httpServer.DeleteBook(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
 
 	require.Equal(t, http.StatusBadRequest, res.StatusCode)
 }
+
+func TestHttpServer_CreateBook_ReturnsBadRequestWithoutUser(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	body := `{"title": "Book", "year": 1980, "author": "Author", "price": 100, "stock": 1, "categoryId": 1}`
+	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	httpServer.CreateBook(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	var errorResponse server.ErrorResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+	require.Equal(t, "invalid-user", errorResponse.Slug)
+	bookServiceMock.AssertNumberOfCalls(t, "CreateBook", 0)
+}
+
+func TestHttpServer_GetBooks_Search(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	testBook, err := domain.NewBook(domain.NewBookData{
+		ID:         1,
+		Title:      "The history of Golang",
+		Year:       2024,
+		Author:     "Rob Pike",
+		Price:      1000,
+		Stock:      100,
+		CategoryID: 1,
+		Highlight: domain.BookHighlight{
+			Title:  "The history of <mark>Golang</mark>",
+			Author: "Rob Pike",
+		},
+	})
+	require.NoError(t, err)
+
+	inStock := true
+	filter := domain.BookFilter{CategoryIDs: []int{1}, Query: "golang", InStock: &inStock, IncludeDescendants: true}
+	bookServiceMock.On("GetBooks", mock.Anything, filter, booksPageSize+1, 0).Return([]domain.Book{testBook}, nil)
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books?category_id=1&q=+golang+", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var books []BookResponse
+	err = json.NewDecoder(res.Body).Decode(&books)
+	require.NoError(t, err)
+
+	require.Len(t, books, 1)
+	require.NotNil(t, books[0].Highlight)
+	require.Equal(t, "The history of <mark>Golang</mark>", books[0].Highlight.Title)
+	require.Equal(t, "Rob Pike", books[0].Highlight.Author)
+}
+
+func TestHttpServer_GetBooks_Filter(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	inStock := true
+	filter := domain.BookFilter{
+		CategoryIDs: []int{},
+		Author:      "pike",
+		MinPrice:    500,
+		MaxPrice:    1500,
+		MinYear:     2000,
+		InStock:     &inStock,
+		Sort:        domain.BookSort{Field: domain.BookSortByPrice, Desc: true},
+	}
+	bookServiceMock.On("GetBooks", mock.Anything, filter, booksPageSize+1, 0).Return([]domain.Book{}, nil)
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/books?author=pike&min_price=500&max_price=1500&min_year=2000&sort=price&order=desc&include_descendants=false",
+		nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestHttpServer_GetBooks_ReturnsBadRequestForInvalidFilter(t *testing.T) {
+	tests := map[string]string{
+		"unknown sort field": "/books?sort=stock",
+		"unknown order":      "/books?sort=price&order=up",
+		"inverted price":     "/books?min_price=1500&max_price=500",
+		"inverted year":      "/books?min_year=2024&max_year=2000",
+		"negative price":     "/books?min_price=-1",
+		"invalid year":       "/books?min_year=new",
+		"invalid in_stock":   "/books?in_stock=maybe",
+	}
+
+	for name, target := range tests {
+		t.Run(name, func(t *testing.T) {
+			httpServer := NewHTTPServer(nil, nil, mocks.NewBookService(t), nil, nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+
+			httpServer.GetBooks(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, "invalid-request", errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_GetBooks_SoldOutIsForAdmins(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	tokenServiceMock := mocks.NewTokenService(t)
+
+	tokenServiceMock.On("GetUser", "user-token").Return(domain.User{Username: "user"}, nil)
+	tokenServiceMock.On("GetUser", "admin-token").Return(domain.User{Username: "admin", Admin: true}, nil)
+
+	inStock := false
+	filter := domain.BookFilter{CategoryIDs: []int{}, InStock: &inStock, IncludeDescendants: true}
+	bookServiceMock.On("GetBooks", mock.Anything, filter, booksPageSize+1, 0).Return([]domain.Book{}, nil)
+
+	httpServer := NewHTTPServer(nil, tokenServiceMock, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	for token, status := range map[string]int{
+		"":            http.StatusUnauthorized,
+		"user-token":  http.StatusUnauthorized,
+		"admin-token": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/books?in_stock=false", nil)
+		if token != "" {
+			req.Header.Set(AuthorizationHeader, BearerPrefix+token)
+		}
+		w := httptest.NewRecorder()
+
+		httpServer.GetBooks(w, req)
+
+		res := w.Result()
+		res.Body.Close()
+
+		require.Equal(t, status, res.StatusCode, token)
+	}
+}
+
+func TestHttpServer_GetBooks_NextCursor(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	books := make([]domain.Book, 0, 3)
+	for id := 1; id <= 3; id++ {
+		book, err := domain.NewBook(domain.NewBookData{ID: id, Title: "Book", Price: id * 100, Stock: 1})
+		require.NoError(t, err)
+		books = append(books, book)
+	}
+
+	inStock := true
+	filter := domain.BookFilter{
+		CategoryIDs:        []int{},
+		IncludeDescendants: true,
+		InStock:            &inStock,
+		Sort:               domain.BookSort{Field: domain.BookSortByPrice},
+	}
+	bookServiceMock.On("CountBooks", mock.Anything, filter).Return(42, nil)
+	bookServiceMock.On("GetBooks", mock.Anything, filter, 3, 0).Return(books, nil)
+	bookServiceMock.On("EncodeCursor", domain.BookCursor{Sort: filter.Sort, Value: "200", ID: 2}).Return("next")
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books?sort=price&limit=2&with_total=true", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "next", res.Header.Get(NextCursorHeader))
+	require.Equal(t, "42", res.Header.Get(TotalCountHeader))
+
+	var response []BookResponse
+	err := json.NewDecoder(res.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 2)
+}
+
+func TestHttpServer_GetBooks_Cursor(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	cursor := domain.BookCursor{Sort: domain.BookSort{Field: domain.BookSortByPrice}, Value: "200", ID: 2}
+	inStock := true
+	filter := domain.BookFilter{
+		CategoryIDs:        []int{},
+		IncludeDescendants: true,
+		InStock:            &inStock,
+		Sort:               cursor.Sort,
+		After:              &cursor,
+	}
+	bookServiceMock.On("DecodeCursor", "next").Return(cursor, nil)
+	bookServiceMock.On("GetBooks", mock.Anything, filter, 3, 0).Return([]domain.Book{}, nil)
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books?sort=price&limit=2&cursor=next", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Empty(t, res.Header.Get(NextCursorHeader))
+}
+
+func TestHttpServer_GetBooks_ReturnsBadRequestForInvalidPagination(t *testing.T) {
+	cursor := domain.BookCursor{ID: 2}
+	tests := map[string]struct {
+		target string
+		slug   string
+	}{
+		"limit too big":       {"/books?limit=101", "invalid-request"},
+		"zero limit":          {"/books?limit=0", "invalid-request"},
+		"cursor and page":     {"/books?cursor=next&page=2", "invalid-request"},
+		"invalid with_total":  {"/books?with_total=maybe", "invalid-request"},
+		"tampered cursor":     {"/books?cursor=tampered", "invalid-cursor"},
+		"cursor of text sort": {"/books?cursor=next&sort=title", "invalid-cursor"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			bookServiceMock.On("DecodeCursor", "next").Return(cursor, nil).Maybe()
+			bookServiceMock.On("DecodeCursor", "tampered").Return(domain.BookCursor{}, domain.ErrInvalidCursor).Maybe()
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			w := httptest.NewRecorder()
+
+			httpServer.GetBooks(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_GetBooks_Envelope(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	books := make([]domain.Book, 0, 3)
+	for id := 3; id <= 5; id++ {
+		book, err := domain.NewBook(domain.NewBookData{ID: id, Title: "Book", Price: 100, Stock: 1})
+		require.NoError(t, err)
+		books = append(books, book)
+	}
+
+	inStock := true
+	filter := domain.BookFilter{CategoryIDs: []int{}, InStock: &inStock, IncludeDescendants: true}
+	bookServiceMock.On("CountBooks", mock.Anything, filter).Return(7, nil)
+	bookServiceMock.On("GetBooks", mock.Anything, filter, 3, 2).Return(books, nil)
+	bookServiceMock.On("EncodeCursor", domain.BookCursor{ID: 4}).Return("next")
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books?envelope=true&limit=2&page=2", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, `</books?envelope=true&limit=2&page=3>; rel="next", `+
+		`</books?envelope=true&limit=2&page=1>; rel="prev"`, res.Header.Get("Link"))
+
+	var page server.Page[BookResponse]
+	err := json.NewDecoder(res.Body).Decode(&page)
+	require.NoError(t, err)
+
+	require.Len(t, page.Items, 2)
+	require.Equal(t, 7, page.Total)
+	require.Equal(t, 2, page.Page)
+	require.Equal(t, 2, page.PageSize)
+	require.Equal(t, "/books?envelope=true&limit=2&page=3", page.Next)
+	require.Equal(t, "/books?envelope=true&limit=2&page=1", page.Prev)
+}
+
+func TestHttpServer_GetBooks_CursorEnvelope(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	books := make([]domain.Book, 0, 3)
+	for id := 3; id <= 5; id++ {
+		book, err := domain.NewBook(domain.NewBookData{ID: id, Title: "Book", Price: 100, Stock: 1})
+		require.NoError(t, err)
+		books = append(books, book)
+	}
+
+	cursor := domain.BookCursor{ID: 2}
+	inStock := true
+	filter := domain.BookFilter{CategoryIDs: []int{}, InStock: &inStock, After: &cursor, IncludeDescendants: true}
+	bookServiceMock.On("DecodeCursor", "current").Return(cursor, nil)
+	bookServiceMock.On("CountBooks", mock.Anything, filter).Return(7, nil)
+	bookServiceMock.On("GetBooks", mock.Anything, filter, 3, 0).Return(books, nil)
+	bookServiceMock.On("EncodeCursor", domain.BookCursor{ID: 4}).Return("next")
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books?cursor=current&limit=2", nil)
+	req.Header.Set("Accept", `application/json;profile="urn:bookshop:paginated"`)
+	w := httptest.NewRecorder()
+
+	httpServer.GetBooks(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, `</books?cursor=next&limit=2>; rel="next"`, res.Header.Get("Link"))
+
+	var page server.Page[BookResponse]
+	err := json.NewDecoder(res.Body).Decode(&page)
+	require.NoError(t, err)
+
+	require.Len(t, page.Items, 2)
+	require.Equal(t, 7, page.Total)
+	require.Zero(t, page.Page)
+	require.Equal(t, "/books?cursor=next&limit=2", page.Next)
+	require.Empty(t, page.Prev)
+}
+
+func TestHttpServer_GetBookByISBN(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	testBook, err := domain.NewBook(domain.NewBookData{ID: 1, Title: "Book", ISBN: "9780306406157"})
+	require.NoError(t, err)
+	bookServiceMock.On("GetBookByISBN", mock.Anything, "9780306406157").Return(testBook, nil)
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/isbn/0-306-40615-2", nil)
+	req = mux.SetURLVars(req, map[string]string{"isbn": "0-306-40615-2"})
+	w := httptest.NewRecorder()
+
+	httpServer.GetBookByISBN(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var response BookResponse
+	err = json.NewDecoder(res.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Equal(t, 1, response.ID)
+	require.Equal(t, "9780306406157", response.ISBN)
+}
+
+func TestHttpServer_GetBookByISBN_ReturnsBadRequestForInvalidISBN(t *testing.T) {
+	httpServer := NewHTTPServer(nil, nil, mocks.NewBookService(t), nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/books/isbn/0-306-40615-3", nil)
+	req = mux.SetURLVars(req, map[string]string{"isbn": "0-306-40615-3"})
+	w := httptest.NewRecorder()
+
+	httpServer.GetBookByISBN(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var errorResponse server.ErrorResponse
+	err := json.NewDecoder(res.Body).Decode(&errorResponse)
+	require.NoError(t, err)
+	require.Equal(t, "invalid-isbn", errorResponse.Slug)
+}
+
+func TestHttpServer_CreateBook_ISBN(t *testing.T) {
+	tests := map[string]struct {
+		isbn   string
+		err    error
+		status int
+		slug   string
+	}{
+		"invalid checksum": {"978-0-306-40615-8", nil, http.StatusBadRequest, "invalid-request"},
+		"duplicate":        {"978-0-306-40615-7", domain.ErrISBNConflict, http.StatusConflict, "isbn-conflict"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			if tt.err != nil {
+				bookServiceMock.On("CreateBook", mock.Anything, testAdmin.ID, mock.Anything).Return(domain.Book{}, tt.err)
+			}
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			body := `{"title": "Book", "year": 1980, "author": "Author", "price": 100, "stock": 1, "categoryId": 1, ` +
+				`"isbn": "` + tt.isbn + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			httpServer.CreateBook(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_CreateBook_Authors(t *testing.T) {
+	tests := map[string]struct {
+		authorIDs string
+		err       error
+		status    int
+		slug      string
+	}{
+		"duplicate author": {"[1, 1]", nil, http.StatusBadRequest, "invalid-request"},
+		"invalid author":   {"[0]", nil, http.StatusBadRequest, "invalid-request"},
+		"unknown author":   {"[42]", domain.ErrAuthorNotFound, http.StatusBadRequest, "author-not-found"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			if tt.err != nil {
+				bookServiceMock.On("CreateBook", mock.Anything, testAdmin.ID, mock.Anything).Return(domain.Book{}, tt.err)
+			}
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			body := `{"title": "Book", "year": 1980, "author": "Author", "price": 100, "stock": 1, "categoryId": 1, ` +
+				`"authorIds": ` + tt.authorIDs + `}`
+			req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			httpServer.CreateBook(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_CreateBook_Categories(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	bookServiceMock.On("CreateBook", mock.Anything, testAdmin.ID, mock.MatchedBy(func(book domain.Book) bool {
+		return book.CategoryID() == 3 && assert.ObjectsAreEqual([]int{3, 5}, book.CategoryIDs())
+	})).Return(func(_ context.Context, _ int, book domain.Book) (domain.Book, error) {
+		return book, nil
+	}).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	body := `{"title": "Book", "year": 1980, "author": "Author", "price": 100, "stock": 1, "categoryIds": [3, 5]}`
+	req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+
+	httpServer.CreateBook(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var bookResponse BookResponse
+	err := json.NewDecoder(res.Body).Decode(&bookResponse)
+	require.NoError(t, err)
+	assert.Equal(t, 3, bookResponse.CategoryID)
+	assert.Equal(t, []int{3, 5}, bookResponse.CategoryIDs)
+}
+
+func TestHttpServer_CreateBook_CategoryErrors(t *testing.T) {
+	tests := map[string]struct {
+		categories string
+		err        error
+		slug       string
+	}{
+		"no category":        {`"categoryIds": []`, nil, "invalid-request"},
+		"duplicate category": {`"categoryIds": [1, 1]`, nil, "invalid-request"},
+		"invalid category":   {`"categoryId": 1, "categoryIds": [-1]`, nil, "invalid-request"},
+		"unknown category":   {`"categoryIds": [42]`, domain.ErrCategoryNotFound, "category-not-found"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			if tt.err != nil {
+				bookServiceMock.On("CreateBook", mock.Anything, testAdmin.ID, mock.Anything).Return(domain.Book{}, tt.err)
+			}
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			body := `{"title": "Book", "year": 1980, "author": "Author", "price": 100, "stock": 1, ` + tt.categories + `}`
+			req := httptest.NewRequest(http.MethodPost, "/book", bytes.NewBufferString(body))
+			w := httptest.NewRecorder()
+
+			httpServer.CreateBook(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_RestoreBook(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	book, err := domain.NewBook(domain.NewBookData{ID: 1, Title: "Book", CategoryID: 2})
+	require.NoError(t, err)
+	bookServiceMock.On("RestoreBook", mock.Anything, testAdmin.ID, 1).Return(book, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/book/1/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"book_id": "1"})
+	w := httptest.NewRecorder()
+
+	httpServer.RestoreBook(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var response BookResponse
+	err = json.NewDecoder(res.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.ID)
+	assert.Nil(t, response.DeletedAt)
+}
+
+func TestHttpServer_RestoreBook_Errors(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		status int
+		slug   string
+	}{
+		"not deleted":      {domain.ErrNotFound, http.StatusBadRequest, "deleted-book-not-found"},
+		"category deleted": {domain.ErrCategoryNotFound, http.StatusConflict, "book-category-deleted"},
+		"isbn taken":       {domain.ErrISBNConflict, http.StatusConflict, "isbn-conflict"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			bookServiceMock.On("RestoreBook", mock.Anything, testAdmin.ID, 1).Return(domain.Book{}, tt.err).Once()
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/book/1/restore", nil)
+			req = mux.SetURLVars(req, map[string]string{"book_id": "1"})
+			w := httptest.NewRecorder()
+
+			httpServer.RestoreBook(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestHttpServer_GetBooks_DeletedIsForAdmins(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+	tokenServiceMock := mocks.NewTokenService(t)
+
+	tokenServiceMock.On("GetUser", "user-token").Return(domain.User{Username: "user"}, nil)
+	tokenServiceMock.On("GetUser", "admin-token").Return(domain.User{Username: "admin", Admin: true}, nil)
+
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	book, err := domain.NewBook(domain.NewBookData{ID: 1, Title: "Book", CategoryID: 2, DeletedAt: deletedAt})
+	require.NoError(t, err)
+	// the deleted books are listed whatever their stock
+	filter := domain.BookFilter{CategoryIDs: []int{}, Deleted: true, IncludeDescendants: true}
+	bookServiceMock.On("GetBooks", mock.Anything, filter, booksPageSize+1, 0).Return([]domain.Book{book}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, tokenServiceMock, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	for _, token := range []string{"", "user-token", "admin-token"} {
+		t.Run("token "+token, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/books?deleted=true", nil)
+			if token != "" {
+				req.Header.Set(AuthorizationHeader, BearerPrefix+token)
+			}
+			w := httptest.NewRecorder()
+
+			httpServer.GetBooks(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			if token != "admin-token" {
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode, token)
+				return
+			}
+			require.Equal(t, http.StatusOK, res.StatusCode)
+
+			var response []BookResponse
+			err := json.NewDecoder(res.Body).Decode(&response)
+			require.NoError(t, err)
+			require.Len(t, response, 1)
+			require.NotNil(t, response[0].DeletedAt)
+			assert.True(t, deletedAt.Equal(*response[0].DeletedAt))
+		})
+	}
+}
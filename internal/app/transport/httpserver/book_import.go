@@ -0,0 +1,193 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+var errUnsupportedImportFormat = errors.New("unsupported import format, send CSV or NDJSON")
+
+// importMediaTypes maps the accepted Content-Type values to the import formats.
+var importMediaTypes = map[string]string{
+	"text/csv":                "csv",
+	"application/csv":         "csv",
+	"application/x-ndjson":    "ndjson",
+	"application/jsonl":       "ndjson",
+	"application/x-jsonlines": "ndjson",
+}
+
+// importRow is a row of an import. Err is set when the row can't be parsed.
+type importRow struct {
+	Line    int
+	Request BookRequest
+	Err     error
+}
+
+// bookImportReader reads the rows of an import one at a time. It returns io.EOF after the last row,
+// any other error means the rest of the input can't be read.
+type bookImportReader interface {
+	Next() (importRow, error)
+}
+
+// newBookImportReader picks the reader of the ?format= parameter, or of the Content-Type.
+func newBookImportReader(r *http.Request) (bookImportReader, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = importMediaTypes[mediaType]
+	}
+
+	switch format {
+	case "csv":
+		return newCSVBookImportReader(r.Body)
+	case "ndjson":
+		return &ndjsonBookImportReader{reader: bufio.NewReader(r.Body)}, nil
+	default:
+		return nil, errUnsupportedImportFormat
+	}
+}
+
+// csvBookImportReader reads CSV with a header row. The columns are named like the BookRequest fields,
+// in camelCase or snake_case, and the ID lists are separated by semicolons. Unknown columns are ignored,
+// so that an export can be imported back.
+type csvBookImportReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVBookImportReader(body io.Reader) (*csvBookImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: CSV header", domain.ErrRequired)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		column := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+		if i == 0 {
+			// spreadsheets often start CSV files with a byte order mark
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+	if !seen["title"] {
+		return nil, fmt.Errorf("%w: title column", domain.ErrRequired)
+	}
+
+	return &csvBookImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvBookImportReader) Next() (importRow, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return importRow{Line: parseErr.StartLine, Err: err}, nil
+		}
+		return importRow{}, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	row := importRow{Line: line}
+	row.Request, row.Err = c.parse(record)
+	return row, nil
+}
+
+func (c *csvBookImportReader) parse(record []string) (BookRequest, error) {
+	var (
+		request BookRequest
+		err     error
+	)
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch c.columns[i] {
+		case "title":
+			request.Title = value
+		case "author":
+			request.Author = value
+		case "isbn":
+			request.ISBN = value
+		case "year":
+			request.Year, err = strconv.Atoi(value)
+		case "price":
+			request.Price, err = strconv.Atoi(value)
+		case "stock":
+			request.Stock, err = strconv.Atoi(value)
+		case "categoryid":
+			request.CategoryID, err = strconv.Atoi(value)
+		case "categoryids":
+			request.CategoryIDs, err = parseImportIDs(value)
+		case "authorids":
+			request.AuthorIDs, err = parseImportIDs(value)
+		}
+		if err != nil {
+			return BookRequest{}, fmt.Errorf("invalid %s: %w", c.columns[i], err)
+		}
+	}
+	return request, nil
+}
+
+// parseImportIDs parses a list of IDs such as "1;2;3".
+func parseImportIDs(value string) ([]int, error) {
+	fields := strings.Split(value, ";")
+	ids := make([]int, 0, len(fields))
+	for _, field := range fields {
+		id, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ndjsonBookImportReader reads a BookRequest JSON object per line. Blank lines are skipped.
+type ndjsonBookImportReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (n *ndjsonBookImportReader) Next() (importRow, error) {
+	for {
+		data, err := n.reader.ReadBytes('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || len(data) == 0) {
+			return importRow{}, err
+		}
+		n.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		row := importRow{Line: n.line}
+		if err := json.Unmarshal(data, &row.Request); err != nil {
+			row.Err = fmt.Errorf("invalid JSON: %w", err)
+		}
+		return row, nil
+	}
+}
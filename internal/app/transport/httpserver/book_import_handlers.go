@@ -0,0 +1,203 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+const (
+	// importBatchSize is the number of books written per transaction.
+	importBatchSize = 500
+	// maxImportSize caps the import body.
+	maxImportSize = 64 << 20
+)
+
+// @Summary ImportBooks
+// @Security ApiKeyAuth
+// @Tags book
+// @Description create or update books from CSV (with a header row, ID lists separated by semicolons)
+// @Description or NDJSON (a book per line). A book is matched by its ISBN, or by its title, author and year
+// @Description when it has none; the stock of matched books is kept, and a row asking for another stock is
+// @Description updated with a warning. Every row is validated like POST /book,
+// @Description valid rows are written in batches and the invalid ones are reported with the reason.
+// @Description A dry run checks all the rows in one transaction that is rolled back.
+// @ID import-books
+// @Accept  text/csv,application/x-ndjson
+// @Produce  json
+// @Param format query string false "csv or ndjson, taken from the Content-Type by default"
+// @Param dry_run query bool false "validate and report without writing"
+// @Success 200 {object} BookImportResponse
+// @Failure 400 {object} server.ErrorResponse "the input can't be read, details hold the rows done before"
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse "a batch failed, details hold the rows done before and the failed ones"
+// @Router /admin/books/import [post]
+func (h HTTPServer) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	dryRun, err := parseBoolParam(r.URL.Query().Get("dry_run"))
+	if err != nil {
+		server.BadRequest("invalid-dry-run", err, w, r)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	reader, err := newBookImportReader(r)
+	if err != nil {
+		if errors.Is(err, errUnsupportedImportFormat) {
+			server.UnsupportedMediaType("unsupported-import-format", err, w, r)
+			return
+		}
+		server.BadRequest("invalid-import", err, w, r)
+		return
+	}
+
+	importer := bookImporter{
+		bookService: h.bookService,
+		actorID:     user.ID,
+		dryRun:      dryRun,
+		report:      BookImportResponse{DryRun: dryRun, Rows: []BookImportRowResponse{}},
+	}
+	if err := importer.run(r.Context(), reader); err != nil {
+		var inputErr importInputError
+		if errors.As(err, &inputErr) {
+			server.BadRequestWithDetails("invalid-import", err, importer.finish(), w, r)
+			return
+		}
+		server.InternalErrorWithDetails("import-failed", err, importer.finish(), w, r)
+		return
+	}
+
+	server.RespondOK(importer.finish(), w, r)
+}
+
+// bookImporter validates the rows of an import and writes the valid ones in batches. A dry run
+// checks all the valid rows in one batch, so that the rows are checked against the ones before
+// them as they would be written.
+type bookImporter struct {
+	bookService BookService
+	actorID     int
+	dryRun      bool
+	batch       []domain.Book
+	batchLines  []int
+	report      BookImportResponse
+}
+
+// importInputError is returned when the rest of the import input can't be read.
+type importInputError struct {
+	err error
+}
+
+func (e importInputError) Error() string {
+	return e.err.Error()
+}
+
+func (e importInputError) Unwrap() error {
+	return e.err
+}
+
+// run imports all the rows. The rows read before an input error are still imported.
+func (i *bookImporter) run(ctx context.Context, reader bookImportReader) error {
+	for {
+		row, err := reader.Next()
+		if err != nil {
+			if flushErr := i.flush(ctx); flushErr != nil {
+				return flushErr
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return importInputError{err: err}
+		}
+
+		if row.Err != nil {
+			i.reject(row.Line, row.Err)
+			continue
+		}
+		if err := row.Request.Validate(); err != nil {
+			i.reject(row.Line, err)
+			continue
+		}
+		book, err := toDomainBook(row.Request)
+		if err != nil {
+			i.reject(row.Line, err)
+			continue
+		}
+
+		i.batch = append(i.batch, book)
+		i.batchLines = append(i.batchLines, row.Line)
+		if len(i.batch) == importBatchSize && !i.dryRun {
+			if err := i.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (i *bookImporter) flush(ctx context.Context) error {
+	if len(i.batch) == 0 {
+		return nil
+	}
+
+	results, err := i.bookService.ImportBooks(ctx, i.actorID, i.batch, i.dryRun)
+	if err != nil {
+		for _, line := range i.batchLines {
+			i.add(BookImportRowResponse{Line: line, Status: string(domain.BookImportFailed),
+				Error: "the batch of the row failed to be written"})
+		}
+		i.batch, i.batchLines = i.batch[:0], i.batchLines[:0]
+		return err
+	}
+	for j, result := range results {
+		row := BookImportRowResponse{
+			Line:    i.batchLines[j],
+			Status:  string(result.Action),
+			BookID:  result.BookID,
+			Warning: result.Warning,
+		}
+		if result.Err != nil {
+			row.Error = result.Err.Error()
+		}
+		i.add(row)
+	}
+	i.batch, i.batchLines = i.batch[:0], i.batchLines[:0]
+
+	return nil
+}
+
+func (i *bookImporter) reject(line int, err error) {
+	i.add(BookImportRowResponse{Line: line, Status: string(domain.BookImportRejected), Error: err.Error()})
+}
+
+func (i *bookImporter) add(row BookImportRowResponse) {
+	switch domain.BookImportAction(row.Status) {
+	case domain.BookImportCreated:
+		i.report.Created++
+	case domain.BookImportUpdated:
+		i.report.Updated++
+	case domain.BookImportRejected:
+		i.report.Rejected++
+	case domain.BookImportFailed:
+		i.report.Failed++
+	}
+	i.report.Rows = append(i.report.Rows, row)
+}
+
+// finish returns the report with the rows in input order, the rejected rows are
+// reported as they are read and the others once their batch is written.
+func (i *bookImporter) finish() BookImportResponse {
+	slices.SortStableFunc(i.report.Rows, func(a, b BookImportRowResponse) int {
+		return a.Line - b.Line
+	})
+	return i.report
+}
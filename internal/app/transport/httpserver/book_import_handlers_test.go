@@ -0,0 +1,258 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportBooks_CSV(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	body := "title,author,year,price,stock,category_ids,isbn\n" +
+		"1984,George Orwell,1949,1000,5,1;2,\n" +
+		"Animal Farm,George Orwell,1945,abc,5,1,\n" +
+		",Nobody,2000,100,1,1,\n" +
+		"Dune,Frank Herbert,1965,1500,3,1,9780441013593\n" +
+		"Emma,Jane Austen,1815,900,2,7,\n"
+
+	bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+		return len(books) == 3 && books[0].Title() == "1984" && assert.ObjectsAreEqual([]int{1, 2}, books[0].CategoryIDs()) &&
+			books[1].ISBN() == "9780441013593" && books[2].Title() == "Emma"
+	}), false).Return([]domain.BookImportResult{
+		{Action: domain.BookImportCreated, BookID: 10},
+		{Action: domain.BookImportUpdated, BookID: 3, Warning: "stock 3 is ignored, the book keeps its stock of 8"},
+		{Action: domain.BookImportRejected, Err: fmt.Errorf("%w: [7]", domain.ErrCategoryNotFound)},
+	}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/books/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+
+	httpServer.ImportBooks(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var report BookImportResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 3, report.Rejected)
+	require.Len(t, report.Rows, 5)
+	assert.Equal(t, BookImportRowResponse{Line: 2, Status: "created", BookID: 10}, report.Rows[0])
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, "rejected", report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Error, "invalid price")
+	assert.Equal(t, BookImportRowResponse{Line: 4, Status: "rejected", Error: "required value: title"}, report.Rows[2])
+	assert.Equal(t, BookImportRowResponse{Line: 5, Status: "updated", BookID: 3,
+		Warning: "stock 3 is ignored, the book keeps its stock of 8"}, report.Rows[3])
+	assert.Equal(t, BookImportRowResponse{Line: 6, Status: "rejected", Error: "category not found: [7]"}, report.Rows[4])
+}
+
+func TestImportBooks_NDJSONDryRun(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	body := `{"title": "1984", "author": "George Orwell", "year": 1949, "price": 1000, "categoryId": 1}` + "\n" +
+		"\n" +
+		`{"title": "broken"` + "\n" +
+		`{"title": "Dune", "author": "Frank Herbert", "year": 1965, "price": 1500, "categoryId": 1, "isbn": "12"}`
+
+	bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+		return len(books) == 1 && books[0].Title() == "1984"
+	}), true).Return([]domain.BookImportResult{{Action: domain.BookImportCreated}}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/books/import?format=ndjson&dry_run=true",
+		strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	httpServer.ImportBooks(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var report BookImportResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Rejected)
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, BookImportRowResponse{Line: 1, Status: "created"}, report.Rows[0])
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Contains(t, report.Rows[1].Error, "invalid JSON")
+	assert.Equal(t, 4, report.Rows[2].Line)
+	assert.Contains(t, report.Rows[2].Error, "invalid ISBN")
+}
+
+func TestImportBooks_Batches(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	var body strings.Builder
+	body.WriteString("title,author,year,price,category_id\n")
+	for i := 0; i < importBatchSize+1; i++ {
+		fmt.Fprintf(&body, "Book %d,Author,2000,100,1\n", i)
+	}
+
+	for _, size := range []int{importBatchSize, 1} {
+		results := make([]domain.BookImportResult, size)
+		for i := range results {
+			results[i] = domain.BookImportResult{Action: domain.BookImportCreated, BookID: i + 1}
+		}
+		bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+			return len(books) == size
+		}), false).Return(results, nil).Once()
+	}
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/books/import?format=csv", strings.NewReader(body.String()))
+	w := httptest.NewRecorder()
+
+	httpServer.ImportBooks(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var report BookImportResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&report))
+	assert.Equal(t, importBatchSize+1, report.Created)
+	assert.Len(t, report.Rows, importBatchSize+1)
+}
+
+func TestImportBooks_DryRunInOneBatch(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	var body strings.Builder
+	body.WriteString("title,author,year,price,category_id\n")
+	for i := 0; i < importBatchSize+1; i++ {
+		fmt.Fprintf(&body, "Book %d,Author,2000,100,1\n", i)
+	}
+
+	results := make([]domain.BookImportResult, importBatchSize+1)
+	for i := range results {
+		results[i] = domain.BookImportResult{Action: domain.BookImportCreated}
+	}
+	bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+		return len(books) == importBatchSize+1
+	}), true).Return(results, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/books/import?format=csv&dry_run=true",
+		strings.NewReader(body.String()))
+	w := httptest.NewRecorder()
+
+	httpServer.ImportBooks(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestImportBooks_BatchFails(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	var body strings.Builder
+	body.WriteString("title,author,year,price,category_id\n")
+	for i := 0; i < importBatchSize+1; i++ {
+		fmt.Fprintf(&body, "Book %d,Author,2000,100,1\n", i)
+	}
+
+	results := make([]domain.BookImportResult, importBatchSize)
+	for i := range results {
+		results[i] = domain.BookImportResult{Action: domain.BookImportCreated, BookID: i + 1}
+	}
+	bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+		return len(books) == importBatchSize
+	}), false).Return(results, nil).Once()
+	bookServiceMock.On("ImportBooks", mock.Anything, testAdmin.ID, mock.MatchedBy(func(books []domain.Book) bool {
+		return len(books) == 1
+	}), false).Return(nil, errors.New("connection reset")).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/books/import?format=csv", strings.NewReader(body.String()))
+	w := httptest.NewRecorder()
+
+	httpServer.ImportBooks(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+	var errorResponse struct {
+		Slug    string             `json:"slug"`
+		Details BookImportResponse `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+	assert.Equal(t, "import-failed", errorResponse.Slug)
+	assert.Equal(t, importBatchSize, errorResponse.Details.Created)
+	assert.Equal(t, 1, errorResponse.Details.Failed)
+	require.Len(t, errorResponse.Details.Rows, importBatchSize+1)
+	last := errorResponse.Details.Rows[importBatchSize]
+	assert.Equal(t, importBatchSize+2, last.Line)
+	assert.Equal(t, "failed", last.Status)
+}
+
+func TestImportBooks_Errors(t *testing.T) {
+	tests := map[string]struct {
+		url         string
+		contentType string
+		body        string
+		status      int
+		slug        string
+	}{
+		"unsupported format": {"/admin/books/import", "application/json", "[]", http.StatusUnsupportedMediaType,
+			"unsupported-import-format"},
+		"invalid dry run": {"/admin/books/import?dry_run=maybe", "text/csv", "title\n", http.StatusBadRequest,
+			"invalid-dry-run"},
+		"empty csv":       {"/admin/books/import", "text/csv", "", http.StatusBadRequest, "invalid-import"},
+		"no title column": {"/admin/books/import", "text/csv", "name,year\n", http.StatusBadRequest, "invalid-import"},
+		"duplicate column": {"/admin/books/import", "text/csv", "title,Title\n", http.StatusBadRequest,
+			"invalid-import"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			httpServer := NewHTTPServer(nil, nil, mocks.NewBookService(t), nil, nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			httpServer.ImportBooks(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
@@ -2,11 +2,44 @@ package httpserver
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/gorilla/mux"
 )
 
+// @Summary GetCart
+// @Security ApiKeyAuth
+// @Tags cart
+// @Description get the cart with priced items and the reservation expiry
+// @ID get-cart
+// @Produce  json
+// @Success 200 {object} CartResponse
+// @Failure 400 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /cart [get]
+func (h HTTPServer) GetCart(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	cart, err := h.cartService.GetCart(r.Context(), user.ID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart)
+
+	server.RespondOK(response, w, r)
+}
+
 // @Summary UpdateCart
 // @Security ApiKeyAuth
 // @Tags cart
@@ -33,6 +66,11 @@ func (h HTTPServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := cartRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
 	_, err = h.userService.GetUserByID(r.Context(), user.ID)
 	if err != nil {
 		server.RespondWithError(err, w, r)
@@ -56,14 +94,129 @@ func (h HTTPServer) UpdateCart(w http.ResponseWriter, r *http.Request) {
 	server.RespondOK(response, w, r)
 }
 
+// @Summary SetCartItem
+// @Security ApiKeyAuth
+// @Tags cart
+// @Description set the number of copies of a book in the cart
+// @ID set-cart-item
+// @Accept  json
+// @Produce  json
+// @Param book_id path int true "book ID"
+// @Param input body CartItemQuantityRequest true "quantity"
+// @Success 200 {object} CartResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /cart/items/{book_id} [put]
+func (h HTTPServer) SetCartItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	bookID, err := strconv.Atoi(mux.Vars(r)["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	var itemRequest CartItemQuantityRequest
+	if err := json.NewDecoder(r.Body).Decode(&itemRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	if err := itemRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	cart, err := h.cartService.SetCartItem(r.Context(), user.ID, bookID, itemRequest.Quantity)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary RemoveCartItem
+// @Security ApiKeyAuth
+// @Tags cart
+// @Description remove a book from the cart
+// @ID remove-cart-item
+// @Produce  json
+// @Param book_id path int true "book ID"
+// @Success 200 {object} CartResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /cart/items/{book_id} [delete]
+func (h HTTPServer) RemoveCartItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	bookID, err := strconv.Atoi(mux.Vars(r)["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	cart, err := h.cartService.RemoveCartItem(r.Context(), user.ID, bookID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary ClearCart
+// @Security ApiKeyAuth
+// @Tags cart
+// @Description remove all books from the cart
+// @ID clear-cart
+// @Produce  json
+// @Success 200 {object} CartResponse
+// @Failure 400 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /cart [delete]
+func (h HTTPServer) ClearCart(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	cart, err := h.cartService.ClearCart(r.Context(), user.ID)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCart(cart)
+
+	server.RespondOK(response, w, r)
+}
+
 // @Summary Checkout
 // @Security ApiKeyAuth
 // @Tags cart
-// @Description checkout
+// @Description checkout the cart and place an order
 // @ID checkout
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} map[string]bool
+// @Param input body CheckoutRequest false "payment info"
+// @Success 200 {object} OrderResponse
 // @Failure 400,404 {object} server.ErrorResponse
 // @Failure 401 {object} server.ErrorResponse
 // @Failure 500 {object} server.ErrorResponse
@@ -75,11 +228,19 @@ func (h HTTPServer) Checkout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.cartService.Checkout(r.Context(), user.ID)
+	var checkoutRequest CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&checkoutRequest); err != nil && !errors.Is(err, io.EOF) {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	order, err := h.cartService.Checkout(r.Context(), user.ID, checkoutRequest.PaymentToken)
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	server.RespondOK(map[string]bool{"ok": true}, w, r)
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
 }
@@ -7,16 +7,87 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+func TestGetCart_Success(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	book, err := domain.NewBook(domain.NewBookData{
+		ID:     1,
+		Title:  "1984",
+		Year:   1949,
+		Author: "George Orwell",
+		Price:  1500,
+	})
+	require.NoError(t, err)
+	item, err := domain.NewCartItem(domain.NewCartItemData{BookID: 1, Quantity: 2, Book: book})
+	require.NoError(t, err)
+	updatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, Items: []domain.CartItem{item}, UpdatedAt: updatedAt})
+	require.NoError(t, err)
+
+	cartServiceMock.On("GetCart", mock.Anything, 1).Return(cart.WithReservationTTL(time.Minute), nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/cart", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.GetCart(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response CartResponse
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, "1984", response.Items[0].Title)
+	assert.Equal(t, 1500, response.Items[0].Price)
+	assert.Equal(t, 3000, response.Items[0].Total)
+	assert.Equal(t, 3000, response.Subtotal)
+	require.NotNil(t, response.ExpiresAt)
+	assert.True(t, updatedAt.Add(time.Minute).Equal(*response.ExpiresAt))
+}
+
+func TestGetCart_Empty(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1})
+	require.NoError(t, err)
+	cartServiceMock.On("GetCart", mock.Anything, 1).Return(cart, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/cart", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.GetCart(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"items": [], "subtotal": 0, "bookIds": []}`, rr.Body.String())
+}
+
 func TestUpdateCart_InvalidUser(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	cartServiceMock := mocks.NewCartService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock)
+	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
 
 	reqBody := CartRequest{BookIDs: []int{1, 2}}
 	reqBodyJSON, _ := json.Marshal(reqBody)
@@ -37,7 +108,7 @@ func TestUpdateCart_InvalidJSON(t *testing.T) {
 	userServiceMock := mocks.NewUserService(t)
 	cartServiceMock := mocks.NewCartService(t)
 
-	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock)
+	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
 
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/cart",
 		bytes.NewBuffer([]byte("invalid json")))
@@ -51,3 +122,263 @@ func TestUpdateCart_InvalidJSON(t *testing.T) {
 	userServiceMock.AssertNumberOfCalls(t, "GetUserByID", 0)
 	cartServiceMock.AssertNumberOfCalls(t, "UpdateCartAndStocks", 0)
 }
+
+func TestUpdateCart_Quantities(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "items", body: `[{"bookId": 1, "quantity": 2}, {"bookId": 2, "quantity": 1}]`},
+		{name: "legacy book IDs", body: `{"bookIds": [1, 2, 1]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userServiceMock := mocks.NewUserService(t)
+			cartServiceMock := mocks.NewCartService(t)
+
+			httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+			userServiceMock.On("GetUserByID", mock.Anything, 1).Return(domain.User{ID: 1}, nil)
+			cartServiceMock.On("UpdateCartAndStocks", mock.Anything, mock.Anything).
+				Return(func(_ context.Context, cart domain.Cart) (domain.Cart, error) {
+					assert.Equal(t, 2, cart.Quantity(1))
+					assert.Equal(t, 1, cart.Quantity(2))
+					return cart, nil
+				})
+
+			ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/cart", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+
+			httpServer.UpdateCart(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var response CartResponse
+			err = json.NewDecoder(rr.Body).Decode(&response)
+			require.NoError(t, err)
+
+			assert.Equal(t, []CartItemResponse{{BookID: 1, Quantity: 2}, {BookID: 2, Quantity: 1}}, response.Items)
+			assert.ElementsMatch(t, []int{1, 1, 2}, response.BookIDs)
+		})
+	}
+}
+
+func TestUpdateCart_InvalidQuantity(t *testing.T) {
+	userServiceMock := mocks.NewUserService(t)
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(userServiceMock, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/cart",
+		bytes.NewBufferString(`[{"bookId": 1, "quantity": 0}]`))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.UpdateCart(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid-request")
+	cartServiceMock.AssertNumberOfCalls(t, "UpdateCartAndStocks", 0)
+}
+
+func TestCheckout_Success(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	item, err := domain.NewOrderItem(domain.NewOrderItemData{
+		BookID:   1,
+		Title:    "The history of Golang",
+		Author:   "Rob Pike",
+		Price:    1000,
+		Quantity: 1,
+	})
+	require.NoError(t, err)
+	order, err := domain.NewOrder(domain.NewOrderData{
+		ID:     1,
+		UserID: 1,
+		Status: domain.OrderStatusPaid,
+		Items:  []domain.OrderItem{item},
+	})
+	require.NoError(t, err)
+
+	cartServiceMock.On("Checkout", mock.Anything, 1, "").Return(order, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/checkout", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.Checkout(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response OrderResponse
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, response.ID)
+	assert.Equal(t, "paid", response.Status)
+	assert.Equal(t, 1000, response.Total)
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, "The history of Golang", response.Items[0].Title)
+}
+
+func TestCheckout_EmptyCart(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	cartServiceMock.On("Checkout", mock.Anything, 1, "").
+		Return(domain.Order{}, slugerrors.NewBadRequestError("cart is empty", "empty-cart"))
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/checkout", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.Checkout(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "empty-cart")
+}
+
+func TestCheckout_PassesPaymentToken(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	cartServiceMock.On("Checkout", mock.Anything, 1, "tok_decline").
+		Return(domain.Order{}, slugerrors.NewBadRequestError("payment declined", "payment-declined"))
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/checkout",
+		bytes.NewBufferString(`{"paymentToken": "tok_decline"}`))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.Checkout(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "payment-declined")
+}
+
+func TestSetCartItem_Success(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	item, err := domain.NewCartItem(domain.NewCartItemData{BookID: 7, Quantity: 3})
+	require.NoError(t, err)
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, Items: []domain.CartItem{item}})
+	require.NoError(t, err)
+	cartServiceMock.On("SetCartItem", mock.Anything, 1, 7, 3).Return(cart, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "/cart/items/7",
+		bytes.NewBufferString(`{"quantity": 3}`))
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"book_id": "7"})
+
+	rr := httptest.NewRecorder()
+
+	httpServer.SetCartItem(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response CartResponse
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, 7, response.Items[0].BookID)
+	assert.Equal(t, 3, response.Items[0].Quantity)
+}
+
+func TestSetCartItem_InvalidRequest(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		bookID string
+		body   string
+		slug   string
+	}{
+		{name: "invalid book ID", bookID: "invalid", body: `{"quantity": 1}`, slug: "invalid-book-id"},
+		{name: "invalid json", bookID: "7", body: `invalid json`, slug: "invalid-json"},
+		{name: "zero quantity", bookID: "7", body: `{"quantity": 0}`, slug: "invalid-request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, "/cart/items/"+tt.bookID,
+				bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"book_id": tt.bookID})
+
+			rr := httptest.NewRecorder()
+
+			httpServer.SetCartItem(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.slug)
+		})
+	}
+
+	cartServiceMock.AssertNumberOfCalls(t, "SetCartItem", 0)
+}
+
+func TestRemoveCartItem_Success(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, BookIDs: []int{2}})
+	require.NoError(t, err)
+	cartServiceMock.On("RemoveCartItem", mock.Anything, 1, 7).Return(cart, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "/cart/items/7", http.NoBody)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"book_id": "7"})
+
+	rr := httptest.NewRecorder()
+
+	httpServer.RemoveCartItem(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"bookIds":[2]`)
+}
+
+func TestClearCart_Success(t *testing.T) {
+	cartServiceMock := mocks.NewCartService(t)
+
+	httpServer := NewHTTPServer(nil, nil, nil, nil, cartServiceMock, nil, nil, nil, nil)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1})
+	require.NoError(t, err)
+	cartServiceMock.On("ClearCart", mock.Anything, 1).Return(cart, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "/cart", http.NoBody)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.ClearCart(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"items": [], "subtotal": 0, "bookIds": []}`, rr.Body.String())
+}
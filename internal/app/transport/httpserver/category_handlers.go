@@ -59,6 +59,12 @@ func (h HTTPServer) GetCategory(w http.ResponseWriter, r *http.Request) {
 // @Router /category [post]
 // CreateCategory creates a new category
 func (h HTTPServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	var categoryRequest CategoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&categoryRequest); err != nil {
 		server.BadRequest("invalid-json", err, w, r)
@@ -71,15 +77,20 @@ func (h HTTPServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	category, err := domain.NewCategory(domain.NewCategoryData{
-		Name: categoryRequest.Name,
+		Name:     categoryRequest.Name,
+		ParentID: categoryRequest.ParentID,
 	})
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	insertedCategory, err := h.categoryService.CreateCategory(r.Context(), category)
+	insertedCategory, err := h.categoryService.CreateCategory(r.Context(), user.ID, category)
 	if err != nil {
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.NotFound("parent-category-not-found", err, w, r)
+			return
+		}
 		server.RespondWithError(err, w, r)
 		return
 	}
@@ -92,7 +103,7 @@ func (h HTTPServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
 // @Summary UpdateCategory
 // @Security ApiKeyAuth
 // @Tags category
-// @Description update category by ID
+// @Description update category by ID, a category can't be moved under one of its subcategories
 // @ID update-category
 // @Accept  json
 // @Produce  json
@@ -101,9 +112,16 @@ func (h HTTPServer) CreateCategory(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} CategoryResponse
 // @Failure 400,404 {object} server.ErrorResponse
 // @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
 // @Failure 500 {object} server.ErrorResponse
 // @Router /category/{category_id} [patch]
 func (h HTTPServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	vars := mux.Vars(r)
 	categoryID, err := strconv.Atoi(vars["category_id"])
 	if err != nil {
@@ -133,16 +151,33 @@ func (h HTTPServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	category, err := domain.NewCategory(domain.NewCategoryData{
-		ID:   categoryID,
-		Name: categoryRequest.Name,
+		ID:       categoryID,
+		Name:     categoryRequest.Name,
+		ParentID: categoryRequest.ParentID,
 	})
 	if err != nil {
+		if errors.Is(err, domain.ErrCategoryCycle) {
+			server.Conflict("category-cycle", err, w, r)
+			return
+		}
 		server.RespondWithError(err, w, r)
 		return
 	}
 
-	updatedCategory, err := h.categoryService.UpdateCategory(r.Context(), category)
+	updatedCategory, err := h.categoryService.UpdateCategory(r.Context(), user.ID, category)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("category-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryCycle) {
+			server.Conflict("category-cycle", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.NotFound("parent-category-not-found", err, w, r)
+			return
+		}
 		server.RespondWithError(err, w, r)
 		return
 	}
@@ -155,17 +190,26 @@ func (h HTTPServer) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 // @Summary DeleteCategory
 // @Security ApiKeyAuth
 // @Tags category
-// @Description delete category by ID
+// @Description delete category by ID, a category with books can only be deleted by reassigning them.
+// @Description Its subcategories are moved up to its parent. It can be restored until it is purged.
 // @ID delete-category
 // @Accept  json
 // @Produce  json
 // @Param category_id path int true "category ID"
+// @Param reassign_to query int false "category ID to move the books to before deleting"
 // @Success 200 {object} map[string]bool
 // @Failure 400,404 {object} server.ErrorResponse
 // @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse{details=CategoryNotEmptyResponse}
 // @Failure 500 {object} server.ErrorResponse
 // @Router /category/{category_id} [delete]
 func (h HTTPServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
 	vars := mux.Vars(r)
 	categoryID, err := strconv.Atoi(vars["category_id"])
 	if err != nil {
@@ -173,6 +217,15 @@ func (h HTTPServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var reassignTo int
+	if value := r.URL.Query().Get("reassign_to"); value != "" {
+		reassignTo, err = strconv.Atoi(value)
+		if err != nil || reassignTo <= 0 || reassignTo == categoryID {
+			server.BadRequest("invalid-reassign-to", err, w, r)
+			return
+		}
+	}
+
 	_, err = h.categoryService.GetCategory(r.Context(), categoryID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
@@ -183,8 +236,17 @@ func (h HTTPServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.categoryService.DeleteCategory(r.Context(), categoryID)
+	err = h.categoryService.DeleteCategory(r.Context(), user.ID, categoryID, reassignTo)
 	if err != nil {
+		var notEmpty domain.CategoryNotEmptyError
+		if errors.As(err, &notEmpty) {
+			server.ConflictWithDetails("category-not-empty", err, CategoryNotEmptyResponse{Books: notEmpty.Books}, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.NotFound("reassign-category-not-found", err, w, r)
+			return
+		}
 		if errors.Is(err, domain.ErrNotFound) {
 			server.NotFound("category-not-found", err, w, r)
 			return
@@ -196,18 +258,93 @@ func (h HTTPServer) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	server.RespondOK(map[string]bool{"deleted": true}, w, r)
 }
 
+// @Summary RestoreCategory
+// @Security ApiKeyAuth
+// @Tags category
+// @Description restore a deleted category by ID, its former subcategories stay where they were moved
+// @ID restore-category
+// @Accept  json
+// @Produce  json
+// @Param category_id path int true "category ID"
+// @Success 200 {object} CategoryResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /category/{category_id}/restore [post]
+func (h HTTPServer) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	categoryID, err := strconv.Atoi(vars["category_id"])
+	if err != nil {
+		server.BadRequest("invalid-category-id", err, w, r)
+		return
+	}
+
+	category, err := h.categoryService.RestoreCategory(r.Context(), user.ID, categoryID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("deleted-category-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrCategoryNotFound) {
+			server.Conflict("parent-category-deleted", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCategory(category)
+
+	server.RespondOK(response, w, r)
+}
+
 // @Summary GetCategories
 // @Tags category
 // @Description get all categories
 // @ID get-categories
 // @Accept  json
 // @Produce  json
+// @Param with_stats query bool false "add the number of books in stock, their price range and the newest one"
+// @Param deleted query bool false "list the deleted categories instead, the last deleted first; for admins only"
+// @Param envelope query bool false "wrap the categories in a paginated envelope"
 // @Success 200 {array} CategoryResponse
 // @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
 // @Failure 500 {object} server.ErrorResponse
 // @Router /categories [get]
 func (h HTTPServer) GetCategories(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.categoryService.GetCategories(r.Context())
+	withStats, err := parseBoolParam(r.URL.Query().Get("with_stats"))
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	deleted, err := parseBoolParam(r.URL.Query().Get("deleted"))
+	if err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+	if deleted && withStats {
+		server.BadRequest("invalid-request", errors.New("with_stats can't be combined with deleted"), w, r)
+		return
+	}
+	if deleted && !h.isAdmin(r) {
+		server.Unauthorised("not-admin", nil, w, r)
+		return
+	}
+
+	var categories []domain.Category
+	if deleted {
+		categories, err = h.categoryService.GetDeletedCategories(r.Context())
+	} else {
+		categories, err = h.categoryService.GetCategories(r.Context(), withStats)
+	}
 	if err != nil {
 		server.RespondWithError(err, w, r)
 		return
@@ -218,5 +355,31 @@ func (h HTTPServer) GetCategories(w http.ResponseWriter, r *http.Request) {
 		response = append(response, toResponseCategory(category))
 	}
 
+	if server.WantsPage(r) {
+		// categories are not paginated, they all fit on the first page
+		server.RespondPage(server.NewNumberedPage(r, response, len(response), 1, len(response)), w, r)
+		return
+	}
+	server.RespondOK(response, w, r)
+}
+
+// @Summary GetCategoryTree
+// @Tags category
+// @Description get all categories nested under their parents
+// @ID get-category-tree
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} CategoryTreeResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /categories/tree [get]
+func (h HTTPServer) GetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.GetCategories(r.Context(), false)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseCategoryTree(domain.NewCategoryTree(categories))
+
 	server.RespondOK(response, w, r)
 }
@@ -3,12 +3,16 @@ package httpserver
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -22,9 +26,10 @@ func TestGetCategory_Success(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	categoryServiceMock.On("CreateCategory", mock.Anything, mock.Anything).Return(testCreatedCategory, nil).Once()
+	categoryServiceMock.On("CreateCategory", mock.Anything, testAdmin.ID, mock.Anything).
+		Return(testCreatedCategory, nil).Once()
 
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	newCategoryRequest := []byte(`{
 		  "name": "Fiction"
@@ -34,7 +39,7 @@ func TestGetCategory_Success(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/category", bytes.NewBuffer(newCategoryRequest))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateCategory(w, req)
+	httpServer.CreateCategory(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -52,7 +57,7 @@ func TestGetCategory_Success(t *testing.T) {
 
 func TestGetCategory_InvalidID(t *testing.T) {
 	categoryServiceMock := mocks.NewCategoryService(t)
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/category/invalid", nil)
 	w := httptest.NewRecorder()
@@ -67,12 +72,12 @@ func TestGetCategory_InvalidID(t *testing.T) {
 
 func TestCreateCategory_InvalidJSON(t *testing.T) {
 	categoryServiceMock := mocks.NewCategoryService(t)
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/category", bytes.NewBuffer([]byte("invalid json")))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateCategory(w, req)
+	httpServer.CreateCategory(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -82,7 +87,7 @@ func TestCreateCategory_InvalidJSON(t *testing.T) {
 
 func TestCreateCategory_InvalidRequest(t *testing.T) {
 	categoryServiceMock := mocks.NewCategoryService(t)
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	invalidCategoryRequest := []byte(`{
 		"name": ""
@@ -91,7 +96,7 @@ func TestCreateCategory_InvalidRequest(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/category", bytes.NewBuffer(invalidCategoryRequest))
 	w := httptest.NewRecorder()
 
-	httpServer.CreateCategory(w, req)
+	httpServer.CreateCategory(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -101,12 +106,12 @@ func TestCreateCategory_InvalidRequest(t *testing.T) {
 
 func TestUpdateCategory_ReturnsBadRequestForInvalidID(t *testing.T) {
 	categoryServiceMock := mocks.NewCategoryService(t)
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodPatch, "/category/invalid", nil)
 	w := httptest.NewRecorder()
 
-	httpServer.UpdateCategory(w, req)
+	httpServer.UpdateCategory(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -116,15 +121,354 @@ func TestUpdateCategory_ReturnsBadRequestForInvalidID(t *testing.T) {
 
 func TestDeleteCategory_ReturnsBadRequestForInvalidID(t *testing.T) {
 	categoryServiceMock := mocks.NewCategoryService(t)
-	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil)
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/category/invalid", nil)
 	w := httptest.NewRecorder()
 
-	httpServer.DeleteCategory(w, req)
+	httpServer.DeleteCategory(w, withAdmin(req))
 
 	res := w.Result()
 	defer res.Body.Close()
 
 	require.Equal(t, http.StatusBadRequest, res.StatusCode)
 }
+
+func TestGetCategories_Envelope(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+
+	category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction"})
+	require.NoError(t, err)
+	categoryServiceMock.On("GetCategories", mock.Anything, false).Return([]domain.Category{category}, nil)
+
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+	req.Header.Set("Accept", `application/json; profile="urn:bookshop:paginated"`)
+	w := httptest.NewRecorder()
+
+	httpServer.GetCategories(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Link"))
+
+	var page server.Page[CategoryResponse]
+	err = json.NewDecoder(res.Body).Decode(&page)
+	require.NoError(t, err)
+
+	assert.Equal(t, []CategoryResponse{{ID: 1, Name: "Fiction"}}, page.Items)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 1, page.Page)
+	assert.Equal(t, 1, page.PageSize)
+	assert.Empty(t, page.Next)
+	assert.Empty(t, page.Prev)
+}
+
+func TestDeleteCategory_NotEmpty(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+	category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction"})
+	require.NoError(t, err)
+
+	categoryServiceMock.On("GetCategory", mock.Anything, 1).Return(category, nil).Once()
+	categoryServiceMock.On("DeleteCategory", mock.Anything, testAdmin.ID, 1, 0).
+		Return(domain.CategoryNotEmptyError{CategoryID: 1, Books: 3}).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/category/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"category_id": "1"})
+	w := httptest.NewRecorder()
+
+	httpServer.DeleteCategory(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	var errorResponse struct {
+		Slug    string                   `json:"slug"`
+		Details CategoryNotEmptyResponse `json:"details"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&errorResponse)
+	require.NoError(t, err)
+	assert.Equal(t, "category-not-empty", errorResponse.Slug)
+	assert.Equal(t, 3, errorResponse.Details.Books)
+}
+
+func TestDeleteCategory_ReassignTo(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+	category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction"})
+	require.NoError(t, err)
+
+	categoryServiceMock.On("GetCategory", mock.Anything, 1).Return(category, nil).Once()
+	categoryServiceMock.On("DeleteCategory", mock.Anything, testAdmin.ID, 1, 2).Return(nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/category/1?reassign_to=2", nil)
+	req = mux.SetURLVars(req, map[string]string{"category_id": "1"})
+	w := httptest.NewRecorder()
+
+	httpServer.DeleteCategory(w, withAdmin(req))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestDeleteCategory_ReturnsBadRequestForInvalidReassignTo(t *testing.T) {
+	for _, reassignTo := range []string{"fiction", "0", "1"} {
+		t.Run(reassignTo, func(t *testing.T) {
+			httpServer := NewHTTPServer(nil, nil, nil, mocks.NewCategoryService(t), nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodDelete, "/category/1?reassign_to="+reassignTo, nil)
+			req = mux.SetURLVars(req, map[string]string{"category_id": "1"})
+			w := httptest.NewRecorder()
+
+			httpServer.DeleteCategory(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err := json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, "invalid-reassign-to", errorResponse.Slug)
+		})
+	}
+}
+
+func TestGetCategories_WithStats(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+	fiction, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction", Stats: &domain.CategoryStats{
+		InStockBooks: 2, MinPrice: 500, MaxPrice: 1500, NewestBook: &domain.CategoryBook{ID: 7, Title: "Dune"},
+	}})
+	require.NoError(t, err)
+	poetry, err := domain.NewCategory(domain.NewCategoryData{ID: 2, Name: "Poetry", Stats: &domain.CategoryStats{}})
+	require.NoError(t, err)
+
+	categoryServiceMock.On("GetCategories", mock.Anything, true).
+		Return([]domain.Category{fiction, poetry}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories?with_stats=true", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetCategories(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "name": "Fiction", "stats": {"inStockBooks": 2, "minPrice": 500, "maxPrice": 1500,
+			"newestBook": {"id": 7, "title": "Dune"}}},
+		{"id": 2, "name": "Poetry", "stats": {"inStockBooks": 0, "minPrice": null, "maxPrice": null,
+			"newestBook": null}}
+	]`, string(body))
+}
+
+func TestGetCategories_ReturnsBadRequestForInvalidWithStats(t *testing.T) {
+	httpServer := NewHTTPServer(nil, nil, nil, mocks.NewCategoryService(t), nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories?with_stats=maybe", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetCategories(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestGetCategoryTree(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+	categories := make([]domain.Category, 0, 3)
+	for _, data := range []domain.NewCategoryData{
+		{ID: 1, Name: "Fiction"},
+		{ID: 2, Name: "Fantasy", ParentID: 1},
+		{ID: 3, Name: "Epic Fantasy", ParentID: 2},
+	} {
+		category, err := domain.NewCategory(data)
+		require.NoError(t, err)
+		categories = append(categories, category)
+	}
+	categoryServiceMock.On("GetCategories", mock.Anything, false).Return(categories, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories/tree", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetCategoryTree(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"id": 1, "name": "Fiction", "children": [
+		{"id": 2, "name": "Fantasy", "children": [{"id": 3, "name": "Epic Fantasy", "children": []}]}
+	]}]`, string(body))
+}
+
+func TestUpdateCategory_Cycle(t *testing.T) {
+	tests := map[string]struct {
+		parentID string
+		err      error
+	}{
+		"own parent":       {"1", nil},
+		"under descendant": {"3", domain.ErrCategoryCycle},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			categoryServiceMock := mocks.NewCategoryService(t)
+			category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction"})
+			require.NoError(t, err)
+			categoryServiceMock.On("GetCategory", mock.Anything, 1).Return(category, nil).Once()
+			if tt.err != nil {
+				categoryServiceMock.On("UpdateCategory", mock.Anything, testAdmin.ID, mock.Anything).
+					Return(domain.Category{}, tt.err).Once()
+			}
+
+			httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+			body := `{"name": "Fiction", "parentId": ` + tt.parentID + `}`
+			req := httptest.NewRequest(http.MethodPatch, "/category/1", bytes.NewBufferString(body))
+			req = mux.SetURLVars(req, map[string]string{"category_id": "1"})
+			w := httptest.NewRecorder()
+
+			httpServer.UpdateCategory(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusConflict, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			err = json.NewDecoder(res.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			require.Equal(t, "category-cycle", errorResponse.Slug)
+		})
+	}
+}
+
+func TestGetCategories_Deleted(t *testing.T) {
+	categoryServiceMock := mocks.NewCategoryService(t)
+	tokenServiceMock := mocks.NewTokenService(t)
+
+	tokenServiceMock.On("GetUser", "user-token").Return(domain.User{Username: "user"}, nil)
+	tokenServiceMock.On("GetUser", "admin-token").Return(domain.User{Username: "admin", Admin: true}, nil)
+
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction", DeletedAt: deletedAt})
+	require.NoError(t, err)
+	categoryServiceMock.On("GetDeletedCategories", mock.Anything).Return([]domain.Category{category}, nil).Once()
+
+	httpServer := NewHTTPServer(nil, tokenServiceMock, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+	for _, token := range []string{"", "user-token", "admin-token"} {
+		t.Run("token "+token, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/categories?deleted=true", nil)
+			if token != "" {
+				req.Header.Set(AuthorizationHeader, BearerPrefix+token)
+			}
+			w := httptest.NewRecorder()
+
+			httpServer.GetCategories(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			if token != "admin-token" {
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode, token)
+				return
+			}
+			require.Equal(t, http.StatusOK, res.StatusCode)
+
+			var response []CategoryResponse
+			err := json.NewDecoder(res.Body).Decode(&response)
+			require.NoError(t, err)
+			require.Len(t, response, 1)
+			require.NotNil(t, response[0].DeletedAt)
+			assert.True(t, deletedAt.Equal(*response[0].DeletedAt))
+		})
+	}
+}
+
+func TestGetCategories_ReturnsBadRequestForDeletedWithStats(t *testing.T) {
+	httpServer := NewHTTPServer(nil, nil, nil, mocks.NewCategoryService(t), nil, nil, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories?deleted=true&with_stats=true", nil)
+	w := httptest.NewRecorder()
+
+	httpServer.GetCategories(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestRestoreCategory(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		status int
+		slug   string
+	}{
+		"restored":       {nil, http.StatusOK, ""},
+		"not deleted":    {domain.ErrNotFound, http.StatusBadRequest, "deleted-category-not-found"},
+		"parent deleted": {domain.ErrCategoryNotFound, http.StatusConflict, "parent-category-deleted"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			categoryServiceMock := mocks.NewCategoryService(t)
+			category, err := domain.NewCategory(domain.NewCategoryData{ID: 1, Name: "Fiction"})
+			require.NoError(t, err)
+			if tt.err != nil {
+				category = domain.Category{}
+			}
+			categoryServiceMock.On("RestoreCategory", mock.Anything, testAdmin.ID, 1).Return(category, tt.err).Once()
+
+			httpServer := NewHTTPServer(nil, nil, nil, categoryServiceMock, nil, nil, nil, nil, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/category/1/restore", nil)
+			req = mux.SetURLVars(req, map[string]string{"category_id": "1"})
+			w := httptest.NewRecorder()
+
+			httpServer.RestoreCategory(w, withAdmin(req))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var response struct {
+				ID   int    `json:"id"`
+				Slug string `json:"slug"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.slug, response.Slug)
+			if tt.err == nil {
+				assert.Equal(t, 1, response.ID)
+			}
+		})
+	}
+}
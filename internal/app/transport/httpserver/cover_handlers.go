@@ -0,0 +1,158 @@
+package httpserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxCoverSize is the largest accepted cover file.
+	maxCoverSize = 5 << 20
+	// coverFormField is the multipart field holding the cover file.
+	coverFormField = "cover"
+	// coverCacheControl lets clients and proxies keep covers forever, their keys change with the content.
+	coverCacheControl = "public, max-age=31536000, immutable"
+)
+
+// coverContentTypes are the accepted cover types, as sniffed by http.DetectContentType.
+var coverContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// @Summary UploadBookCover
+// @Security ApiKeyAuth
+// @Tags book
+// @Description upload a JPEG, PNG or GIF cover of at most 5 MB, small and medium JPEG thumbnails are made from it
+// @ID upload-book-cover
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param book_id path int true "book ID"
+// @Param cover formData file true "cover image"
+// @Success 200 {object} BookResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 413 {object} server.ErrorResponse
+// @Failure 415 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /book/{book_id}/cover [post]
+func (h HTTPServer) UploadBookCover(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		server.BadRequest("invalid-book-id", err, w, r)
+		return
+	}
+
+	data, err := readCoverUpload(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			server.RequestTooLarge("cover-too-large", err, w, r)
+			return
+		}
+		server.BadRequest("invalid-cover", err, w, r)
+		return
+	}
+	if contentType := http.DetectContentType(data); !coverContentTypes[contentType] {
+		server.UnsupportedMediaType("invalid-cover-type", fmt.Errorf("unsupported cover type %s", contentType), w, r)
+		return
+	}
+
+	book, err := h.bookService.UploadCover(r.Context(), user.ID, bookID, data)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("book-not-found", err, w, r)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidCover) {
+			server.BadRequest("invalid-cover", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseBook(book)
+
+	server.RespondOK(response, w, r)
+}
+
+// readCoverUpload reads the cover file of a multipart request. The whole body is capped,
+// so an oversized upload fails with a *http.MaxBytesError.
+func readCoverUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	// leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxCoverSize+1<<10)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: %s file", domain.ErrRequired, coverFormField)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() != coverFormField {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, maxCoverSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxCoverSize {
+			return nil, &http.MaxBytesError{Limit: maxCoverSize}
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("%w: %s file", domain.ErrRequired, coverFormField)
+		}
+		return data, nil
+	}
+}
+
+// @Summary GetCover
+// @Tags book
+// @Description get a book cover or thumbnail by the key in the cover URLs of a book
+// @ID get-cover
+// @Produce  image/jpeg,image/png,image/gif
+// @Param key path string true "cover key"
+// @Success 200 {file} file
+// @Success 304
+// @Failure 400 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /covers/{key} [get]
+func (h HTTPServer) GetCover(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	blob, err := h.bookService.GetCover(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("cover-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", blob.ContentType)
+	w.Header().Set("Cache-Control", coverCacheControl)
+	w.Header().Set("ETag", strconv.Quote(key))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, key, blob.ModTime, bytes.NewReader(blob.Data))
+}
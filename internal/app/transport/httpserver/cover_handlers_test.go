@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 6))))
+	return buf.Bytes()
+}
+
+func newCoverRequest(t *testing.T, field string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, "cover.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/book/1/cover", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return mux.SetURLVars(req, map[string]string{"book_id": "1"})
+}
+
+func TestUploadBookCover_Success(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	cover := testPNG(t)
+	book, err := domain.NewBook(domain.NewBookData{
+		ID: 1, Title: "1984", Year: 1949, Author: "George Orwell", Price: 1000, Stock: 5, CategoryID: 1,
+		CoverKey: "book-1-0123456789abcdef.png",
+	})
+	require.NoError(t, err)
+	bookServiceMock.On("UploadCover", mock.Anything, testAdmin.ID, 1, cover).Return(book, nil).Once()
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	httpServer.UploadBookCover(w, withAdmin(newCoverRequest(t, "cover", cover)))
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var bookResponse BookResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&bookResponse))
+	assert.Equal(t, &BookCoverResponse{
+		Original: "/covers/book-1-0123456789abcdef.png",
+		Medium:   "/covers/book-1-0123456789abcdef-medium.jpg",
+		Small:    "/covers/book-1-0123456789abcdef-small.jpg",
+	}, bookResponse.Cover)
+}
+
+func TestUploadBookCover_Errors(t *testing.T) {
+	tests := map[string]struct {
+		field  string
+		data   []byte
+		err    error
+		status int
+		slug   string
+	}{
+		"missing file":   {"image", testPNG(t), nil, http.StatusBadRequest, "invalid-cover"},
+		"not an image":   {"cover", []byte("just some text"), nil, http.StatusUnsupportedMediaType, "invalid-cover-type"},
+		"too large":      {"cover", make([]byte, maxCoverSize+1), nil, http.StatusRequestEntityTooLarge, "cover-too-large"},
+		"book not found": {"cover", testPNG(t), domain.ErrNotFound, http.StatusBadRequest, "book-not-found"},
+		"corrupt image":  {"cover", testPNG(t), domain.ErrInvalidCover, http.StatusBadRequest, "invalid-cover"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			bookServiceMock := mocks.NewBookService(t)
+			if tt.err != nil {
+				bookServiceMock.On("UploadCover", mock.Anything, testAdmin.ID, 1, tt.data).Return(domain.Book{}, tt.err).Once()
+			}
+
+			httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+			w := httptest.NewRecorder()
+			httpServer.UploadBookCover(w, withAdmin(newCoverRequest(t, tt.field, tt.data)))
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			require.Equal(t, tt.status, res.StatusCode)
+
+			var errorResponse server.ErrorResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+			assert.Equal(t, tt.slug, errorResponse.Slug)
+		})
+	}
+}
+
+func TestGetCover(t *testing.T) {
+	bookServiceMock := mocks.NewBookService(t)
+
+	cover := testPNG(t)
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bookServiceMock.On("GetCover", mock.Anything, "book-1-0123456789abcdef.png").Return(domain.Blob{
+		Data: cover, ContentType: "image/png", ModTime: modTime,
+	}, nil)
+	bookServiceMock.On("GetCover", mock.Anything, "missing.png").Return(domain.Blob{}, domain.ErrNotFound)
+
+	httpServer := NewHTTPServer(nil, nil, bookServiceMock, nil, nil, nil, nil, nil, nil)
+
+	get := func(key string, header http.Header) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/covers/"+key, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		httpServer.GetCover(w, mux.SetURLVars(req, map[string]string{"key": key}))
+		return w.Result()
+	}
+
+	res := get("book-1-0123456789abcdef.png", nil)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "image/png", res.Header.Get("Content-Type"))
+	assert.Equal(t, coverCacheControl, res.Header.Get("Cache-Control"))
+	assert.Equal(t, `"book-1-0123456789abcdef.png"`, res.Header.Get("ETag"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, cover, body)
+
+	// A cached cover is revalidated by its ETag
+	res = get("book-1-0123456789abcdef.png", http.Header{"If-None-Match": {`"book-1-0123456789abcdef.png"`}})
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	res = get("missing.png", nil)
+	defer res.Body.Close()
+	var errorResponse server.ErrorResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&errorResponse))
+	assert.Equal(t, "cover-not-found", errorResponse.Slug)
+}
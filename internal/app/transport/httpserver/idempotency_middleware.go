@@ -0,0 +1,163 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotentResponseContent = "application/json; charset=utf-8"
+)
+
+// responseUnavailable is replayed for a key whose request completed but whose response could not be stored.
+var responseUnavailable, _ = json.Marshal(server.ErrorResponse{Slug: "idempotent-response-unavailable"})
+
+// Idempotent makes the handler safe to retry with the same Idempotency-Key header.
+// The first response for a key is stored and replayed for later requests with the same body.
+// It must be wrapped by CheckAuthorizedUser, since keys are scoped to the user.
+func (h HTTPServer) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			server.BadRequest("invalid-idempotency-key", nil, w, r)
+			return
+		}
+
+		user, err := getUserFromContext(r.Context())
+		if err != nil {
+			server.BadRequest("invalid-user", err, w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			server.BadRequest("invalid-body", err, w, r)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		idempotencyKey, replay, err := h.idempotencyService.Begin(r.Context(), user.ID, key, requestFingerprint(r, body))
+		if err != nil {
+			server.RespondWithError(err, w, r)
+			return
+		}
+		if replay {
+			w.Header().Set("Content-Type", idempotentResponseContent)
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(idempotencyKey.ResponseStatus())
+			_, _ = w.Write(idempotencyKey.ResponseBody())
+			return
+		}
+
+		// the key is completed or released even if the client goes away, or the handler panics
+		ctx := context.WithoutCancel(r.Context())
+		defer func() {
+			if p := recover(); p != nil {
+				if err := h.idempotencyService.Release(ctx, idempotencyKey); err != nil {
+					log.Printf("failed to release idempotency key: %v", err)
+				}
+				panic(p)
+			}
+		}()
+
+		// a slow request keeps its key, so that a retry doesn't run it a second time
+		renewCtx, stopRenewing := context.WithCancel(ctx)
+		defer stopRenewing()
+		go h.renewIdempotencyKey(renewCtx, idempotencyKey)
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		stopRenewing()
+
+		// server errors are not stored, so that the request can be retried
+		if rec.status >= http.StatusInternalServerError {
+			if err := h.idempotencyService.Release(ctx, idempotencyKey); err != nil {
+				log.Printf("failed to release idempotency key: %v", err)
+			}
+			return
+		}
+		err = h.idempotencyService.Complete(ctx, idempotencyKey, rec.status, rec.body.Bytes())
+		if err != nil && !errors.Is(err, domain.ErrIdempotencyKeyNotHeld) {
+			log.Printf("failed to store idempotent response: %v", err)
+			// the response was sent, so a retry must not run the request again once the lease runs out
+			err = h.idempotencyService.Complete(ctx, idempotencyKey, http.StatusConflict, responseUnavailable)
+		}
+		if err != nil {
+			log.Printf("failed to complete idempotency key: %v", err)
+		}
+	}
+}
+
+// renewIdempotencyKey renews the lease of the key each time half of it is left, until ctx is done
+// or the key is lost.
+func (h HTTPServer) renewIdempotencyKey(ctx context.Context, key domain.IdempotencyKey) {
+	for {
+		wait := time.Until(key.LockedUntil()) / 2
+		if wait <= 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		renewed, err := h.idempotencyService.Renew(ctx, key)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("failed to renew idempotency key: %v", err)
+			if errors.Is(err, domain.ErrIdempotencyKeyNotHeld) {
+				return
+			}
+			// retry on what is left of the lease
+			continue
+		}
+		key = renewed
+	}
+}
+
+// requestFingerprint identifies the request a key was used with, by its method, URL and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.RequestURI())
+	_, _ = hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
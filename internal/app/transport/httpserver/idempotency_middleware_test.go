@@ -0,0 +1,232 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotentRequest(t *testing.T, key, body string) *http.Request {
+	t.Helper()
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/checkout", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	return req
+}
+
+func newTestIdempotencyKey(t *testing.T) domain.IdempotencyKey {
+	t.Helper()
+
+	key, err := domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{Key: "key", UserID: 1, Fingerprint: "hash"})
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestIdempotent_NoKey(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	called := false
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "", `{}`))
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	idempotencyServiceMock.AssertNumberOfCalls(t, "Begin", 0)
+}
+
+func TestIdempotent_FirstRequestStoresResponse(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	idempotencyServiceMock.EXPECT().Complete(mock.Anything, key, http.StatusOK, []byte(`{"id":1}`)).Return(nil)
+
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	})
+
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "key", `{}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"id":1}`, rr.Body.String())
+	assert.Empty(t, rr.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotent_Replay(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t).WithResponse(http.StatusOK, []byte(`{"id":1}`))
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, true, nil)
+
+	called := false
+	handler := httpServer.Idempotent(func(_ http.ResponseWriter, _ *http.Request) {
+		called = true
+	})
+
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "key", `{}`))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"id":1}`, rr.Body.String())
+	assert.Equal(t, "true", rr.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotent_KeyReused(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(domain.IdempotencyKey{},
+		false, slugerrors.NewUnprocessableError("reused", "idempotency-key-reused"))
+
+	handler := httpServer.Idempotent(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Fatal("handler must not be called")
+	})
+
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "key", `{"paymentToken":"tok"}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Contains(t, rr.Body.String(), "idempotency-key-reused")
+}
+
+func TestIdempotent_ServerErrorReleasesKey(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	idempotencyServiceMock.EXPECT().Release(mock.Anything, key).Return(nil)
+
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "key", `{}`))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	idempotencyServiceMock.AssertNumberOfCalls(t, "Complete", 0)
+}
+
+func TestIdempotent_PanicReleasesKey(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	idempotencyServiceMock.EXPECT().Release(mock.Anything, key).Return(nil)
+
+	handler := httpServer.Idempotent(func(_ http.ResponseWriter, _ *http.Request) {
+		panic("boom")
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler(httptest.NewRecorder(), newIdempotentRequest(t, "key", `{}`))
+	})
+	idempotencyServiceMock.AssertNumberOfCalls(t, "Complete", 0)
+}
+
+func TestIdempotent_CompletesAfterClientCancel(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	idempotencyServiceMock.EXPECT().Complete(mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), key, http.StatusOK, []byte(nil)).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := newIdempotentRequest(t, "key", `{}`)
+	req = req.WithContext(context.WithValue(ctx, ContextUserKey, domain.User{ID: 1, Username: "user"}))
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler(httptest.NewRecorder(), req)
+}
+
+func TestIdempotent_RenewsLeaseOfSlowRequest(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key, err := domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{
+		Key: "key", UserID: 1, Fingerprint: "hash", LockedUntil: time.Now().Add(20 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	// the renewed key has no lease left, so that it is renewed once
+	idempotencyServiceMock.EXPECT().Renew(mock.Anything, key).Return(newTestIdempotencyKey(t), nil).Once()
+	idempotencyServiceMock.EXPECT().Complete(mock.Anything, key, http.StatusOK, []byte(nil)).Return(nil)
+
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler(httptest.NewRecorder(), newIdempotentRequest(t, "key", `{}`))
+}
+
+func TestIdempotent_ResponseNotStored(t *testing.T) {
+	idempotencyServiceMock := mocks.NewIdempotencyService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, nil, idempotencyServiceMock, nil, nil)
+
+	key := newTestIdempotencyKey(t)
+	idempotencyServiceMock.EXPECT().Begin(mock.Anything, 1, "key", mock.Anything).Return(key, false, nil)
+	idempotencyServiceMock.EXPECT().Complete(mock.Anything, key, http.StatusCreated, []byte(`{"id":1}`)).
+		Return(errors.New("connection refused"))
+	// the key is kept as completed, so that a retry is not run again
+	idempotencyServiceMock.EXPECT().Complete(mock.Anything, key, http.StatusConflict,
+		[]byte(`{"slug":"idempotent-response-unavailable"}`)).Return(nil)
+
+	handler := httpServer.Idempotent(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	})
+	rr := httptest.NewRecorder()
+	handler(rr, newIdempotentRequest(t, "key", `{}`))
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, `{"id":1}`, rr.Body.String())
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	first := newIdempotentRequest(t, "key", `{}`)
+	second := newIdempotentRequest(t, "key", `{}`)
+
+	assert.Equal(t, requestFingerprint(first, []byte(`{}`)), requestFingerprint(second, []byte(`{}`)))
+	assert.NotEqual(t, requestFingerprint(first, []byte(`{}`)), requestFingerprint(first, []byte(`{"a":1}`)))
+
+	withQuery := newIdempotentRequest(t, "key", `{}`)
+	withQuery.URL.RawQuery = "dry_run=true"
+	assert.NotEqual(t, requestFingerprint(first, []byte(`{}`)), requestFingerprint(withQuery, []byte(`{}`)))
+}
@@ -24,22 +24,68 @@ type TokenService interface {
 // BookService is a book service.
 type BookService interface {
 	GetBook(ctx context.Context, id int) (domain.Book, error)
-	GetBooks(ctx context.Context, categoryIDs []int, limit, offset int) ([]domain.Book, error)
-	CreateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error)
-	DeleteBook(ctx context.Context, id int) error
+	GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetBooks(ctx context.Context, filter domain.BookFilter, limit, offset int) ([]domain.Book, error)
+	CountBooks(ctx context.Context, filter domain.BookFilter) (int, error)
+	EncodeCursor(cursor domain.BookCursor) string
+	DecodeCursor(token string) (domain.BookCursor, error)
+	CreateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error)
+	UpdateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error)
+	DeleteBook(ctx context.Context, actorID, id int) error
+	RestoreBook(ctx context.Context, actorID, id int) (domain.Book, error)
+	UploadCover(ctx context.Context, actorID, bookID int, data []byte) (domain.Book, error)
+	GetCover(ctx context.Context, key string) (domain.Blob, error)
+	ImportBooks(ctx context.Context, actorID int, books []domain.Book, dryRun bool) ([]domain.BookImportResult, error)
+	ExportBooks(ctx context.Context, filter domain.BookFilter, fn func(books []domain.ExportedBook) error) error
+}
+
+// AuthorService is an author service.
+type AuthorService interface {
+	GetAuthor(ctx context.Context, id int) (domain.Author, error)
+	GetAuthors(ctx context.Context) ([]domain.Author, error)
+	CreateAuthor(ctx context.Context, author domain.Author) (domain.Author, error)
+	UpdateAuthor(ctx context.Context, actorID int, author domain.Author) (domain.Author, error)
+	MergeAuthors(ctx context.Context, actorID, id, intoID int) (domain.Author, error)
+	DeleteAuthor(ctx context.Context, actorID, id int) error
 }
 
 // CategoryService is a category service.
 type CategoryService interface {
 	GetCategory(ctx context.Context, id int) (domain.Category, error)
-	GetCategories(ctx context.Context) ([]domain.Category, error)
-	CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error)
-	DeleteCategory(ctx context.Context, id int) error
+	GetCategories(ctx context.Context, withStats bool) ([]domain.Category, error)
+	CreateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error)
+	UpdateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error)
+	DeleteCategory(ctx context.Context, actorID, id, reassignTo int) error
+	GetDeletedCategories(ctx context.Context) ([]domain.Category, error)
+	RestoreCategory(ctx context.Context, actorID, id int) (domain.Category, error)
 }
 
 type CartService interface {
+	GetCart(ctx context.Context, userID int) (domain.Cart, error)
 	UpdateCartAndStocks(ctx context.Context, cart domain.Cart) (domain.Cart, error)
-	Checkout(ctx context.Context, userID int) error
+	SetCartItem(ctx context.Context, userID, bookID, quantity int) (domain.Cart, error)
+	RemoveCartItem(ctx context.Context, userID, bookID int) (domain.Cart, error)
+	ClearCart(ctx context.Context, userID int) (domain.Cart, error)
+	Checkout(ctx context.Context, userID int, cardToken string) (domain.Order, error)
+}
+
+// OrderService is an order service.
+type OrderService interface {
+	GetUserOrder(ctx context.Context, userID, id int) (domain.Order, error)
+	GetOrders(ctx context.Context, filter domain.OrderFilter, limit, offset int) ([]domain.Order, error)
+	UpdateOrderStatus(ctx context.Context, id int, status domain.OrderStatus) (domain.Order, error)
+	CancelUserOrder(ctx context.Context, userID, id int) (domain.Order, error)
+}
+
+// AuditService reads the audit trail of the admin changes.
+type AuditService interface {
+	GetAuditEvents(ctx context.Context, filter domain.AuditFilter, limit, offset int) ([]domain.AuditEvent, error)
+}
+
+// IdempotencyService is an idempotency key service.
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID int, key, fingerprint string) (domain.IdempotencyKey, bool, error)
+	Renew(ctx context.Context, key domain.IdempotencyKey) (domain.IdempotencyKey, error)
+	Complete(ctx context.Context, key domain.IdempotencyKey, status int, body []byte) error
+	Release(ctx context.Context, key domain.IdempotencyKey) error
 }
@@ -23,9 +23,66 @@ func (_m *BookService) EXPECT() *BookService_Expecter {
 	return &BookService_Expecter{mock: &_m.Mock}
 }
 
-// CreateBook provides a mock function with given fields: ctx, book
-func (_m *BookService) CreateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	ret := _m.Called(ctx, book)
+// CountBooks provides a mock function with given fields: ctx, filter
+func (_m *BookService) CountBooks(ctx context.Context, filter domain.BookFilter) (int, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountBooks")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookFilter) (int, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookFilter) int); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BookFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_CountBooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountBooks'
+type BookService_CountBooks_Call struct {
+	*mock.Call
+}
+
+// CountBooks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.BookFilter
+func (_e *BookService_Expecter) CountBooks(ctx interface{}, filter interface{}) *BookService_CountBooks_Call {
+	return &BookService_CountBooks_Call{Call: _e.mock.On("CountBooks", ctx, filter)}
+}
+
+func (_c *BookService_CountBooks_Call) Run(run func(ctx context.Context, filter domain.BookFilter)) *BookService_CountBooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.BookFilter))
+	})
+	return _c
+}
+
+func (_c *BookService_CountBooks_Call) Return(_a0 int, _a1 error) *BookService_CountBooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_CountBooks_Call) RunAndReturn(run func(context.Context, domain.BookFilter) (int, error)) *BookService_CountBooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateBook provides a mock function with given fields: ctx, actorID, book
+func (_m *BookService) CreateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
+	ret := _m.Called(ctx, actorID, book)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateBook")
@@ -33,17 +90,17 @@ func (_m *BookService) CreateBook(ctx context.Context, book domain.Book) (domain
 
 	var r0 domain.Book
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Book) (domain.Book, error)); ok {
-		return rf(ctx, book)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Book) (domain.Book, error)); ok {
+		return rf(ctx, actorID, book)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Book) domain.Book); ok {
-		r0 = rf(ctx, book)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Book) domain.Book); ok {
+		r0 = rf(ctx, actorID, book)
 	} else {
 		r0 = ret.Get(0).(domain.Book)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Book) error); ok {
-		r1 = rf(ctx, book)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Book) error); ok {
+		r1 = rf(ctx, actorID, book)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,14 +115,15 @@ type BookService_CreateBook_Call struct {
 
 // CreateBook is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - book domain.Book
-func (_e *BookService_Expecter) CreateBook(ctx interface{}, book interface{}) *BookService_CreateBook_Call {
-	return &BookService_CreateBook_Call{Call: _e.mock.On("CreateBook", ctx, book)}
+func (_e *BookService_Expecter) CreateBook(ctx interface{}, actorID interface{}, book interface{}) *BookService_CreateBook_Call {
+	return &BookService_CreateBook_Call{Call: _e.mock.On("CreateBook", ctx, actorID, book)}
 }
 
-func (_c *BookService_CreateBook_Call) Run(run func(ctx context.Context, book domain.Book)) *BookService_CreateBook_Call {
+func (_c *BookService_CreateBook_Call) Run(run func(ctx context.Context, actorID int, book domain.Book)) *BookService_CreateBook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(domain.Book))
+		run(args[0].(context.Context), args[1].(int), args[2].(domain.Book))
 	})
 	return _c
 }
@@ -75,22 +133,78 @@ func (_c *BookService_CreateBook_Call) Return(_a0 domain.Book, _a1 error) *BookS
 	return _c
 }
 
-func (_c *BookService_CreateBook_Call) RunAndReturn(run func(context.Context, domain.Book) (domain.Book, error)) *BookService_CreateBook_Call {
+func (_c *BookService_CreateBook_Call) RunAndReturn(run func(context.Context, int, domain.Book) (domain.Book, error)) *BookService_CreateBook_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteBook provides a mock function with given fields: ctx, id
-func (_m *BookService) DeleteBook(ctx context.Context, id int) error {
-	ret := _m.Called(ctx, id)
+// DecodeCursor provides a mock function with given fields: token
+func (_m *BookService) DecodeCursor(token string) (domain.BookCursor, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecodeCursor")
+	}
+
+	var r0 domain.BookCursor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (domain.BookCursor, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(string) domain.BookCursor); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Get(0).(domain.BookCursor)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_DecodeCursor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DecodeCursor'
+type BookService_DecodeCursor_Call struct {
+	*mock.Call
+}
+
+// DecodeCursor is a helper method to define mock.On call
+//   - token string
+func (_e *BookService_Expecter) DecodeCursor(token interface{}) *BookService_DecodeCursor_Call {
+	return &BookService_DecodeCursor_Call{Call: _e.mock.On("DecodeCursor", token)}
+}
+
+func (_c *BookService_DecodeCursor_Call) Run(run func(token string)) *BookService_DecodeCursor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *BookService_DecodeCursor_Call) Return(_a0 domain.BookCursor, _a1 error) *BookService_DecodeCursor_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_DecodeCursor_Call) RunAndReturn(run func(string) (domain.BookCursor, error)) *BookService_DecodeCursor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteBook provides a mock function with given fields: ctx, actorID, id
+func (_m *BookService) DeleteBook(ctx context.Context, actorID int, id int) error {
+	ret := _m.Called(ctx, actorID, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteBook")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, actorID, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -105,14 +219,15 @@ type BookService_DeleteBook_Call struct {
 
 // DeleteBook is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - id int
-func (_e *BookService_Expecter) DeleteBook(ctx interface{}, id interface{}) *BookService_DeleteBook_Call {
-	return &BookService_DeleteBook_Call{Call: _e.mock.On("DeleteBook", ctx, id)}
+func (_e *BookService_Expecter) DeleteBook(ctx interface{}, actorID interface{}, id interface{}) *BookService_DeleteBook_Call {
+	return &BookService_DeleteBook_Call{Call: _e.mock.On("DeleteBook", ctx, actorID, id)}
 }
 
-func (_c *BookService_DeleteBook_Call) Run(run func(ctx context.Context, id int)) *BookService_DeleteBook_Call {
+func (_c *BookService_DeleteBook_Call) Run(run func(ctx context.Context, actorID int, id int)) *BookService_DeleteBook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
 	})
 	return _c
 }
@@ -122,7 +237,101 @@ func (_c *BookService_DeleteBook_Call) Return(_a0 error) *BookService_DeleteBook
 	return _c
 }
 
-func (_c *BookService_DeleteBook_Call) RunAndReturn(run func(context.Context, int) error) *BookService_DeleteBook_Call {
+func (_c *BookService_DeleteBook_Call) RunAndReturn(run func(context.Context, int, int) error) *BookService_DeleteBook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EncodeCursor provides a mock function with given fields: cursor
+func (_m *BookService) EncodeCursor(cursor domain.BookCursor) string {
+	ret := _m.Called(cursor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EncodeCursor")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(domain.BookCursor) string); ok {
+		r0 = rf(cursor)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// BookService_EncodeCursor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EncodeCursor'
+type BookService_EncodeCursor_Call struct {
+	*mock.Call
+}
+
+// EncodeCursor is a helper method to define mock.On call
+//   - cursor domain.BookCursor
+func (_e *BookService_Expecter) EncodeCursor(cursor interface{}) *BookService_EncodeCursor_Call {
+	return &BookService_EncodeCursor_Call{Call: _e.mock.On("EncodeCursor", cursor)}
+}
+
+func (_c *BookService_EncodeCursor_Call) Run(run func(cursor domain.BookCursor)) *BookService_EncodeCursor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(domain.BookCursor))
+	})
+	return _c
+}
+
+func (_c *BookService_EncodeCursor_Call) Return(_a0 string) *BookService_EncodeCursor_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BookService_EncodeCursor_Call) RunAndReturn(run func(domain.BookCursor) string) *BookService_EncodeCursor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportBooks provides a mock function with given fields: ctx, filter, fn
+func (_m *BookService) ExportBooks(ctx context.Context, filter domain.BookFilter, fn func(books []domain.ExportedBook) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportBooks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookFilter, func(books []domain.ExportedBook) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// BookService_ExportBooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportBooks'
+type BookService_ExportBooks_Call struct {
+	*mock.Call
+}
+
+// ExportBooks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter domain.BookFilter
+//   - fn func(books []domain.ExportedBook) error
+func (_e *BookService_Expecter) ExportBooks(ctx interface{}, filter interface{}, fn interface{}) *BookService_ExportBooks_Call {
+	return &BookService_ExportBooks_Call{Call: _e.mock.On("ExportBooks", ctx, filter, fn)}
+}
+
+func (_c *BookService_ExportBooks_Call) Run(run func(ctx context.Context, filter domain.BookFilter, fn func(books []domain.ExportedBook) error)) *BookService_ExportBooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(domain.BookFilter), args[2].(func(books []domain.ExportedBook) error))
+	})
+	return _c
+}
+
+func (_c *BookService_ExportBooks_Call) Return(_a0 error) *BookService_ExportBooks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *BookService_ExportBooks_Call) RunAndReturn(run func(context.Context, domain.BookFilter, func(books []domain.ExportedBook) error) error) *BookService_ExportBooks_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -184,9 +393,66 @@ func (_c *BookService_GetBook_Call) RunAndReturn(run func(context.Context, int)
 	return _c
 }
 
-// GetBooks provides a mock function with given fields: ctx, categoryIDs, limit, offset
-func (_m *BookService) GetBooks(ctx context.Context, categoryIDs []int, limit int, offset int) ([]domain.Book, error) {
-	ret := _m.Called(ctx, categoryIDs, limit, offset)
+// GetBookByISBN provides a mock function with given fields: ctx, isbn
+func (_m *BookService) GetBookByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	ret := _m.Called(ctx, isbn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBookByISBN")
+	}
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Book, error)); ok {
+		return rf(ctx, isbn)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Book); ok {
+		r0 = rf(ctx, isbn)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, isbn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_GetBookByISBN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBookByISBN'
+type BookService_GetBookByISBN_Call struct {
+	*mock.Call
+}
+
+// GetBookByISBN is a helper method to define mock.On call
+//   - ctx context.Context
+//   - isbn string
+func (_e *BookService_Expecter) GetBookByISBN(ctx interface{}, isbn interface{}) *BookService_GetBookByISBN_Call {
+	return &BookService_GetBookByISBN_Call{Call: _e.mock.On("GetBookByISBN", ctx, isbn)}
+}
+
+func (_c *BookService_GetBookByISBN_Call) Run(run func(ctx context.Context, isbn string)) *BookService_GetBookByISBN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *BookService_GetBookByISBN_Call) Return(_a0 domain.Book, _a1 error) *BookService_GetBookByISBN_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_GetBookByISBN_Call) RunAndReturn(run func(context.Context, string) (domain.Book, error)) *BookService_GetBookByISBN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBooks provides a mock function with given fields: ctx, filter, limit, offset
+func (_m *BookService) GetBooks(ctx context.Context, filter domain.BookFilter, limit int, offset int) ([]domain.Book, error) {
+	ret := _m.Called(ctx, filter, limit, offset)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetBooks")
@@ -194,19 +460,19 @@ func (_m *BookService) GetBooks(ctx context.Context, categoryIDs []int, limit in
 
 	var r0 []domain.Book
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, []int, int, int) ([]domain.Book, error)); ok {
-		return rf(ctx, categoryIDs, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookFilter, int, int) ([]domain.Book, error)); ok {
+		return rf(ctx, filter, limit, offset)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, []int, int, int) []domain.Book); ok {
-		r0 = rf(ctx, categoryIDs, limit, offset)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.BookFilter, int, int) []domain.Book); ok {
+		r0 = rf(ctx, filter, limit, offset)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Book)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, []int, int, int) error); ok {
-		r1 = rf(ctx, categoryIDs, limit, offset)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.BookFilter, int, int) error); ok {
+		r1 = rf(ctx, filter, limit, offset)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -221,16 +487,16 @@ type BookService_GetBooks_Call struct {
 
 // GetBooks is a helper method to define mock.On call
 //   - ctx context.Context
-//   - categoryIDs []int
+//   - filter domain.BookFilter
 //   - limit int
 //   - offset int
-func (_e *BookService_Expecter) GetBooks(ctx interface{}, categoryIDs interface{}, limit interface{}, offset interface{}) *BookService_GetBooks_Call {
-	return &BookService_GetBooks_Call{Call: _e.mock.On("GetBooks", ctx, categoryIDs, limit, offset)}
+func (_e *BookService_Expecter) GetBooks(ctx interface{}, filter interface{}, limit interface{}, offset interface{}) *BookService_GetBooks_Call {
+	return &BookService_GetBooks_Call{Call: _e.mock.On("GetBooks", ctx, filter, limit, offset)}
 }
 
-func (_c *BookService_GetBooks_Call) Run(run func(ctx context.Context, categoryIDs []int, limit int, offset int)) *BookService_GetBooks_Call {
+func (_c *BookService_GetBooks_Call) Run(run func(ctx context.Context, filter domain.BookFilter, limit int, offset int)) *BookService_GetBooks_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].([]int), args[2].(int), args[3].(int))
+		run(args[0].(context.Context), args[1].(domain.BookFilter), args[2].(int), args[3].(int))
 	})
 	return _c
 }
@@ -240,14 +506,190 @@ func (_c *BookService_GetBooks_Call) Return(_a0 []domain.Book, _a1 error) *BookS
 	return _c
 }
 
-func (_c *BookService_GetBooks_Call) RunAndReturn(run func(context.Context, []int, int, int) ([]domain.Book, error)) *BookService_GetBooks_Call {
+func (_c *BookService_GetBooks_Call) RunAndReturn(run func(context.Context, domain.BookFilter, int, int) ([]domain.Book, error)) *BookService_GetBooks_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateBook provides a mock function with given fields: ctx, book
-func (_m *BookService) UpdateBook(ctx context.Context, book domain.Book) (domain.Book, error) {
-	ret := _m.Called(ctx, book)
+// GetCover provides a mock function with given fields: ctx, key
+func (_m *BookService) GetCover(ctx context.Context, key string) (domain.Blob, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCover")
+	}
+
+	var r0 domain.Blob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Blob, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Blob); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(domain.Blob)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_GetCover_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCover'
+type BookService_GetCover_Call struct {
+	*mock.Call
+}
+
+// GetCover is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *BookService_Expecter) GetCover(ctx interface{}, key interface{}) *BookService_GetCover_Call {
+	return &BookService_GetCover_Call{Call: _e.mock.On("GetCover", ctx, key)}
+}
+
+func (_c *BookService_GetCover_Call) Run(run func(ctx context.Context, key string)) *BookService_GetCover_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *BookService_GetCover_Call) Return(_a0 domain.Blob, _a1 error) *BookService_GetCover_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_GetCover_Call) RunAndReturn(run func(context.Context, string) (domain.Blob, error)) *BookService_GetCover_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportBooks provides a mock function with given fields: ctx, actorID, books, dryRun
+func (_m *BookService) ImportBooks(ctx context.Context, actorID int, books []domain.Book, dryRun bool) ([]domain.BookImportResult, error) {
+	ret := _m.Called(ctx, actorID, books, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportBooks")
+	}
+
+	var r0 []domain.BookImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []domain.Book, bool) ([]domain.BookImportResult, error)); ok {
+		return rf(ctx, actorID, books, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []domain.Book, bool) []domain.BookImportResult); ok {
+		r0 = rf(ctx, actorID, books, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.BookImportResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []domain.Book, bool) error); ok {
+		r1 = rf(ctx, actorID, books, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_ImportBooks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportBooks'
+type BookService_ImportBooks_Call struct {
+	*mock.Call
+}
+
+// ImportBooks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID int
+//   - books []domain.Book
+//   - dryRun bool
+func (_e *BookService_Expecter) ImportBooks(ctx interface{}, actorID interface{}, books interface{}, dryRun interface{}) *BookService_ImportBooks_Call {
+	return &BookService_ImportBooks_Call{Call: _e.mock.On("ImportBooks", ctx, actorID, books, dryRun)}
+}
+
+func (_c *BookService_ImportBooks_Call) Run(run func(ctx context.Context, actorID int, books []domain.Book, dryRun bool)) *BookService_ImportBooks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].([]domain.Book), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *BookService_ImportBooks_Call) Return(_a0 []domain.BookImportResult, _a1 error) *BookService_ImportBooks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_ImportBooks_Call) RunAndReturn(run func(context.Context, int, []domain.Book, bool) ([]domain.BookImportResult, error)) *BookService_ImportBooks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreBook provides a mock function with given fields: ctx, actorID, id
+func (_m *BookService) RestoreBook(ctx context.Context, actorID int, id int) (domain.Book, error) {
+	ret := _m.Called(ctx, actorID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreBook")
+	}
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Book, error)); ok {
+		return rf(ctx, actorID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Book); ok {
+		r0 = rf(ctx, actorID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, actorID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_RestoreBook_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreBook'
+type BookService_RestoreBook_Call struct {
+	*mock.Call
+}
+
+// RestoreBook is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID int
+//   - id int
+func (_e *BookService_Expecter) RestoreBook(ctx interface{}, actorID interface{}, id interface{}) *BookService_RestoreBook_Call {
+	return &BookService_RestoreBook_Call{Call: _e.mock.On("RestoreBook", ctx, actorID, id)}
+}
+
+func (_c *BookService_RestoreBook_Call) Run(run func(ctx context.Context, actorID int, id int)) *BookService_RestoreBook_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *BookService_RestoreBook_Call) Return(_a0 domain.Book, _a1 error) *BookService_RestoreBook_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_RestoreBook_Call) RunAndReturn(run func(context.Context, int, int) (domain.Book, error)) *BookService_RestoreBook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateBook provides a mock function with given fields: ctx, actorID, book
+func (_m *BookService) UpdateBook(ctx context.Context, actorID int, book domain.Book) (domain.Book, error) {
+	ret := _m.Called(ctx, actorID, book)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateBook")
@@ -255,17 +697,17 @@ func (_m *BookService) UpdateBook(ctx context.Context, book domain.Book) (domain
 
 	var r0 domain.Book
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Book) (domain.Book, error)); ok {
-		return rf(ctx, book)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Book) (domain.Book, error)); ok {
+		return rf(ctx, actorID, book)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Book) domain.Book); ok {
-		r0 = rf(ctx, book)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Book) domain.Book); ok {
+		r0 = rf(ctx, actorID, book)
 	} else {
 		r0 = ret.Get(0).(domain.Book)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Book) error); ok {
-		r1 = rf(ctx, book)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Book) error); ok {
+		r1 = rf(ctx, actorID, book)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -280,14 +722,15 @@ type BookService_UpdateBook_Call struct {
 
 // UpdateBook is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - book domain.Book
-func (_e *BookService_Expecter) UpdateBook(ctx interface{}, book interface{}) *BookService_UpdateBook_Call {
-	return &BookService_UpdateBook_Call{Call: _e.mock.On("UpdateBook", ctx, book)}
+func (_e *BookService_Expecter) UpdateBook(ctx interface{}, actorID interface{}, book interface{}) *BookService_UpdateBook_Call {
+	return &BookService_UpdateBook_Call{Call: _e.mock.On("UpdateBook", ctx, actorID, book)}
 }
 
-func (_c *BookService_UpdateBook_Call) Run(run func(ctx context.Context, book domain.Book)) *BookService_UpdateBook_Call {
+func (_c *BookService_UpdateBook_Call) Run(run func(ctx context.Context, actorID int, book domain.Book)) *BookService_UpdateBook_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(domain.Book))
+		run(args[0].(context.Context), args[1].(int), args[2].(domain.Book))
 	})
 	return _c
 }
@@ -297,7 +740,66 @@ func (_c *BookService_UpdateBook_Call) Return(_a0 domain.Book, _a1 error) *BookS
 	return _c
 }
 
-func (_c *BookService_UpdateBook_Call) RunAndReturn(run func(context.Context, domain.Book) (domain.Book, error)) *BookService_UpdateBook_Call {
+func (_c *BookService_UpdateBook_Call) RunAndReturn(run func(context.Context, int, domain.Book) (domain.Book, error)) *BookService_UpdateBook_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UploadCover provides a mock function with given fields: ctx, actorID, bookID, data
+func (_m *BookService) UploadCover(ctx context.Context, actorID int, bookID int, data []byte) (domain.Book, error) {
+	ret := _m.Called(ctx, actorID, bookID, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadCover")
+	}
+
+	var r0 domain.Book
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []byte) (domain.Book, error)); ok {
+		return rf(ctx, actorID, bookID, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []byte) domain.Book); ok {
+		r0 = rf(ctx, actorID, bookID, data)
+	} else {
+		r0 = ret.Get(0).(domain.Book)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, []byte) error); ok {
+		r1 = rf(ctx, actorID, bookID, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BookService_UploadCover_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadCover'
+type BookService_UploadCover_Call struct {
+	*mock.Call
+}
+
+// UploadCover is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID int
+//   - bookID int
+//   - data []byte
+func (_e *BookService_Expecter) UploadCover(ctx interface{}, actorID interface{}, bookID interface{}, data interface{}) *BookService_UploadCover_Call {
+	return &BookService_UploadCover_Call{Call: _e.mock.On("UploadCover", ctx, actorID, bookID, data)}
+}
+
+func (_c *BookService_UploadCover_Call) Run(run func(ctx context.Context, actorID int, bookID int, data []byte)) *BookService_UploadCover_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *BookService_UploadCover_Call) Return(_a0 domain.Book, _a1 error) *BookService_UploadCover_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BookService_UploadCover_Call) RunAndReturn(run func(context.Context, int, int, []byte) (domain.Book, error)) *BookService_UploadCover_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -23,22 +23,32 @@ func (_m *CartService) EXPECT() *CartService_Expecter {
 	return &CartService_Expecter{mock: &_m.Mock}
 }
 
-// Checkout provides a mock function with given fields: ctx, userID
-func (_m *CartService) Checkout(ctx context.Context, userID int) error {
-	ret := _m.Called(ctx, userID)
+// Checkout provides a mock function with given fields: ctx, userID, cardToken
+func (_m *CartService) Checkout(ctx context.Context, userID int, cardToken string) (domain.Order, error) {
+	ret := _m.Called(ctx, userID, cardToken)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Checkout")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, userID)
+	var r0 domain.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (domain.Order, error)); ok {
+		return rf(ctx, userID, cardToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) domain.Order); ok {
+		r0 = rf(ctx, userID, cardToken)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(domain.Order)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, cardToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // CartService_Checkout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checkout'
@@ -49,23 +59,255 @@ type CartService_Checkout_Call struct {
 // Checkout is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID int
-func (_e *CartService_Expecter) Checkout(ctx interface{}, userID interface{}) *CartService_Checkout_Call {
-	return &CartService_Checkout_Call{Call: _e.mock.On("Checkout", ctx, userID)}
+//   - cardToken string
+func (_e *CartService_Expecter) Checkout(ctx interface{}, userID interface{}, cardToken interface{}) *CartService_Checkout_Call {
+	return &CartService_Checkout_Call{Call: _e.mock.On("Checkout", ctx, userID, cardToken)}
+}
+
+func (_c *CartService_Checkout_Call) Run(run func(ctx context.Context, userID int, cardToken string)) *CartService_Checkout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *CartService_Checkout_Call) Return(_a0 domain.Order, _a1 error) *CartService_Checkout_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CartService_Checkout_Call) RunAndReturn(run func(context.Context, int, string) (domain.Order, error)) *CartService_Checkout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClearCart provides a mock function with given fields: ctx, userID
+func (_m *CartService) ClearCart(ctx context.Context, userID int) (domain.Cart, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearCart")
+	}
+
+	var r0 domain.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Cart, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Cart); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(domain.Cart)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CartService_ClearCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClearCart'
+type CartService_ClearCart_Call struct {
+	*mock.Call
+}
+
+// ClearCart is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *CartService_Expecter) ClearCart(ctx interface{}, userID interface{}) *CartService_ClearCart_Call {
+	return &CartService_ClearCart_Call{Call: _e.mock.On("ClearCart", ctx, userID)}
 }
 
-func (_c *CartService_Checkout_Call) Run(run func(ctx context.Context, userID int)) *CartService_Checkout_Call {
+func (_c *CartService_ClearCart_Call) Run(run func(ctx context.Context, userID int)) *CartService_ClearCart_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(int))
 	})
 	return _c
 }
 
-func (_c *CartService_Checkout_Call) Return(_a0 error) *CartService_Checkout_Call {
-	_c.Call.Return(_a0)
+func (_c *CartService_ClearCart_Call) Return(_a0 domain.Cart, _a1 error) *CartService_ClearCart_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CartService_ClearCart_Call) RunAndReturn(run func(context.Context, int) (domain.Cart, error)) *CartService_ClearCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCart provides a mock function with given fields: ctx, userID
+func (_m *CartService) GetCart(ctx context.Context, userID int) (domain.Cart, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCart")
+	}
+
+	var r0 domain.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Cart, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Cart); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(domain.Cart)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CartService_GetCart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCart'
+type CartService_GetCart_Call struct {
+	*mock.Call
+}
+
+// GetCart is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *CartService_Expecter) GetCart(ctx interface{}, userID interface{}) *CartService_GetCart_Call {
+	return &CartService_GetCart_Call{Call: _e.mock.On("GetCart", ctx, userID)}
+}
+
+func (_c *CartService_GetCart_Call) Run(run func(ctx context.Context, userID int)) *CartService_GetCart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *CartService_GetCart_Call) Return(_a0 domain.Cart, _a1 error) *CartService_GetCart_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CartService_GetCart_Call) RunAndReturn(run func(context.Context, int) (domain.Cart, error)) *CartService_GetCart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveCartItem provides a mock function with given fields: ctx, userID, bookID
+func (_m *CartService) RemoveCartItem(ctx context.Context, userID int, bookID int) (domain.Cart, error) {
+	ret := _m.Called(ctx, userID, bookID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveCartItem")
+	}
+
+	var r0 domain.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Cart, error)); ok {
+		return rf(ctx, userID, bookID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Cart); ok {
+		r0 = rf(ctx, userID, bookID)
+	} else {
+		r0 = ret.Get(0).(domain.Cart)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userID, bookID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CartService_RemoveCartItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveCartItem'
+type CartService_RemoveCartItem_Call struct {
+	*mock.Call
+}
+
+// RemoveCartItem is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - bookID int
+func (_e *CartService_Expecter) RemoveCartItem(ctx interface{}, userID interface{}, bookID interface{}) *CartService_RemoveCartItem_Call {
+	return &CartService_RemoveCartItem_Call{Call: _e.mock.On("RemoveCartItem", ctx, userID, bookID)}
+}
+
+func (_c *CartService_RemoveCartItem_Call) Run(run func(ctx context.Context, userID int, bookID int)) *CartService_RemoveCartItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *CartService_RemoveCartItem_Call) Return(_a0 domain.Cart, _a1 error) *CartService_RemoveCartItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CartService_RemoveCartItem_Call) RunAndReturn(run func(context.Context, int, int) (domain.Cart, error)) *CartService_RemoveCartItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetCartItem provides a mock function with given fields: ctx, userID, bookID, quantity
+func (_m *CartService) SetCartItem(ctx context.Context, userID int, bookID int, quantity int) (domain.Cart, error) {
+	ret := _m.Called(ctx, userID, bookID, quantity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCartItem")
+	}
+
+	var r0 domain.Cart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) (domain.Cart, error)); ok {
+		return rf(ctx, userID, bookID, quantity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) domain.Cart); ok {
+		r0 = rf(ctx, userID, bookID, quantity)
+	} else {
+		r0 = ret.Get(0).(domain.Cart)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = rf(ctx, userID, bookID, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CartService_SetCartItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCartItem'
+type CartService_SetCartItem_Call struct {
+	*mock.Call
+}
+
+// SetCartItem is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - bookID int
+//   - quantity int
+func (_e *CartService_Expecter) SetCartItem(ctx interface{}, userID interface{}, bookID interface{}, quantity interface{}) *CartService_SetCartItem_Call {
+	return &CartService_SetCartItem_Call{Call: _e.mock.On("SetCartItem", ctx, userID, bookID, quantity)}
+}
+
+func (_c *CartService_SetCartItem_Call) Run(run func(ctx context.Context, userID int, bookID int, quantity int)) *CartService_SetCartItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *CartService_SetCartItem_Call) Return(_a0 domain.Cart, _a1 error) *CartService_SetCartItem_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *CartService_Checkout_Call) RunAndReturn(run func(context.Context, int) error) *CartService_Checkout_Call {
+func (_c *CartService_SetCartItem_Call) RunAndReturn(run func(context.Context, int, int, int) (domain.Cart, error)) *CartService_SetCartItem_Call {
 	_c.Call.Return(run)
 	return _c
 }
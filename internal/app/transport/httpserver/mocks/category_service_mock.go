@@ -23,9 +23,9 @@ func (_m *CategoryService) EXPECT() *CategoryService_Expecter {
 	return &CategoryService_Expecter{mock: &_m.Mock}
 }
 
-// CreateCategory provides a mock function with given fields: ctx, category
-func (_m *CategoryService) CreateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
-	ret := _m.Called(ctx, category)
+// CreateCategory provides a mock function with given fields: ctx, actorID, category
+func (_m *CategoryService) CreateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error) {
+	ret := _m.Called(ctx, actorID, category)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateCategory")
@@ -33,17 +33,17 @@ func (_m *CategoryService) CreateCategory(ctx context.Context, category domain.C
 
 	var r0 domain.Category
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Category) (domain.Category, error)); ok {
-		return rf(ctx, category)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Category) (domain.Category, error)); ok {
+		return rf(ctx, actorID, category)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Category) domain.Category); ok {
-		r0 = rf(ctx, category)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Category) domain.Category); ok {
+		r0 = rf(ctx, actorID, category)
 	} else {
 		r0 = ret.Get(0).(domain.Category)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Category) error); ok {
-		r1 = rf(ctx, category)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Category) error); ok {
+		r1 = rf(ctx, actorID, category)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,14 +58,15 @@ type CategoryService_CreateCategory_Call struct {
 
 // CreateCategory is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - category domain.Category
-func (_e *CategoryService_Expecter) CreateCategory(ctx interface{}, category interface{}) *CategoryService_CreateCategory_Call {
-	return &CategoryService_CreateCategory_Call{Call: _e.mock.On("CreateCategory", ctx, category)}
+func (_e *CategoryService_Expecter) CreateCategory(ctx interface{}, actorID interface{}, category interface{}) *CategoryService_CreateCategory_Call {
+	return &CategoryService_CreateCategory_Call{Call: _e.mock.On("CreateCategory", ctx, actorID, category)}
 }
 
-func (_c *CategoryService_CreateCategory_Call) Run(run func(ctx context.Context, category domain.Category)) *CategoryService_CreateCategory_Call {
+func (_c *CategoryService_CreateCategory_Call) Run(run func(ctx context.Context, actorID int, category domain.Category)) *CategoryService_CreateCategory_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(domain.Category))
+		run(args[0].(context.Context), args[1].(int), args[2].(domain.Category))
 	})
 	return _c
 }
@@ -75,22 +76,22 @@ func (_c *CategoryService_CreateCategory_Call) Return(_a0 domain.Category, _a1 e
 	return _c
 }
 
-func (_c *CategoryService_CreateCategory_Call) RunAndReturn(run func(context.Context, domain.Category) (domain.Category, error)) *CategoryService_CreateCategory_Call {
+func (_c *CategoryService_CreateCategory_Call) RunAndReturn(run func(context.Context, int, domain.Category) (domain.Category, error)) *CategoryService_CreateCategory_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteCategory provides a mock function with given fields: ctx, id
-func (_m *CategoryService) DeleteCategory(ctx context.Context, id int) error {
-	ret := _m.Called(ctx, id)
+// DeleteCategory provides a mock function with given fields: ctx, actorID, id, reassignTo
+func (_m *CategoryService) DeleteCategory(ctx context.Context, actorID int, id int, reassignTo int) error {
+	ret := _m.Called(ctx, actorID, id, reassignTo)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteCategory")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) error); ok {
+		r0 = rf(ctx, actorID, id, reassignTo)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -105,14 +106,16 @@ type CategoryService_DeleteCategory_Call struct {
 
 // DeleteCategory is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - id int
-func (_e *CategoryService_Expecter) DeleteCategory(ctx interface{}, id interface{}) *CategoryService_DeleteCategory_Call {
-	return &CategoryService_DeleteCategory_Call{Call: _e.mock.On("DeleteCategory", ctx, id)}
+//   - reassignTo int
+func (_e *CategoryService_Expecter) DeleteCategory(ctx interface{}, actorID interface{}, id interface{}, reassignTo interface{}) *CategoryService_DeleteCategory_Call {
+	return &CategoryService_DeleteCategory_Call{Call: _e.mock.On("DeleteCategory", ctx, actorID, id, reassignTo)}
 }
 
-func (_c *CategoryService_DeleteCategory_Call) Run(run func(ctx context.Context, id int)) *CategoryService_DeleteCategory_Call {
+func (_c *CategoryService_DeleteCategory_Call) Run(run func(ctx context.Context, actorID int, id int, reassignTo int)) *CategoryService_DeleteCategory_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
 	})
 	return _c
 }
@@ -122,14 +125,14 @@ func (_c *CategoryService_DeleteCategory_Call) Return(_a0 error) *CategoryServic
 	return _c
 }
 
-func (_c *CategoryService_DeleteCategory_Call) RunAndReturn(run func(context.Context, int) error) *CategoryService_DeleteCategory_Call {
+func (_c *CategoryService_DeleteCategory_Call) RunAndReturn(run func(context.Context, int, int, int) error) *CategoryService_DeleteCategory_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetCategories provides a mock function with given fields: ctx
-func (_m *CategoryService) GetCategories(ctx context.Context) ([]domain.Category, error) {
-	ret := _m.Called(ctx)
+// GetCategories provides a mock function with given fields: ctx, withStats
+func (_m *CategoryService) GetCategories(ctx context.Context, withStats bool) ([]domain.Category, error) {
+	ret := _m.Called(ctx, withStats)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCategories")
@@ -137,19 +140,19 @@ func (_m *CategoryService) GetCategories(ctx context.Context) ([]domain.Category
 
 	var r0 []domain.Category
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Category, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, bool) ([]domain.Category, error)); ok {
+		return rf(ctx, withStats)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []domain.Category); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, bool) []domain.Category); ok {
+		r0 = rf(ctx, withStats)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Category)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, bool) error); ok {
+		r1 = rf(ctx, withStats)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -164,13 +167,14 @@ type CategoryService_GetCategories_Call struct {
 
 // GetCategories is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *CategoryService_Expecter) GetCategories(ctx interface{}) *CategoryService_GetCategories_Call {
-	return &CategoryService_GetCategories_Call{Call: _e.mock.On("GetCategories", ctx)}
+//   - withStats bool
+func (_e *CategoryService_Expecter) GetCategories(ctx interface{}, withStats interface{}) *CategoryService_GetCategories_Call {
+	return &CategoryService_GetCategories_Call{Call: _e.mock.On("GetCategories", ctx, withStats)}
 }
 
-func (_c *CategoryService_GetCategories_Call) Run(run func(ctx context.Context)) *CategoryService_GetCategories_Call {
+func (_c *CategoryService_GetCategories_Call) Run(run func(ctx context.Context, withStats bool)) *CategoryService_GetCategories_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context))
+		run(args[0].(context.Context), args[1].(bool))
 	})
 	return _c
 }
@@ -180,7 +184,7 @@ func (_c *CategoryService_GetCategories_Call) Return(_a0 []domain.Category, _a1
 	return _c
 }
 
-func (_c *CategoryService_GetCategories_Call) RunAndReturn(run func(context.Context) ([]domain.Category, error)) *CategoryService_GetCategories_Call {
+func (_c *CategoryService_GetCategories_Call) RunAndReturn(run func(context.Context, bool) ([]domain.Category, error)) *CategoryService_GetCategories_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -242,9 +246,125 @@ func (_c *CategoryService_GetCategory_Call) RunAndReturn(run func(context.Contex
 	return _c
 }
 
-// UpdateCategory provides a mock function with given fields: ctx, category
-func (_m *CategoryService) UpdateCategory(ctx context.Context, category domain.Category) (domain.Category, error) {
-	ret := _m.Called(ctx, category)
+// GetDeletedCategories provides a mock function with given fields: ctx
+func (_m *CategoryService) GetDeletedCategories(ctx context.Context) ([]domain.Category, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeletedCategories")
+	}
+
+	var r0 []domain.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Category, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Category); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CategoryService_GetDeletedCategories_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeletedCategories'
+type CategoryService_GetDeletedCategories_Call struct {
+	*mock.Call
+}
+
+// GetDeletedCategories is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *CategoryService_Expecter) GetDeletedCategories(ctx interface{}) *CategoryService_GetDeletedCategories_Call {
+	return &CategoryService_GetDeletedCategories_Call{Call: _e.mock.On("GetDeletedCategories", ctx)}
+}
+
+func (_c *CategoryService_GetDeletedCategories_Call) Run(run func(ctx context.Context)) *CategoryService_GetDeletedCategories_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *CategoryService_GetDeletedCategories_Call) Return(_a0 []domain.Category, _a1 error) *CategoryService_GetDeletedCategories_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CategoryService_GetDeletedCategories_Call) RunAndReturn(run func(context.Context) ([]domain.Category, error)) *CategoryService_GetDeletedCategories_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreCategory provides a mock function with given fields: ctx, actorID, id
+func (_m *CategoryService) RestoreCategory(ctx context.Context, actorID int, id int) (domain.Category, error) {
+	ret := _m.Called(ctx, actorID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreCategory")
+	}
+
+	var r0 domain.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Category, error)); ok {
+		return rf(ctx, actorID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Category); ok {
+		r0 = rf(ctx, actorID, id)
+	} else {
+		r0 = ret.Get(0).(domain.Category)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, actorID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CategoryService_RestoreCategory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreCategory'
+type CategoryService_RestoreCategory_Call struct {
+	*mock.Call
+}
+
+// RestoreCategory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - actorID int
+//   - id int
+func (_e *CategoryService_Expecter) RestoreCategory(ctx interface{}, actorID interface{}, id interface{}) *CategoryService_RestoreCategory_Call {
+	return &CategoryService_RestoreCategory_Call{Call: _e.mock.On("RestoreCategory", ctx, actorID, id)}
+}
+
+func (_c *CategoryService_RestoreCategory_Call) Run(run func(ctx context.Context, actorID int, id int)) *CategoryService_RestoreCategory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *CategoryService_RestoreCategory_Call) Return(_a0 domain.Category, _a1 error) *CategoryService_RestoreCategory_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *CategoryService_RestoreCategory_Call) RunAndReturn(run func(context.Context, int, int) (domain.Category, error)) *CategoryService_RestoreCategory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateCategory provides a mock function with given fields: ctx, actorID, category
+func (_m *CategoryService) UpdateCategory(ctx context.Context, actorID int, category domain.Category) (domain.Category, error) {
+	ret := _m.Called(ctx, actorID, category)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateCategory")
@@ -252,17 +372,17 @@ func (_m *CategoryService) UpdateCategory(ctx context.Context, category domain.C
 
 	var r0 domain.Category
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Category) (domain.Category, error)); ok {
-		return rf(ctx, category)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Category) (domain.Category, error)); ok {
+		return rf(ctx, actorID, category)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Category) domain.Category); ok {
-		r0 = rf(ctx, category)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Category) domain.Category); ok {
+		r0 = rf(ctx, actorID, category)
 	} else {
 		r0 = ret.Get(0).(domain.Category)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Category) error); ok {
-		r1 = rf(ctx, category)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Category) error); ok {
+		r1 = rf(ctx, actorID, category)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -277,14 +397,15 @@ type CategoryService_UpdateCategory_Call struct {
 
 // UpdateCategory is a helper method to define mock.On call
 //   - ctx context.Context
+//   - actorID int
 //   - category domain.Category
-func (_e *CategoryService_Expecter) UpdateCategory(ctx interface{}, category interface{}) *CategoryService_UpdateCategory_Call {
-	return &CategoryService_UpdateCategory_Call{Call: _e.mock.On("UpdateCategory", ctx, category)}
+func (_e *CategoryService_Expecter) UpdateCategory(ctx interface{}, actorID interface{}, category interface{}) *CategoryService_UpdateCategory_Call {
+	return &CategoryService_UpdateCategory_Call{Call: _e.mock.On("UpdateCategory", ctx, actorID, category)}
 }
 
-func (_c *CategoryService_UpdateCategory_Call) Run(run func(ctx context.Context, category domain.Category)) *CategoryService_UpdateCategory_Call {
+func (_c *CategoryService_UpdateCategory_Call) Run(run func(ctx context.Context, actorID int, category domain.Category)) *CategoryService_UpdateCategory_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(domain.Category))
+		run(args[0].(context.Context), args[1].(int), args[2].(domain.Category))
 	})
 	return _c
 }
@@ -294,7 +415,7 @@ func (_c *CategoryService_UpdateCategory_Call) Return(_a0 domain.Category, _a1 e
 	return _c
 }
 
-func (_c *CategoryService_UpdateCategory_Call) RunAndReturn(run func(context.Context, domain.Category) (domain.Category, error)) *CategoryService_UpdateCategory_Call {
+func (_c *CategoryService_UpdateCategory_Call) RunAndReturn(run func(context.Context, int, domain.Category) (domain.Category, error)) *CategoryService_UpdateCategory_Call {
 	_c.Call.Return(run)
 	return _c
 }
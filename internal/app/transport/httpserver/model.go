@@ -1,18 +1,29 @@
 package httpserver
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 )
 
 type BookRequest struct {
-	Title      string `json:"title"`
-	Year       int    `json:"year"`
-	Author     string `json:"author"`
-	Price      int    `json:"price"`
-	Stock      int    `json:"stock"`
-	CategoryID int    `json:"categoryId"`
+	Title  string `json:"title"`
+	Year   int    `json:"year"`
+	Author string `json:"author"`
+	Price  int    `json:"price"`
+	Stock  int    `json:"stock"`
+	// CategoryID is the main category, it defaults to the first of CategoryIDs.
+	CategoryID int `json:"categoryId"`
+	// CategoryIDs are all the book categories, the main one is added when missing.
+	CategoryIDs []int `json:"categoryIds,omitempty"`
+	// ISBN is an optional ISBN-10 or ISBN-13, hyphens are allowed.
+	ISBN string `json:"isbn,omitempty"`
+	// AuthorIDs are the book authors in the order they are credited in. Without them,
+	// the book is linked to the author named like Author.
+	AuthorIDs []int `json:"authorIds,omitempty"`
 }
 
 func (r *BookRequest) Validate() error {
@@ -28,12 +39,30 @@ func (r *BookRequest) Validate() error {
 	if r.Price <= 0 {
 		return fmt.Errorf("%w: price", domain.ErrNegative)
 	}
-	if r.CategoryID == 0 {
+	if r.CategoryID == 0 && len(r.CategoryIDs) == 0 {
 		return fmt.Errorf("%w: category_id", domain.ErrRequired)
 	}
+	if !uniquePositiveIDs(r.CategoryIDs) {
+		return fmt.Errorf("%w: category_ids", domain.ErrInvalidCategoryIDs)
+	}
+	if !uniquePositiveIDs(r.AuthorIDs) {
+		return fmt.Errorf("%w: author_ids", domain.ErrInvalidAuthorIDs)
+	}
 	return nil
 }
 
+// uniquePositiveIDs reports whether the IDs are all positive and distinct.
+func uniquePositiveIDs(ids []int) bool {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
 type BookResponse struct {
 	ID         int    `json:"id"`
 	Title      string `json:"title"`
@@ -42,22 +71,130 @@ type BookResponse struct {
 	Price      int    `json:"price"`
 	Stock      int    `json:"stock"`
 	CategoryID int    `json:"categoryId"`
+	// ISBN is the ISBN-13 of the book, omitted when it is unknown.
+	ISBN        string `json:"isbn,omitempty"`
+	CategoryIDs []int  `json:"categoryIds"`
+	AuthorIDs   []int  `json:"authorIds"`
+	// Cover is omitted when the book has no cover.
+	Cover *BookCoverResponse `json:"cover,omitempty"`
+	// Highlight is only set for books found by a search query.
+	Highlight *BookHighlightResponse `json:"highlight,omitempty"`
+	// DeletedAt is only set for deleted books.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// BookCoverResponse holds the URLs of the cover variants. They never change, a new upload gets new URLs.
+type BookCoverResponse struct {
+	Original string `json:"original"`
+	Medium   string `json:"medium"`
+	Small    string `json:"small"`
+}
+
+// BookHighlightResponse holds the title and author with the matching words wrapped in <mark> tags.
+type BookHighlightResponse struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// BookExportResponse is a line of the NDJSON export.
+type BookExportResponse struct {
+	BookResponse
+	CategoryNames []string  `json:"categoryNames"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// BookImportResponse is the report of a book import.
+type BookImportResponse struct {
+	DryRun   bool `json:"dryRun"`
+	Created  int  `json:"created"`
+	Updated  int  `json:"updated"`
+	Rejected int  `json:"rejected"`
+	// Failed counts the rows of the batch that failed to be written when the import fails.
+	Failed int `json:"failed"`
+	// Rows are ordered by line.
+	Rows []BookImportRowResponse `json:"rows"`
+}
+
+// BookImportRowResponse is the outcome of an imported row.
+type BookImportRowResponse struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	// BookID is omitted for the rejected rows and for the rows a dry run would create.
+	BookID int `json:"bookId,omitempty"`
+	// Error is the reason a row was rejected or failed.
+	Error string `json:"error,omitempty"`
+	// Warning tells what of an updated row was not written: the stock of an existing book is kept.
+	Warning string `json:"warning,omitempty"`
+}
+
+type AuthorRequest struct {
+	Name string `json:"name"`
+}
+
+func (r *AuthorRequest) Validate() error {
+	if domain.NormalizeAuthorName(r.Name) == "" {
+		return fmt.Errorf("%w: name", domain.ErrRequired)
+	}
+	return nil
+}
+
+type AuthorResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
 type CategoryRequest struct {
 	Name string `json:"name"`
+	// ParentID is the parent category, a top-level category has none.
+	ParentID int `json:"parentId,omitempty"`
 }
 
 func (r *CategoryRequest) Validate() error {
 	if r.Name == "" {
 		return fmt.Errorf("%w: name", domain.ErrRequired)
 	}
+	if r.ParentID < 0 {
+		return fmt.Errorf("%w: parent_id", domain.ErrNegative)
+	}
 	return nil
 }
 
 type CategoryResponse struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
+	// ParentID is omitted for top-level categories.
+	ParentID int `json:"parentId,omitempty"`
+	// Stats is only set with ?with_stats=true.
+	Stats *CategoryStatsResponse `json:"stats,omitempty"`
+	// DeletedAt is only set for deleted categories.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// CategoryStatsResponse summarises the books in stock in a category. The prices and the newest
+// book are null when there are none.
+type CategoryStatsResponse struct {
+	InStockBooks int                   `json:"inStockBooks"`
+	MinPrice     *int                  `json:"minPrice"`
+	MaxPrice     *int                  `json:"maxPrice"`
+	NewestBook   *CategoryBookResponse `json:"newestBook"`
+}
+
+type CategoryBookResponse struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// CategoryTreeResponse is a category with its subcategories.
+type CategoryTreeResponse struct {
+	ID       int                    `json:"id"`
+	Name     string                 `json:"name"`
+	Children []CategoryTreeResponse `json:"children"`
+}
+
+// CategoryNotEmptyResponse holds the details of a category-not-empty error.
+type CategoryNotEmptyResponse struct {
+	// Books is the number of books in the category.
+	Books int `json:"books"`
 }
 
 type AuthRequest struct {
@@ -75,10 +212,119 @@ func (r *AuthRequest) Validate() error {
 	return nil
 }
 
+type CartItemRequest struct {
+	BookID   int `json:"bookId"`
+	Quantity int `json:"quantity"`
+}
+
+// CartRequest is either a list of items, e.g. [{"bookId": 1, "quantity": 2}],
+// or the legacy object form {"bookIds": [1, 1]}, where every ID adds one copy.
 type CartRequest struct {
-	BookIDs []int `json:"bookIds"`
+	Items   []CartItemRequest `json:"items,omitempty"`
+	BookIDs []int             `json:"bookIds,omitempty"`
+}
+
+func (r *CartRequest) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		r.BookIDs = nil
+		return json.Unmarshal(trimmed, &r.Items)
+	}
+
+	type cartRequest CartRequest
+	return json.Unmarshal(data, (*cartRequest)(r))
+}
+
+func (r *CartRequest) Validate() error {
+	for _, item := range r.Items {
+		if item.BookID <= 0 {
+			return fmt.Errorf("%w: bookId", domain.ErrInvalidBookIDs)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("%w: quantity", domain.ErrInvalidQuantity)
+		}
+	}
+	for _, bookID := range r.BookIDs {
+		if bookID <= 0 {
+			return fmt.Errorf("%w: bookIds", domain.ErrInvalidBookIDs)
+		}
+	}
+	return nil
+}
+
+type CartItemQuantityRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+func (r *CartItemQuantityRequest) Validate() error {
+	if r.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity", domain.ErrInvalidQuantity)
+	}
+	return nil
+}
+
+type CartItemResponse struct {
+	BookID   int    `json:"bookId"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Year     int    `json:"year"`
+	Price    int    `json:"price"`
+	Quantity int    `json:"quantity"`
+	Total    int    `json:"total"`
 }
 
 type CartResponse struct {
+	Items    []CartItemResponse `json:"items"`
+	Subtotal int                `json:"subtotal"`
+	// ExpiresAt is when the reserved books are released, unless the cart is updated before.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// BookIDs is kept for clients of the legacy form.
 	BookIDs []int `json:"bookIds"`
 }
+
+// CheckoutRequest carries the card token issued by the payment provider.
+type CheckoutRequest struct {
+	PaymentToken string `json:"paymentToken"`
+}
+
+type OrderItemResponse struct {
+	BookID   int    `json:"bookId"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	Price    int    `json:"price"`
+	Quantity int    `json:"quantity"`
+}
+
+type OrderResponse struct {
+	ID        int                 `json:"id"`
+	Status    string              `json:"status"`
+	Total     int                 `json:"total"`
+	Items     []OrderItemResponse `json:"items"`
+	CreatedAt time.Time           `json:"createdAt"`
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (r *OrderStatusRequest) Validate() error {
+	if r.Status == "" {
+		return fmt.Errorf("%w: status", domain.ErrRequired)
+	}
+	if !domain.OrderStatus(r.Status).Valid() {
+		return fmt.Errorf("%w: status", domain.ErrInvalidOrderStatus)
+	}
+	return nil
+}
+
+// AuditEventResponse is a change to a book or category, with the entity as JSON before and after it.
+type AuditEventResponse struct {
+	ID int64 `json:"id"`
+	// ActorID is left out once the admin who made the change is deleted.
+	ActorID   int             `json:"actorId,omitempty"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entityId"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
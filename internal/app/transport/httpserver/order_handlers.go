@@ -0,0 +1,267 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/server"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/gorilla/mux"
+)
+
+// @Summary GetOrders
+// @Security ApiKeyAuth
+// @Tags order
+// @Description get orders of the current user, newest first
+// @ID get-orders
+// @Accept  json
+// @Produce  json
+// @Param page query int false "page number"
+// @Success 200 {array} OrderResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /orders [get]
+func (h HTTPServer) GetOrders(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	limit, offset := pageToLimitOffset(r, ordersPageSize)
+
+	orders, err := h.orderService.GetOrders(r.Context(), domain.OrderFilter{UserID: user.ID}, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		response = append(response, toResponseOrder(order))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary GetOrder
+// @Security ApiKeyAuth
+// @Tags order
+// @Description get order of the current user by ID
+// @ID get-order
+// @Accept  json
+// @Produce  json
+// @Param order_id path int true "order ID"
+// @Success 200 {object} OrderResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /orders/{order_id} [get]
+func (h HTTPServer) GetOrder(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	orderID, err := strconv.Atoi(vars["order_id"])
+	if err != nil {
+		server.BadRequest("invalid-order-id", err, w, r)
+		return
+	}
+
+	order, err := h.orderService.GetUserOrder(r.Context(), user.ID, orderID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("order-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary GetAdminOrders
+// @Security ApiKeyAuth
+// @Tags order
+// @Description get orders of all users, newest first
+// @ID get-admin-orders
+// @Accept  json
+// @Produce  json
+// @Param user_id query int false "user ID"
+// @Param status query string false "order status"
+// @Param from query string false "created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "created before (RFC 3339 or YYYY-MM-DD, inclusive day)"
+// @Param page query int false "page number"
+// @Success 200 {array} OrderResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /admin/orders [get]
+func (h HTTPServer) GetAdminOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var filter domain.OrderFilter
+	if userID := query.Get("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil {
+			server.BadRequest("invalid-user-id", err, w, r)
+			return
+		}
+		filter.UserID = id
+	}
+	if status := query.Get("status"); status != "" {
+		filter.Status = domain.OrderStatus(status)
+		if !filter.Status.Valid() {
+			server.BadRequest("invalid-order-status", nil, w, r)
+			return
+		}
+	}
+	from, err := parseTimeParam(query.Get("from"), false)
+	if err != nil {
+		server.BadRequest("invalid-from", err, w, r)
+		return
+	}
+	filter.From = from
+	to, err := parseTimeParam(query.Get("to"), true)
+	if err != nil {
+		server.BadRequest("invalid-to", err, w, r)
+		return
+	}
+	filter.To = to
+
+	limit, offset := pageToLimitOffset(r, ordersPageSize)
+
+	orders, err := h.orderService.GetOrders(r.Context(), filter, limit, offset)
+	if err != nil {
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		response = append(response, toResponseOrder(order))
+	}
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary UpdateOrderStatus
+// @Security ApiKeyAuth
+// @Tags order
+// @Description move an order to another status
+// @ID update-order-status
+// @Accept  json
+// @Produce  json
+// @Param order_id path int true "order ID"
+// @Param input body OrderStatusRequest true "new status"
+// @Success 200 {object} OrderResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /admin/orders/{order_id}/status [patch]
+func (h HTTPServer) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	orderID, err := strconv.Atoi(vars["order_id"])
+	if err != nil {
+		server.BadRequest("invalid-order-id", err, w, r)
+		return
+	}
+
+	var statusRequest OrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&statusRequest); err != nil {
+		server.BadRequest("invalid-json", err, w, r)
+		return
+	}
+
+	if err := statusRequest.Validate(); err != nil {
+		server.BadRequest("invalid-request", err, w, r)
+		return
+	}
+
+	order, err := h.orderService.UpdateOrderStatus(r.Context(), orderID, domain.OrderStatus(statusRequest.Status))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("order-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
+}
+
+// @Summary CancelOrder
+// @Security ApiKeyAuth
+// @Tags order
+// @Description cancel an order of the current user
+// @ID cancel-order
+// @Accept  json
+// @Produce  json
+// @Param order_id path int true "order ID"
+// @Success 200 {object} OrderResponse
+// @Failure 400,404 {object} server.ErrorResponse
+// @Failure 401 {object} server.ErrorResponse
+// @Failure 409 {object} server.ErrorResponse
+// @Failure 500 {object} server.ErrorResponse
+// @Router /orders/{order_id}/cancel [post]
+func (h HTTPServer) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	user, err := getUserFromContext(r.Context())
+	if err != nil {
+		server.BadRequest("invalid-user", err, w, r)
+		return
+	}
+
+	vars := mux.Vars(r)
+	orderID, err := strconv.Atoi(vars["order_id"])
+	if err != nil {
+		server.BadRequest("invalid-order-id", err, w, r)
+		return
+	}
+
+	order, err := h.orderService.CancelUserOrder(r.Context(), user.ID, orderID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			server.NotFound("order-not-found", err, w, r)
+			return
+		}
+		server.RespondWithError(err, w, r)
+		return
+	}
+
+	response := toResponseOrder(order)
+
+	server.RespondOK(response, w, r)
+}
+
+// parseTimeParam parses a RFC 3339 timestamp or a YYYY-MM-DD date. When endOfDay is set,
+// a bare date is moved to the start of the next day, so it can be used as an exclusive upper bound.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
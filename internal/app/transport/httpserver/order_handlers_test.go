@@ -0,0 +1,195 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cronnoss/bookshop-home-task/internal/app/common/slugerrors"
+	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/transport/httpserver/mocks"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrder(t *testing.T, id, userID int) domain.Order {
+	t.Helper()
+
+	item, err := domain.NewOrderItem(domain.NewOrderItemData{
+		BookID:   1,
+		Title:    "The history of Golang",
+		Author:   "Rob Pike",
+		Price:    1000,
+		Quantity: 1,
+	})
+	require.NoError(t, err)
+
+	order, err := domain.NewOrder(domain.NewOrderData{
+		ID:        id,
+		UserID:    userID,
+		Status:    domain.OrderStatusPaid,
+		Items:     []domain.OrderItem{item},
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	return order
+}
+
+func TestGetOrders_Success(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	orderServiceMock.On("GetOrders", mock.Anything, domain.OrderFilter{UserID: 1}, 10, 10).
+		Return([]domain.Order{newTestOrder(t, 1, 1)}, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/orders?page=2", nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	httpServer.GetOrders(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response []OrderResponse
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 1)
+	assert.Equal(t, 1000, response[0].Total)
+	assert.Equal(t, "Rob Pike", response[0].Items[0].Author)
+}
+
+func TestGetOrder_NotFound(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	orderServiceMock.On("GetUserOrder", mock.Anything, 1, 5).Return(domain.Order{}, domain.ErrNotFound)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/orders/5", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"order_id": "5"})
+
+	rr := httptest.NewRecorder()
+
+	httpServer.GetOrder(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "order-not-found")
+}
+
+func TestGetOrder_InvalidID(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/orders/invalid", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"order_id": "invalid"})
+
+	rr := httptest.NewRecorder()
+
+	httpServer.GetOrder(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	orderServiceMock.AssertNumberOfCalls(t, "GetUserOrder", 0)
+}
+
+func TestGetAdminOrders_Filters(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	expectedFilter := domain.OrderFilter{
+		UserID: 3,
+		Status: domain.OrderStatusPaid,
+		From:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	orderServiceMock.On("GetOrders", mock.Anything, expectedFilter, 10, 0).Return([]domain.Order{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/admin/orders?user_id=3&status=paid&from=2024-01-01&to=2024-01-31", nil)
+	rr := httptest.NewRecorder()
+
+	httpServer.GetAdminOrders(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestGetAdminOrders_InvalidStatus(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/orders?status=unknown", nil)
+	rr := httptest.NewRecorder()
+
+	httpServer.GetAdminOrders(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	orderServiceMock.AssertNumberOfCalls(t, "GetOrders", 0)
+}
+
+func TestUpdateOrderStatus_Conflict(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	orderServiceMock.On("UpdateOrderStatus", mock.Anything, 1, domain.OrderStatusPending).
+		Return(domain.Order{}, slugerrors.NewConflictError("invalid transition", "invalid-order-status-transition"))
+
+	req := httptest.NewRequest(http.MethodPatch, "/admin/orders/1/status",
+		bytes.NewBufferString(`{"status": "pending"}`))
+	req = mux.SetURLVars(req, map[string]string{"order_id": "1"})
+	rr := httptest.NewRecorder()
+
+	httpServer.UpdateOrderStatus(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid-order-status-transition")
+}
+
+func TestUpdateOrderStatus_InvalidStatus(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/admin/orders/1/status",
+		bytes.NewBufferString(`{"status": "shipped"}`))
+	req = mux.SetURLVars(req, map[string]string{"order_id": "1"})
+	rr := httptest.NewRecorder()
+
+	httpServer.UpdateOrderStatus(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	orderServiceMock.AssertNumberOfCalls(t, "UpdateOrderStatus", 0)
+}
+
+func TestCancelOrder_Success(t *testing.T) {
+	orderServiceMock := mocks.NewOrderService(t)
+	httpServer := NewHTTPServer(nil, nil, nil, nil, nil, orderServiceMock, nil, nil, nil)
+
+	cancelled, err := newTestOrder(t, 1, 1).WithStatus(domain.OrderStatusCancelled)
+	require.NoError(t, err)
+	orderServiceMock.On("CancelUserOrder", mock.Anything, 1, 1).Return(cancelled, nil)
+
+	ctx := context.WithValue(context.Background(), ContextUserKey, domain.User{ID: 1, Username: "user"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/orders/1/cancel", nil)
+	require.NoError(t, err)
+	req = mux.SetURLVars(req, map[string]string{"order_id": "1"})
+	rr := httptest.NewRecorder()
+
+	httpServer.CancelOrder(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response OrderResponse
+	err = json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", response.Status)
+}
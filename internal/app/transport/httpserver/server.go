@@ -2,11 +2,15 @@ package httpserver
 
 // HTTPServer is a HTTP server for ports.
 type HTTPServer struct {
-	userService     UserService
-	tokenService    TokenService
-	bookService     BookService
-	categoryService CategoryService
-	cartService     CartService
+	userService        UserService
+	tokenService       TokenService
+	bookService        BookService
+	categoryService    CategoryService
+	cartService        CartService
+	orderService       OrderService
+	idempotencyService IdempotencyService
+	authorService      AuthorService
+	auditService       AuditService
 }
 
 // NewHTTPServer creates a new HTTP server for ports.
@@ -15,12 +19,20 @@ func NewHTTPServer(userService UserService,
 	bookService BookService,
 	categoryService CategoryService,
 	cartService CartService,
+	orderService OrderService,
+	idempotencyService IdempotencyService,
+	authorService AuthorService,
+	auditService AuditService,
 ) HTTPServer {
 	return HTTPServer{
-		userService:     userService,
-		tokenService:    tokenService,
-		bookService:     bookService,
-		categoryService: categoryService,
-		cartService:     cartService,
+		userService:        userService,
+		tokenService:       tokenService,
+		bookService:        bookService,
+		categoryService:    categoryService,
+		cartService:        cartService,
+		orderService:       orderService,
+		idempotencyService: idempotencyService,
+		authorService:      authorService,
+		auditService:       auditService,
 	}
 }
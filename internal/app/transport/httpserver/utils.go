@@ -2,37 +2,126 @@ package httpserver
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
 )
 
 func toResponseBook(book domain.Book) BookResponse {
-	return BookResponse{
-		ID:         book.ID(),
-		Title:      book.Title(),
-		Year:       book.Year(),
-		Author:     book.Author(),
-		Price:      book.Price(),
-		Stock:      book.Stock(),
-		CategoryID: book.CategoryID(),
+	response := BookResponse{
+		ID:          book.ID(),
+		Title:       book.Title(),
+		Year:        book.Year(),
+		Author:      book.Author(),
+		Price:       book.Price(),
+		Stock:       book.Stock(),
+		CategoryID:  book.CategoryID(),
+		ISBN:        book.ISBN(),
+		CategoryIDs: book.CategoryIDs(),
+		AuthorIDs:   book.AuthorIDs(),
+	}
+	if response.CategoryIDs == nil {
+		response.CategoryIDs = []int{}
+	}
+	if response.AuthorIDs == nil {
+		response.AuthorIDs = []int{}
+	}
+	if key := book.CoverKey(); key != "" {
+		response.Cover = &BookCoverResponse{
+			Original: coverURL(key, domain.CoverSizeOriginal),
+			Medium:   coverURL(key, domain.CoverSizeMedium),
+			Small:    coverURL(key, domain.CoverSizeSmall),
+		}
+	}
+	if highlight := book.Highlight(); highlight != (domain.BookHighlight{}) {
+		response.Highlight = &BookHighlightResponse{
+			Title:  highlight.Title,
+			Author: highlight.Author,
+		}
+	}
+	if deletedAt := book.DeletedAt(); !deletedAt.IsZero() {
+		response.DeletedAt = &deletedAt
+	}
+
+	return response
+}
+
+func toResponseExportedBook(book domain.ExportedBook) BookExportResponse {
+	response := BookExportResponse{
+		BookResponse:  toResponseBook(book.Book),
+		CategoryNames: book.CategoryNames,
+		CreatedAt:     book.CreatedAt(),
+	}
+	if response.CategoryNames == nil {
+		response.CategoryNames = []string{}
+	}
+	return response
+}
+
+// coverURL is the GET /covers/{key} URL of a cover variant.
+func coverURL(key string, size domain.CoverSize) string {
+	return "/covers/" + domain.CoverVariantKey(key, size)
+}
+
+func toResponseAuthor(author domain.Author) AuthorResponse {
+	return AuthorResponse{
+		ID:   author.ID(),
+		Name: author.Name(),
 	}
 }
 
 func toResponseCategory(category domain.Category) CategoryResponse {
-	return CategoryResponse{
-		ID:   category.ID(),
-		Name: category.Name(),
+	response := CategoryResponse{
+		ID:       category.ID(),
+		Name:     category.Name(),
+		ParentID: category.ParentID(),
+	}
+	if deletedAt := category.DeletedAt(); !deletedAt.IsZero() {
+		response.DeletedAt = &deletedAt
+	}
+	if stats := category.Stats(); stats != nil {
+		response.Stats = &CategoryStatsResponse{
+			InStockBooks: stats.InStockBooks,
+		}
+		if stats.InStockBooks > 0 {
+			response.Stats.MinPrice, response.Stats.MaxPrice = &stats.MinPrice, &stats.MaxPrice
+		}
+		if stats.NewestBook != nil {
+			response.Stats.NewestBook = &CategoryBookResponse{
+				ID:    stats.NewestBook.ID,
+				Title: stats.NewestBook.Title,
+			}
+		}
 	}
+
+	return response
+}
+
+func toResponseCategoryTree(nodes []domain.CategoryNode) []CategoryTreeResponse {
+	response := make([]CategoryTreeResponse, 0, len(nodes))
+	for _, node := range nodes {
+		response = append(response, CategoryTreeResponse{
+			ID:       node.ID(),
+			Name:     node.Name(),
+			Children: toResponseCategoryTree(node.Children),
+		})
+	}
+	return response
 }
 
 func toDomainBook(bookRequest BookRequest) (domain.Book, error) {
 	return domain.NewBook(domain.NewBookData{
-		Title:      bookRequest.Title,
-		Year:       bookRequest.Year,
-		Author:     bookRequest.Author,
-		Price:      bookRequest.Price,
-		Stock:      bookRequest.Stock,
-		CategoryID: bookRequest.CategoryID,
+		Title:       bookRequest.Title,
+		Year:        bookRequest.Year,
+		Author:      bookRequest.Author,
+		Price:       bookRequest.Price,
+		Stock:       bookRequest.Stock,
+		CategoryID:  bookRequest.CategoryID,
+		CategoryIDs: bookRequest.CategoryIDs,
+		ISBN:        bookRequest.ISBN,
+		AuthorIDs:   bookRequest.AuthorIDs,
 	})
 }
 
@@ -44,16 +133,139 @@ func toDomainUser(username, password string) (domain.User, error) {
 }
 
 func toDomainCart(userID int, cartRequest CartRequest) (domain.Cart, error) {
+	items := make([]domain.CartItem, 0, len(cartRequest.Items))
+	for _, item := range cartRequest.Items {
+		domainItem, err := domain.NewCartItem(domain.NewCartItemData{
+			BookID:   item.BookID,
+			Quantity: item.Quantity,
+		})
+		if err != nil {
+			return domain.Cart{}, err
+		}
+		items = append(items, domainItem)
+	}
+
 	return domain.NewCart(domain.NewCartData{
 		UserID:  userID,
+		Items:   items,
 		BookIDs: cartRequest.BookIDs,
 	})
 }
 
 func toResponseCart(cart domain.Cart) CartResponse {
-	return CartResponse{
-		BookIDs: cart.BookIDs(),
+	items := make([]CartItemResponse, 0, len(cart.Items()))
+	bookIDs := []int{}
+	for _, item := range cart.Items() {
+		items = append(items, CartItemResponse{
+			BookID:   item.BookID(),
+			Title:    item.Book().Title(),
+			Author:   item.Book().Author(),
+			Year:     item.Book().Year(),
+			Price:    item.Book().Price(),
+			Quantity: item.Quantity(),
+			Total:    item.Total(),
+		})
+		for range item.Quantity() {
+			bookIDs = append(bookIDs, item.BookID())
+		}
+	}
+
+	response := CartResponse{
+		Items:    items,
+		Subtotal: cart.Subtotal(),
+		BookIDs:  bookIDs,
+	}
+	if expiresAt := cart.ExpiresAt(); !expiresAt.IsZero() {
+		response.ExpiresAt = &expiresAt
+	}
+
+	return response
+}
+
+func toResponseOrder(order domain.Order) OrderResponse {
+	items := make([]OrderItemResponse, 0, len(order.Items()))
+	for _, item := range order.Items() {
+		items = append(items, OrderItemResponse{
+			BookID:   item.BookID(),
+			Title:    item.Title(),
+			Author:   item.Author(),
+			Price:    item.Price(),
+			Quantity: item.Quantity(),
+		})
+	}
+
+	return OrderResponse{
+		ID:        order.ID(),
+		Status:    string(order.Status()),
+		Total:     order.Total(),
+		Items:     items,
+		CreatedAt: order.CreatedAt(),
+	}
+}
+
+func toResponseAuditEvent(event domain.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:        event.ID(),
+		ActorID:   event.ActorID(),
+		Entity:    string(event.Entity()),
+		EntityID:  event.EntityID(),
+		Action:    string(event.Action()),
+		Before:    event.Before(),
+		After:     event.After(),
+		CreatedAt: event.CreatedAt(),
+	}
+}
+
+const (
+	booksPageSize  = 10
+	ordersPageSize = 10
+	auditPageSize  = 20
+	// maxBooksPageSize caps the "limit" query parameter of the book listing.
+	maxBooksPageSize = 100
+)
+
+const (
+	// NextCursorHeader carries the cursor of the next page of a listing.
+	NextCursorHeader = "X-Next-Cursor"
+	// TotalCountHeader carries the number of items in a listing across all pages.
+	TotalCountHeader = "X-Total-Count"
+)
+
+// pageToLimitOffset converts the "page" query parameter to limit and offset.
+// A missing or invalid page means the first page.
+func pageToLimitOffset(r *http.Request, pageSize int) (limit, offset int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil {
+		page = 1
+	}
+	if page > 0 {
+		limit = pageSize
+		offset = (page - 1) * limit
+	}
+	return limit, offset
+}
+
+// parseLimitParam reads a page size between 1 and maxLimit, defaultLimit when the value is empty.
+func parseLimitParam(value string, defaultLimit, maxLimit int) (int, error) {
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit: %w", err)
+	}
+	if limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidRange, maxLimit)
+	}
+	return limit, nil
+}
+
+// parseBoolParam reads an optional boolean query parameter, false when the value is empty.
+func parseBoolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
 	}
+	return strconv.ParseBool(value)
 }
 
 func getUserFromContext(ctx context.Context) (domain.User, error) {
@@ -0,0 +1,118 @@
+package pg
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/uptrace/bun"
+)
+
+// ErrLockNotAcquired is returned when the advisory lock is held by another session.
+var ErrLockNotAcquired = errors.New("advisory lock is held by another session")
+
+// LockKey turns a lock name into a key for the Postgres advisory lock functions.
+func LockKey(name string) int64 {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	return int64(hash.Sum32())
+}
+
+// LeaderLock is a session-level advisory lock kept on a dedicated connection, so that one of several
+// replicas stays the leader for as long as its connection lives instead of racing for the lock on
+// every run of a job.
+type LeaderLock struct {
+	db  *DB
+	key int64
+
+	mu   sync.Mutex
+	conn *bun.Conn
+}
+
+// NewLeaderLock creates a leader lock with the given key. The lock is not taken until Hold is called.
+func NewLeaderLock(db *DB, key int64) *LeaderLock {
+	return &LeaderLock{
+		db:  db,
+		key: key,
+	}
+}
+
+// Hold makes sure the lock is held and returns ErrLockNotAcquired when another session holds it.
+// It doesn't wait for the lock. Call it before every run of the job: when the connection holding
+// the lock has dropped, Postgres has released the lock with it, so it is taken again on a new
+// connection, unless another replica took it over in the meantime.
+func (l *LeaderLock) Hold(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		held, err := l.held(ctx)
+		if err == nil && held {
+			return nil
+		}
+		if err != nil {
+			// the connection is gone and the lock with it, keep it out of the pool
+			_ = l.conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection: %w", err)
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", l.key).Scan(&acquired)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		_ = conn.Close()
+		return ErrLockNotAcquired
+	}
+
+	l.conn = &conn
+	return nil
+}
+
+// held checks on the dedicated connection that its session still holds the lock.
+func (l *LeaderLock) held(ctx context.Context) (bool, error) {
+	// a bigint key is split into classid and objid, objsubid 1 marks a bigint key
+	var held bool
+	err := l.conn.QueryRowContext(ctx, `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted
+			AND classid = ? AND objid = ? AND objsubid = 1
+	)`, l.key>>32, l.key&0xffffffff).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("failed to check advisory lock: %w", err)
+	}
+	return held, nil
+}
+
+// Release unlocks the lock and closes its connection, so that another replica can take over.
+// It does nothing when the lock is not held.
+func (l *LeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	defer func() {
+		_ = l.conn.Close()
+		l.conn = nil
+	}()
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", l.key)
+	if err != nil {
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+	return nil
+}
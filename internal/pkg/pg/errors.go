@@ -0,0 +1,32 @@
+package pg
+
+import (
+	"errors"
+
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+const (
+	// foreignKeyViolation is the SQLSTATE of a foreign key constraint violation.
+	foreignKeyViolation = "23503"
+	// uniqueViolation is the SQLSTATE of a unique constraint violation.
+	uniqueViolation = "23505"
+)
+
+// IsUniqueViolation reports whether the error is a violation of the named unique constraint or index.
+func IsUniqueViolation(err error, constraint string) bool {
+	return isViolation(err, uniqueViolation, constraint)
+}
+
+// IsForeignKeyViolation reports whether the error is a violation of the named foreign key constraint.
+func IsForeignKeyViolation(err error, constraint string) bool {
+	return isViolation(err, foreignKeyViolation, constraint)
+}
+
+func isViolation(err error, code, constraint string) bool {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Field('C') == code && pgErr.Field('n') == constraint
+}
@@ -13,3 +13,10 @@ func TestDial_EmptyDSN(t *testing.T) {
 	assert.Nil(t, db)
 	assert.Equal(t, "no postgres DSN provided", err.Error())
 }
+
+func TestLockKey(t *testing.T) {
+	assert.Equal(t, LockKey("cart-sweeper"), LockKey("cart-sweeper"))
+	assert.NotEqual(t, LockKey("cart-sweeper"), LockKey("order-sweeper"))
+	// the key fits in pg_locks.objid, which is how LeaderLock finds it
+	assert.Zero(t, LockKey("cart-sweeper")>>32)
+}
@@ -13,7 +13,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/cronnoss/bookshop-home-task/internal/app/blobstore"
+	"github.com/cronnoss/bookshop-home-task/internal/app/payment"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/pgrepo"
 	servise "github.com/cronnoss/bookshop-home-task/internal/app/services"
@@ -64,6 +67,8 @@ func (s *HTTPTestSuite) TearDownTest1() {
 	require.NoError(s.t, err)
 	_, err = s.db.NewTruncateTable().Model((*models.Category)(nil)).Exec(context.Background())
 	require.NoError(s.t, err)
+	_, err = s.db.NewTruncateTable().Model((*models.CartItem)(nil)).Exec(context.Background())
+	require.NoError(s.t, err)
 	_, err = s.db.NewTruncateTable().Model((*models.Cart)(nil)).Exec(context.Background())
 	require.NoError(s.t, err)
 }
@@ -99,9 +104,12 @@ func (s *HTTPTestSuite) TestCreateBookHttp_Success(t *testing.T) {
 
 	s.userService = servise.NewUserService(pgrepo.NewUserRepo(&pg.DB{DB: s.db}))
 	s.tokenService = servise.NewTokenService(15)
-	s.bookService = servise.NewBookService(pgrepo.NewBookRepo(&pg.DB{DB: s.db}))
+	s.bookService = servise.NewBookService(pgrepo.NewBookRepo(&pg.DB{DB: s.db}), blobstore.NewMemoryStore(),
+		[]byte("cursor-secret"))
 	s.categoryService = servise.NewCategoryService(pgrepo.NewCategoryRepo(&pg.DB{DB: s.db}))
-	s.cartService = servise.NewCartService(pgrepo.NewCartRepo(&pg.DB{DB: s.db}))
+	paymentGateway := payment.NewFakeGateway()
+	s.cartService = servise.NewCartService(pgrepo.NewCartRepo(&pg.DB{DB: s.db}),
+		pgrepo.NewOrderRepo(&pg.DB{DB: s.db}), paymentGateway, time.Minute)
 
 	// create http server with application injected
 	s.httpServer = httpserver.NewHTTPServer(
@@ -110,6 +118,10 @@ func (s *HTTPTestSuite) TestCreateBookHttp_Success(t *testing.T) {
 		s.bookService,
 		s.categoryService,
 		s.cartService,
+		servise.NewOrderService(pgrepo.NewOrderRepo(&pg.DB{DB: s.db}), paymentGateway),
+		servise.NewIdempotencyService(pgrepo.NewIdempotencyRepo(&pg.DB{DB: s.db}), time.Hour),
+		servise.NewAuthorService(pgrepo.NewAuthorRepo(&pg.DB{DB: s.db})),
+		servise.NewAuditService(pgrepo.NewAuditRepo(&pg.DB{DB: s.db})),
 	)
 
 	// 1. create POST /signup request
@@ -197,6 +209,11 @@ func (s *HTTPTestSuite) TestCreateBookHttp_Success(t *testing.T) {
 	req = httptest.NewRequest(http.MethodPost, "/book", bytes.NewBuffer(newBookRequest))
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
+	// the handler runs without the auth middleware, so put the user in the context like it does
+	user, err := s.tokenService.GetUser(token)
+	require.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), httpserver.ContextUserKey, user))
+
 	// create http recorder
 	w = httptest.NewRecorder()
 
@@ -250,10 +267,41 @@ func createSchema1(ctx context.Context, db *bun.DB) error {
 	if err != nil {
 		return fmt.Errorf("failed to create books table: %w", err)
 	}
+	_, err = db.ExecContext(ctx, `ALTER TABLE books ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(author, '')), 'B')) STORED`)
+	if err != nil {
+		return fmt.Errorf("failed to add search vector to books table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to add unique index to books table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.Author)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create authors table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE UNIQUE INDEX authors_name_key ON authors (lower(name))`)
+	if err != nil {
+		return fmt.Errorf("failed to add unique index to authors table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.BookAuthor)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create book authors table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `ALTER TABLE book_authors ADD CONSTRAINT book_authors_author_id_fkey
+		FOREIGN KEY (author_id) REFERENCES authors (id) ON DELETE CASCADE`)
+	if err != nil {
+		return fmt.Errorf("failed to add foreign key to book authors table: %w", err)
+	}
 	_, err = db.NewCreateTable().Model((*models.Category)(nil)).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create categories table: %w", err)
 	}
+	_, err = db.NewCreateTable().Model((*models.BookCategory)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create book categories table: %w", err)
+	}
 	_, err = db.NewCreateTable().Model((*models.Cart)(nil)).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create carts table: %w", err)
@@ -262,6 +310,14 @@ func createSchema1(ctx context.Context, db *bun.DB) error {
 	if err != nil {
 		return fmt.Errorf("failed to add unique constraint to carts table: %w", err)
 	}
+	_, err = db.NewCreateTable().Model((*models.CartItem)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create cart items table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.AuditEvent)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create audit events table: %w", err)
+	}
 	return nil
 }
 
@@ -5,14 +5,19 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cronnoss/bookshop-home-task/internal/app/domain"
+	"github.com/cronnoss/bookshop-home-task/internal/app/payment"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/models"
 	"github.com/cronnoss/bookshop-home-task/internal/app/repository/pgrepo"
+	"github.com/cronnoss/bookshop-home-task/internal/app/services"
 	"github.com/cronnoss/bookshop-home-task/internal/pkg/pg"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +33,8 @@ import (
 const (
 	dbUser     = "user"
 	dbPassword = "password"
+	// testActorID is the admin the changes are recorded under in the audit trail.
+	testActorID = 1
 )
 
 type Book struct {
@@ -70,6 +77,8 @@ func (s *IntegrationSuite) TearDownTest() {
 	require.NoError(s.t, err)
 	_, err = s.db.NewTruncateTable().Model((*models.Category)(nil)).Exec(context.Background())
 	require.NoError(s.t, err)
+	_, err = s.db.NewTruncateTable().Model((*models.CartItem)(nil)).Exec(context.Background())
+	require.NoError(s.t, err)
 	_, err = s.db.NewTruncateTable().Model((*models.Cart)(nil)).Exec(context.Background())
 	require.NoError(s.t, err)
 }
@@ -106,24 +115,60 @@ func TestIntegrationSuite(t *testing.T) {
 		t.Run("TestGetBook_NotFound", suite.TestGetBook_NotFound)
 		t.Run("TestUpdateBook_Success", suite.TestUpdateBook_Success)
 		t.Run("TestDeleteBook_Success", suite.TestDeleteBook_Success)
+		t.Run("TestGetBookByISBN_Success", suite.TestGetBookByISBN_Success)
+		t.Run("TestCreateBook_ISBNConflict", suite.TestCreateBook_ISBNConflict)
 		t.Run("TestGetBooks_Success", suite.TestGetBooks_Success)
+		t.Run("TestGetBooks_Search", suite.TestGetBooks_Search)
+		t.Run("TestGetBooks_Filter", suite.TestGetBooks_Filter)
+		t.Run("TestGetBooks_Cursor", suite.TestGetBooks_Cursor)
+		t.Run("TestGetBooks_MultipleCategories", suite.TestGetBooks_MultipleCategories)
+		t.Run("TestUpdateBookCover", suite.TestUpdateBookCover)
+		t.Run("TestImportBooks", suite.TestImportBooks)
+		t.Run("TestExportBooks", suite.TestExportBooks)
 		// CategoryRepo tests
 		t.Run("TestCreateCategory_Success", suite.TestCreateCategory_Success)
 		t.Run("TestGetCategory_NotFound", suite.TestGetCategory_NotFound)
 		t.Run("TestUpdateCategory_Success", suite.TestUpdateCategory_Success)
 		t.Run("TestDeleteCategory_Success", suite.TestDeleteCategory_Success)
+		t.Run("TestDeleteCategory_NotEmpty", suite.TestDeleteCategory_NotEmpty)
 		t.Run("TestGetCategories_Success", suite.TestGetCategories_Success)
+		t.Run("TestGetCategories_WithStats", suite.TestGetCategories_WithStats)
+		t.Run("TestCategoryTree", suite.TestCategoryTree)
+		t.Run("TestSoftDelete", suite.TestSoftDelete)
+		// AuditRepo tests
+		t.Run("TestAuditEvents", suite.TestAuditEvents)
+		// AuthorRepo tests
+		t.Run("TestCreateAuthor_Conflict", suite.TestCreateAuthor_Conflict)
+		t.Run("TestCreateBook_LinksAuthors", suite.TestCreateBook_LinksAuthors)
+		t.Run("TestRenameAndMergeAuthors", suite.TestRenameAndMergeAuthors)
 		// CartRepo tests
 		t.Run("TestGetCart_Success", suite.TestGetCart_Success)
 		t.Run("TestGetCart_NotFound", suite.TestGetCart_NotFound)
 		t.Run("TestUpdateCartAndStocks_Success", suite.TestUpdateCartAndStocks_Success)
+		t.Run("TestUpdateCartAndStocks_QuantityDelta", suite.TestUpdateCartAndStocks_QuantityDelta)
+		t.Run("TestUpdateCartAndStocks_ConcurrentFirstWrite", suite.TestUpdateCartAndStocks_ConcurrentFirstWrite)
+		t.Run("TestUpdateCartItem_Success", suite.TestUpdateCartItem_Success)
+		t.Run("TestClearCart_Success", suite.TestClearCart_Success)
+		t.Run("TestCleanExpiredCarts_Success", suite.TestCleanExpiredCarts_Success)
 		t.Run("TestCheckStocks_Success", suite.TestCheckStocks_Success)
 		t.Run("TestDeleteCart_Success", suite.TestDeleteCart_Success)
+		// OrderRepo tests
+		t.Run("TestCreateOrderFromCart_Success", suite.TestCreateOrderFromCart_Success)
+		t.Run("TestCreateOrderFromCart_EmptyCart", suite.TestCreateOrderFromCart_EmptyCart)
+		t.Run("TestCreateOrderFromCart_PaymentFails", suite.TestCreateOrderFromCart_PaymentFails)
+		t.Run("TestUpdateOrderStatus_CancelRestoresStock", suite.TestUpdateOrderStatus_CancelRestoresStock)
+		t.Run("TestCheckout_CaptureDeclineVoidsPayment", suite.TestCheckout_CaptureDeclineVoidsPayment)
+		// IdempotencyRepo tests
+		t.Run("TestTakeOverIdempotencyKey", suite.TestTakeOverIdempotencyKey)
+		t.Run("TestIdempotencyKey_Owner", suite.TestIdempotencyKey_Owner)
 		// HandleBunTransaction tests
 		t.Run("TestHandleBunTransaction_Success", suite.TestHandleBunTransaction_Success)
 		t.Run("TestHandleBunTransaction_FailBegin", suite.TestHandleBunTransaction_FailBegin)
 		t.Run("TestHandleBunTransaction_FailCommit", suite.TestHandleBunTransaction_FailCommit)
 		t.Run("TestHandleBunTransaction_FailRollback", suite.TestHandleBunTransaction_FailRollback)
+		// Advisory lock tests
+		t.Run("TestLeaderLock_Contention", suite.TestLeaderLock_Contention)
+		t.Run("TestLeaderLock_ConnectionDropped", suite.TestLeaderLock_ConnectionDropped)
 	})
 }
 
@@ -199,7 +244,7 @@ func (s *IntegrationSuite) TestCreateBook_Success(t *testing.T) {
 	book, err := domain.NewBook(bookData)
 	require.NoError(t, err)
 
-	createdBook, err := bookRepo.CreateBook(ctx, book)
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
 	require.NoError(t, err)
 
 	assert.Equal(t, "1984", createdBook.Title())
@@ -229,7 +274,7 @@ func (s *IntegrationSuite) TestGetBook_Success(t *testing.T) {
 	book, err := domain.NewBook(bookData)
 	require.NoError(t, err)
 
-	createdBook, err := bookRepo.CreateBook(ctx, book)
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
 	require.NoError(t, err)
 
 	retrievedBook, err := bookRepo.GetBook(ctx, createdBook.ID())
@@ -274,7 +319,7 @@ func (s *IntegrationSuite) TestUpdateBook_Success(t *testing.T) {
 	book, err := domain.NewBook(bookData)
 	require.NoError(t, err)
 
-	createdBook, err := bookRepo.CreateBook(ctx, book)
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
 	require.NoError(t, err)
 
 	createdBookData := domain.NewBookData{
@@ -290,7 +335,7 @@ func (s *IntegrationSuite) TestUpdateBook_Success(t *testing.T) {
 	updatedBook, err := domain.NewBook(createdBookData)
 	require.NoError(t, err)
 
-	updatedBook, err = bookRepo.UpdateBook(ctx, updatedBook)
+	updatedBook, err = bookRepo.UpdateBook(ctx, testActorID, updatedBook)
 	require.NoError(t, err)
 
 	assert.Equal(t, "Animal Farm", updatedBook.Title())
@@ -320,10 +365,10 @@ func (s *IntegrationSuite) TestDeleteBook_Success(t *testing.T) {
 	book, err := domain.NewBook(bookData)
 	require.NoError(t, err)
 
-	createdBook, err := bookRepo.CreateBook(ctx, book)
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
 	require.NoError(t, err)
 
-	err = bookRepo.DeleteBook(ctx, createdBook.ID())
+	err = bookRepo.DeleteBook(ctx, testActorID, createdBook.ID())
 	require.NoError(t, err)
 
 	_, err = bookRepo.GetBook(ctx, createdBook.ID())
@@ -331,6 +376,70 @@ func (s *IntegrationSuite) TestDeleteBook_Success(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+func (s *IntegrationSuite) TestGetBookByISBN_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      200,
+		CategoryID: 1,
+		ISBN:       "0-452-28423-6",
+	})
+	require.NoError(t, err)
+
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+	assert.Equal(t, "9780452284234", createdBook.ISBN())
+
+	foundBook, err := bookRepo.GetBookByISBN(ctx, "9780452284234")
+	require.NoError(t, err)
+	assert.Equal(t, createdBook.ID(), foundBook.ID())
+
+	_, err = bookRepo.GetBookByISBN(ctx, "9780306406157")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func (s *IntegrationSuite) TestCreateBook_ISBNConflict(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	for i := 0; i < 2; i++ {
+		book, err := domain.NewBook(domain.NewBookData{Title: "No ISBN", Year: 1949, Author: "A", Price: 1})
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err, "books without an ISBN don't conflict")
+	}
+
+	book, err := domain.NewBook(domain.NewBookData{Title: "1984", Year: 1949, Author: "A", Price: 1, ISBN: "0452284236"})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	duplicate, err := domain.NewBook(domain.NewBookData{
+		Title: "Nineteen Eighty-Four", Year: 1949, Author: "A", Price: 1, ISBN: "978-0-452-28423-4",
+	})
+	require.NoError(t, err)
+	_, err = bookRepo.CreateBook(ctx, testActorID, duplicate)
+	require.ErrorIs(t, err, domain.ErrISBNConflict)
+
+	// a deleted book doesn't hold its ISBN, but can't be restored while another book has it
+	require.NoError(t, bookRepo.DeleteBook(ctx, testActorID, book.ID()))
+	_, err = bookRepo.CreateBook(ctx, testActorID, duplicate)
+	require.NoError(t, err)
+	_, err = bookRepo.RestoreBook(ctx, testActorID, book.ID())
+	require.ErrorIs(t, err, domain.ErrISBNConflict)
+}
+
 func (s *IntegrationSuite) TestGetBooks_Success(t *testing.T) {
 	ctx := context.Background()
 
@@ -362,19 +471,393 @@ func (s *IntegrationSuite) TestGetBooks_Success(t *testing.T) {
 	book2, err := domain.NewBook(bookData2)
 	require.NoError(t, err)
 
-	_, err = bookRepo.CreateBook(ctx, book1)
+	_, err = bookRepo.CreateBook(ctx, testActorID, book1)
 	require.NoError(t, err)
 
-	_, err = bookRepo.CreateBook(ctx, book2)
+	_, err = bookRepo.CreateBook(ctx, testActorID, book2)
 	require.NoError(t, err)
 
-	books, err := bookRepo.GetBooks(ctx, []int{1}, 10, 0)
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{1}}, 10, 0)
 	require.NoError(t, err)
 
 	assert.Len(t, books, 2)
 }
 
+func (s *IntegrationSuite) TestGetBooks_Search(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	for _, data := range []domain.NewBookData{
+		{Title: "Animal Farm", Year: 1945, Author: "George Orwell", Price: 1000, Stock: 100, CategoryID: 1},
+		{Title: "Farmer Giles of Ham", Year: 1949, Author: "J. R. R. Tolkien", Price: 900, Stock: 10, CategoryID: 1},
+		{Title: "The Hobbit", Year: 1937, Author: "J. R. R. Tolkien", Price: 1200, Stock: 10, CategoryID: 1},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{Query: "farms"}, 10, 0)
+	require.NoError(t, err)
+
+	require.Len(t, books, 1)
+	assert.Equal(t, "Animal Farm", books[0].Title())
+	assert.Equal(t, "Animal <mark>Farm</mark>", books[0].Highlight().Title)
+
+	books, err = bookRepo.GetBooks(ctx, domain.BookFilter{Query: "tolkien -farmer"}, 10, 0)
+	require.NoError(t, err)
+
+	require.Len(t, books, 1)
+	assert.Equal(t, "The Hobbit", books[0].Title())
+	assert.Equal(t, "J. R. R. <mark>Tolkien</mark>", books[0].Highlight().Author)
+}
+
+func (s *IntegrationSuite) TestGetBooks_Filter(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	for _, data := range []domain.NewBookData{
+		{Title: "Animal Farm", Year: 1945, Author: "George Orwell", Price: 1000, Stock: 100, CategoryID: 1},
+		{Title: "1984", Year: 1949, Author: "George Orwell", Price: 1500, Stock: 0, CategoryID: 1},
+		{Title: "The Hobbit", Year: 1937, Author: "J. R. R. Tolkien", Price: 1200, Stock: 10, CategoryID: 1},
+		{Title: "100% Orwell", Year: 2003, Author: "Orwell_Fans", Price: 800, Stock: 5, CategoryID: 1},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	inStock, soldOut := true, false
+	tests := []struct {
+		name   string
+		filter domain.BookFilter
+		titles []string
+	}{
+		{"author", domain.BookFilter{Author: "orwell"}, []string{"Animal Farm", "1984", "100% Orwell"}},
+		{"author wildcard", domain.BookFilter{Author: "l_f"}, []string{}},
+		{"in stock", domain.BookFilter{Author: "george", InStock: &inStock}, []string{"Animal Farm"}},
+		{"sold out", domain.BookFilter{InStock: &soldOut}, []string{"1984"}},
+		{"price range", domain.BookFilter{MinPrice: 1000, MaxPrice: 1200}, []string{"Animal Farm", "The Hobbit"}},
+		{"year range", domain.BookFilter{MinYear: 1940, MaxYear: 1950}, []string{"Animal Farm", "1984"}},
+		{
+			"sort by price desc",
+			domain.BookFilter{Sort: domain.BookSort{Field: domain.BookSortByPrice, Desc: true}},
+			[]string{"1984", "The Hobbit", "Animal Farm", "100% Orwell"},
+		},
+		{
+			"sort by year",
+			domain.BookFilter{Sort: domain.BookSort{Field: domain.BookSortByYear}},
+			[]string{"The Hobbit", "Animal Farm", "1984", "100% Orwell"},
+		},
+	}
+
+	for _, tt := range tests {
+		books, err := bookRepo.GetBooks(ctx, tt.filter, 10, 0)
+		require.NoError(t, err, tt.name)
+
+		titles := make([]string, 0, len(books))
+		for _, book := range books {
+			titles = append(titles, book.Title())
+		}
+		assert.Equal(t, tt.titles, titles, tt.name)
+	}
+}
+
+func (s *IntegrationSuite) TestGetBooks_Cursor(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	for _, data := range []domain.NewBookData{
+		{Title: "Animal Farm", Year: 1945, Author: "George Orwell", Price: 1000, Stock: 100, CategoryID: 1},
+		{Title: "1984", Year: 1949, Author: "George Orwell", Price: 1500, Stock: 10, CategoryID: 1},
+		{Title: "The Hobbit", Year: 1937, Author: "J. R. R. Tolkien", Price: 1000, Stock: 10, CategoryID: 1},
+		{Title: "Homage to Catalonia", Year: 1938, Author: "George Orwell", Price: 1200, Stock: 5, CategoryID: 1},
+		{Title: "Burmese Days", Year: 1934, Author: "George Orwell", Price: 1000, Stock: 5, CategoryID: 1},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.BookFilter
+		titles []string
+	}{
+		{
+			"by ID",
+			domain.BookFilter{},
+			[]string{"Animal Farm", "1984", "The Hobbit", "Homage to Catalonia", "Burmese Days"},
+		},
+		{
+			"by price desc",
+			domain.BookFilter{Sort: domain.BookSort{Field: domain.BookSortByPrice, Desc: true}},
+			[]string{"1984", "Homage to Catalonia", "Animal Farm", "The Hobbit", "Burmese Days"},
+		},
+		{
+			"by relevance",
+			domain.BookFilter{Query: "orwell"},
+			[]string{"Animal Farm", "1984", "Homage to Catalonia", "Burmese Days"},
+		},
+	}
+
+	for _, tt := range tests {
+		count, err := bookRepo.CountBooks(ctx, tt.filter)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, len(tt.titles), count, tt.name)
+
+		filter := tt.filter
+		titles := make([]string, 0, len(tt.titles))
+		for {
+			books, err := bookRepo.GetBooks(ctx, filter, 2, 0)
+			require.NoError(t, err, tt.name)
+			if len(books) == 0 {
+				break
+			}
+			for _, book := range books {
+				titles = append(titles, book.Title())
+			}
+			cursor := filter.CursorAfter(books[len(books)-1])
+			filter.After = &cursor
+		}
+		assert.Equal(t, tt.titles, titles, tt.name)
+	}
+}
+
+func (s *IntegrationSuite) TestGetBooks_MultipleCategories(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	for _, data := range []domain.NewBookData{
+		{Title: "Designing Data-Intensive Applications", Year: 2017, Author: "Martin Kleppmann", Price: 1,
+			CategoryIDs: []int{1, 2}},
+		{Title: "The Go Programming Language", Year: 2015, Author: "Alan Donovan", Price: 1, CategoryID: 1},
+		{Title: "Database Internals", Year: 2019, Author: "Alex Petrov", Price: 1, CategoryID: 2},
+		{Title: "Dune", Year: 1965, Author: "Frank Herbert", Price: 1, CategoryID: 3},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{1, 2}}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, books, 3, "a book in both categories is only returned once")
+
+	count, err := bookRepo.CountBooks(ctx, domain.BookFilter{CategoryIDs: []int{1, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	books, err = bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{2}}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, books, 2)
+	for _, book := range books {
+		assert.Contains(t, book.CategoryIDs(), 2)
+	}
+}
+
 // CategoryRepo tests.
+func (s *IntegrationSuite) TestUpdateBookCover(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      200,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	createdBook, err := bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+	assert.Empty(t, createdBook.CoverKey())
+
+	updatedBook, previousKey, err := bookRepo.UpdateBookCover(ctx, testActorID, createdBook.ID(), "book-1-aaaa.png")
+	require.NoError(t, err)
+	assert.Empty(t, previousKey)
+	assert.Equal(t, "book-1-aaaa.png", updatedBook.CoverKey())
+
+	_, previousKey, err = bookRepo.UpdateBookCover(ctx, testActorID, createdBook.ID(), "book-1-bbbb.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "book-1-aaaa.png", previousKey)
+
+	// Updating the book keeps its cover
+	book, err = domain.NewBook(domain.NewBookData{
+		ID:         createdBook.ID(),
+		Title:      "Nineteen Eighty-Four",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	updatedBook, err = bookRepo.UpdateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+	assert.Equal(t, "book-1-bbbb.jpg", updatedBook.CoverKey())
+
+	_, _, err = bookRepo.UpdateBookCover(ctx, testActorID, createdBook.ID()+1, "book-2-cccc.png")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func (s *IntegrationSuite) TestImportBooks(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	newBook := func(title string, year, price int, isbn string, authorIDs []int) domain.Book {
+		book, err := domain.NewBook(domain.NewBookData{
+			Title:      title,
+			Year:       year,
+			Author:     "George Orwell",
+			Price:      price,
+			Stock:      10,
+			CategoryID: 1,
+			ISBN:       isbn,
+			AuthorIDs:  authorIDs,
+		})
+		require.NoError(t, err)
+		return book
+	}
+
+	existing, err := bookRepo.CreateBook(ctx, testActorID, newBook("1984", 1949, 1000, "9780451524935", nil))
+	require.NoError(t, err)
+
+	books := []domain.Book{
+		newBook("Nineteen Eighty-Four", 1949, 1200, "9780451524935", nil), // same ISBN
+		newBook("Animal Farm", 1945, 800, "", nil),
+		newBook("Animal Farm", 1945, 900, "", nil), // same title, author and year as the previous one
+		newBook("Homage to Catalonia", 1938, 700, "", []int{404}),
+	}
+
+	// A dry run reports without writing
+	results, err := bookRepo.ImportBooks(ctx, testActorID, books, true)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, domain.BookImportResult{Action: domain.BookImportUpdated, BookID: existing.ID()}, results[0])
+	assert.Equal(t, domain.BookImportCreated, results[1].Action)
+	assert.Zero(t, results[1].BookID)
+	assert.Equal(t, domain.BookImportUpdated, results[2].Action)
+	assert.Equal(t, domain.BookImportRejected, results[3].Action)
+	require.ErrorIs(t, results[3].Err, domain.ErrAuthorNotFound)
+
+	count, err := bookRepo.CountBooks(ctx, domain.BookFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	results, err = bookRepo.ImportBooks(ctx, testActorID, books, false)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, domain.BookImportUpdated, results[0].Action)
+	assert.Equal(t, domain.BookImportCreated, results[1].Action)
+	assert.Equal(t, domain.BookImportResult{Action: domain.BookImportUpdated, BookID: results[1].BookID}, results[2])
+	assert.Equal(t, domain.BookImportRejected, results[3].Action)
+
+	updated, err := bookRepo.GetBook(ctx, existing.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "Nineteen Eighty-Four", updated.Title())
+	assert.Equal(t, 1200, updated.Price())
+
+	animalFarm, err := bookRepo.GetBook(ctx, results[1].BookID)
+	require.NoError(t, err)
+	assert.Equal(t, 900, animalFarm.Price())
+
+	count, err = bookRepo.CountBooks(ctx, domain.BookFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// An exported row with an edited stock updates the book, which keeps its stock, with a warning
+	restocked, err := domain.NewBook(domain.NewBookData{
+		Title: "Nineteen Eighty-Four", Year: 1949, Author: "George Orwell", Price: 1300, Stock: 3,
+		CategoryID: 1, ISBN: "9780451524935",
+	})
+	require.NoError(t, err)
+	results, err = bookRepo.ImportBooks(ctx, testActorID, []domain.Book{restocked}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, domain.BookImportUpdated, results[0].Action)
+	assert.Equal(t, "stock 3 is ignored, the book keeps its stock of 10", results[0].Warning)
+
+	updated, err = bookRepo.GetBook(ctx, existing.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 1300, updated.Price())
+	assert.Equal(t, 10, updated.Stock())
+}
+
+func (s *IntegrationSuite) TestExportBooks(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	categoryIDs := make([]int, 0, 2)
+	for _, name := range []string{"Science", "Fiction"} {
+		category, err := domain.NewCategory(domain.NewCategoryData{Name: name})
+		require.NoError(t, err)
+		category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+		require.NoError(t, err)
+		categoryIDs = append(categoryIDs, category.ID())
+	}
+	science, fiction := categoryIDs[0], categoryIDs[1]
+
+	for _, data := range []domain.NewBookData{
+		{Title: "Dune", Year: 1965, Author: "Frank Herbert", Price: 900, Stock: 3, CategoryIDs: []int{science, fiction}},
+		{Title: "Solaris", Year: 1961, Author: "Stanislaw Lem", Price: 1200, Stock: 0, CategoryID: fiction},
+		{Title: "Cosmos", Year: 1980, Author: "Carl Sagan", Price: 1500, Stock: 2, CategoryID: science},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	var exported []domain.ExportedBook
+	filter := domain.BookFilter{CategoryIDs: []int{fiction}, Sort: domain.BookSort{Field: domain.BookSortByPrice}}
+	err := bookRepo.ExportBooks(ctx, filter, func(books []domain.ExportedBook) error {
+		exported = append(exported, books...)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, exported, 2, "sold-out books are exported unless filtered out")
+	assert.Equal(t, "Dune", exported[0].Title())
+	assert.Equal(t, []string{"Fiction", "Science"}, exported[0].CategoryNames)
+	assert.Equal(t, []int{science, fiction}, exported[0].CategoryIDs())
+	assert.Equal(t, "Solaris", exported[1].Title())
+	assert.Equal(t, []string{"Fiction"}, exported[1].CategoryNames)
+
+	// An error from fn stops the export
+	calls := 0
+	err = bookRepo.ExportBooks(ctx, domain.BookFilter{}, func([]domain.ExportedBook) error {
+		calls++
+		return errors.New("client went away")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
 func (s *IntegrationSuite) TestCreateCategory_Success(t *testing.T) {
 	ctx := context.Background()
 	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
@@ -388,7 +871,7 @@ func (s *IntegrationSuite) TestCreateCategory_Success(t *testing.T) {
 	category, err := domain.NewCategory(categoryData)
 	require.NoError(t, err)
 
-	createdCategory, err := categoryRepo.CreateCategory(ctx, category)
+	createdCategory, err := categoryRepo.CreateCategory(ctx, testActorID, category)
 	require.NoError(t, err)
 
 	assert.Equal(t, "Fiction", createdCategory.Name())
@@ -423,7 +906,7 @@ func (s *IntegrationSuite) TestUpdateCategory_Success(t *testing.T) {
 	category, err := domain.NewCategory(categoryData)
 	require.NoError(t, err)
 
-	createdCategory, err := categoryRepo.CreateCategory(ctx, category)
+	createdCategory, err := categoryRepo.CreateCategory(ctx, testActorID, category)
 	require.NoError(t, err)
 
 	createdCategoryData := domain.NewCategoryData{
@@ -434,7 +917,7 @@ func (s *IntegrationSuite) TestUpdateCategory_Success(t *testing.T) {
 	updatedCategory, err := domain.NewCategory(createdCategoryData)
 	require.NoError(t, err)
 
-	updatedCategory, err = categoryRepo.UpdateCategory(ctx, updatedCategory)
+	updatedCategory, err = categoryRepo.UpdateCategory(ctx, testActorID, updatedCategory)
 	require.NoError(t, err)
 
 	assert.Equal(t, "Non-fiction", updatedCategory.Name())
@@ -453,10 +936,10 @@ func (s *IntegrationSuite) TestDeleteCategory_Success(t *testing.T) {
 	category, err := domain.NewCategory(categoryData)
 	require.NoError(t, err)
 
-	createdCategory, err := categoryRepo.CreateCategory(ctx, category)
+	createdCategory, err := categoryRepo.CreateCategory(ctx, testActorID, category)
 	require.NoError(t, err)
 
-	err = categoryRepo.DeleteCategory(ctx, createdCategory.ID())
+	err = categoryRepo.DeleteCategory(ctx, testActorID, createdCategory.ID(), 0)
 	require.NoError(t, err)
 
 	_, err = categoryRepo.GetCategory(ctx, createdCategory.ID())
@@ -464,6 +947,58 @@ func (s *IntegrationSuite) TestDeleteCategory_Success(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+func (s *IntegrationSuite) TestDeleteCategory_NotEmpty(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	categoryIDs := make([]int, 0, 3)
+	for _, name := range []string{"Programming", "Distributed Systems", "Databases"} {
+		category, err := domain.NewCategory(domain.NewCategoryData{Name: name})
+		require.NoError(t, err)
+		category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+		require.NoError(t, err)
+		categoryIDs = append(categoryIDs, category.ID())
+	}
+	programming, distributed, databases := categoryIDs[0], categoryIDs[1], categoryIDs[2]
+
+	for _, data := range []domain.NewBookData{
+		{Title: "The Go Programming Language", Year: 2015, Author: "Alan Donovan", Price: 1, CategoryID: programming},
+		{Title: "Designing Data-Intensive Applications", Year: 2017, Author: "Martin Kleppmann", Price: 1,
+			CategoryIDs: []int{databases, programming}},
+		{Title: "Database Internals", Year: 2019, Author: "Alex Petrov", Price: 1,
+			CategoryIDs: []int{databases, distributed}},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	err := categoryRepo.DeleteCategory(ctx, testActorID, programming, 0)
+	var notEmpty domain.CategoryNotEmptyError
+	require.ErrorAs(t, err, &notEmpty)
+	assert.Equal(t, 2, notEmpty.Books)
+
+	err = categoryRepo.DeleteCategory(ctx, testActorID, programming, programming+100)
+	require.ErrorIs(t, err, domain.ErrCategoryNotFound)
+
+	err = categoryRepo.DeleteCategory(ctx, testActorID, programming, distributed)
+	require.NoError(t, err)
+	_, err = categoryRepo.GetCategory(ctx, programming)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{distributed}}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, books, 3)
+	for _, book := range books {
+		assert.NotContains(t, book.CategoryIDs(), programming)
+		assert.NotEqual(t, programming, book.CategoryID())
+	}
+}
+
 func (s *IntegrationSuite) TestGetCategories_Success(t *testing.T) {
 	ctx := context.Background()
 	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
@@ -484,106 +1019,787 @@ func (s *IntegrationSuite) TestGetCategories_Success(t *testing.T) {
 	category2, err := domain.NewCategory(categoryData2)
 	require.NoError(t, err)
 
-	_, err = categoryRepo.CreateCategory(ctx, category1)
+	_, err = categoryRepo.CreateCategory(ctx, testActorID, category1)
 	require.NoError(t, err)
 
-	_, err = categoryRepo.CreateCategory(ctx, category2)
+	_, err = categoryRepo.CreateCategory(ctx, testActorID, category2)
 	require.NoError(t, err)
 
-	categories, err := categoryRepo.GetCategories(ctx)
+	categories, err := categoryRepo.GetCategories(ctx, false)
 	require.NoError(t, err)
 
 	assert.Len(t, categories, 2)
 }
 
-// CartRepo tests.
-func (s *IntegrationSuite) TestGetCart_Success(t *testing.T) {
+func (s *IntegrationSuite) TestGetCategories_WithStats(t *testing.T) {
 	ctx := context.Background()
-
 	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
 
-	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
 
-	cartData := domain.NewCartData{
-		UserID:  1,
-		BookIDs: []int{1, 2},
+	categoryIDs := make([]int, 0, 3)
+	for _, name := range []string{"Fiction", "Science", "Poetry"} {
+		category, err := domain.NewCategory(domain.NewCategoryData{Name: name})
+		require.NoError(t, err)
+		category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+		require.NoError(t, err)
+		categoryIDs = append(categoryIDs, category.ID())
 	}
-
-	cart, err := domain.NewCart(cartData)
-	require.NoError(t, err)
-	cartModel := &models.Cart{
-		UserID:  cart.UserID(),
-		BookIDs: cart.BookIDs(),
+	fiction, science, poetry := categoryIDs[0], categoryIDs[1], categoryIDs[2]
+
+	var newest domain.Book
+	for _, data := range []domain.NewBookData{
+		{Title: "Dune", Year: 1965, Author: "Frank Herbert", Price: 900, Stock: 3, CategoryIDs: []int{fiction, science}},
+		{Title: "Solaris", Year: 1961, Author: "Stanislaw Lem", Price: 1200, Stock: 1, CategoryID: fiction},
+		{Title: "Sold Out", Year: 2000, Author: "Nobody", Price: 100, Stock: 0, CategoryIDs: []int{fiction, poetry}},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		book, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+		if data.Title == "Solaris" {
+			newest = book
+		}
 	}
-	_, err = s.db.NewInsert().Model(cartModel).Column("user_id", "book_ids").Exec(ctx)
-	require.NoError(t, err)
 
-	retrievedCart, err := cartRepo.GetCart(ctx, cartData.UserID)
+	categories, err := categoryRepo.GetCategories(ctx, true)
 	require.NoError(t, err)
+	require.Len(t, categories, 3)
 
-	assert.Equal(t, cartData.UserID, retrievedCart.UserID())
-	assert.ElementsMatch(t, cartData.BookIDs, retrievedCart.BookIDs())
-}
-
-func (s *IntegrationSuite) TestGetCart_NotFound(t *testing.T) {
-	ctx := context.Background()
-
-	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
-
-	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	assert.Equal(t, &domain.CategoryStats{
+		InStockBooks: 2, MinPrice: 900, MaxPrice: 1200,
+		NewestBook: &domain.CategoryBook{ID: newest.ID(), Title: "Solaris"},
+	}, categories[0].Stats())
+	assert.Equal(t, 1, categories[1].Stats().InStockBooks)
+	assert.Equal(t, &domain.CategoryStats{}, categories[2].Stats(), "sold-out books are not counted")
 
-	_, err := cartRepo.GetCart(ctx, 1)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "not found")
+	categories, err = categoryRepo.GetCategories(ctx, false)
+	require.NoError(t, err)
+	assert.Nil(t, categories[0].Stats())
 }
 
-func (s *IntegrationSuite) TestUpdateCartAndStocks_Success(t *testing.T) {
+func (s *IntegrationSuite) TestSoftDelete(t *testing.T) {
 	ctx := context.Background()
-
 	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
 
-	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
 
-	cartData := domain.NewCartData{
-		UserID:  1,
-		BookIDs: []int{1, 2},
+	categoryIDs := make([]int, 0, 3)
+	for _, name := range []string{"Fiction", "Poetry", "Drama"} {
+		category, err := domain.NewCategory(domain.NewCategoryData{Name: name})
+		require.NoError(t, err)
+		category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+		require.NoError(t, err)
+		categoryIDs = append(categoryIDs, category.ID())
 	}
+	fiction, poetry, drama := categoryIDs[0], categoryIDs[1], categoryIDs[2]
+
+	bookIDs := make([]int, 0, 3)
+	for _, data := range []domain.NewBookData{
+		{Title: "Dune", Year: 1965, Author: "Frank Herbert", Price: 1, Stock: 1, CategoryID: fiction},
+		{Title: "Leaves of Grass", Year: 1855, Author: "Walt Whitman", Price: 1, Stock: 1,
+			CategoryIDs: []int{fiction, poetry}},
+		{Title: "Hamlet", Year: 1603, Author: "William Shakespeare", Price: 1, Stock: 1, CategoryID: drama},
+	} {
+		book, err := domain.NewBook(data)
+		require.NoError(t, err)
+		book, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+		bookIDs = append(bookIDs, book.ID())
+	}
+	dune, leaves, hamlet := bookIDs[0], bookIDs[1], bookIDs[2]
 
-	cart, err := domain.NewCart(cartData)
+	// a deleted book is hidden but listed for admins
+	require.NoError(t, bookRepo.DeleteBook(ctx, testActorID, dune))
+	_, err := bookRepo.GetBook(ctx, dune)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{fiction}}, 10, 0)
 	require.NoError(t, err)
-	cartModel := &models.Cart{
-		UserID:  cart.UserID(),
-		BookIDs: cart.BookIDs(),
-	}
-	_, err = s.db.NewInsert().Model(cartModel).Column("user_id", "book_ids").
-		On("CONFLICT (user_id) DO UPDATE").Set("book_ids = EXCLUDED.book_ids").Exec(ctx)
+	require.Len(t, books, 1)
+	assert.Equal(t, leaves, books[0].ID())
+	books, err = bookRepo.GetBooks(ctx, domain.BookFilter{Deleted: true}, 10, 0)
 	require.NoError(t, err)
-
-	_, err = cartRepo.GetCart(ctx, cartData.UserID)
+	require.Len(t, books, 1)
+	assert.Equal(t, dune, books[0].ID())
+	assert.False(t, books[0].DeletedAt().IsZero())
+
+	// deleted books don't keep a category from being deleted, they are reassigned with the others
+	err = categoryRepo.DeleteCategory(ctx, testActorID, fiction, 0)
+	var notEmpty domain.CategoryNotEmptyError
+	require.ErrorAs(t, err, &notEmpty)
+	assert.Equal(t, 1, notEmpty.Books)
+	require.NoError(t, categoryRepo.DeleteCategory(ctx, testActorID, fiction, poetry))
+	_, err = categoryRepo.GetCategory(ctx, fiction)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	deletedCategories, err := categoryRepo.GetDeletedCategories(ctx)
 	require.NoError(t, err)
+	require.Len(t, deletedCategories, 1)
+	assert.Equal(t, fiction, deletedCategories[0].ID())
 
-	updatedCartData := domain.NewCartData{
-		UserID:  1,
-		BookIDs: []int{1},
-	}
+	restoredBook, err := bookRepo.RestoreBook(ctx, testActorID, dune)
+	require.NoError(t, err)
+	assert.True(t, restoredBook.DeletedAt().IsZero())
+	assert.Equal(t, poetry, restoredBook.CategoryID())
+	_, err = bookRepo.RestoreBook(ctx, testActorID, dune)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	// a book can't be restored into a deleted category, nor linked to one
+	require.NoError(t, bookRepo.DeleteBook(ctx, testActorID, hamlet))
+	require.NoError(t, categoryRepo.DeleteCategory(ctx, testActorID, drama, 0))
+	_, err = bookRepo.RestoreBook(ctx, testActorID, hamlet)
+	require.ErrorIs(t, err, domain.ErrCategoryNotFound)
+	book, err := domain.NewBook(domain.NewBookData{Title: "Macbeth", Year: 1606, Author: "William Shakespeare",
+		Price: 1, CategoryID: drama})
+	require.NoError(t, err)
+	_, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.ErrorIs(t, err, domain.ErrCategoryNotFound)
 
-	updatedCart, err := domain.NewCart(updatedCartData)
+	restoredCategory, err := categoryRepo.RestoreCategory(ctx, testActorID, drama)
+	require.NoError(t, err)
+	assert.True(t, restoredCategory.DeletedAt().IsZero())
+	_, err = bookRepo.RestoreBook(ctx, testActorID, hamlet)
 	require.NoError(t, err)
-	_ = &models.Cart{
-		UserID:  updatedCart.UserID(),
-		BookIDs: updatedCart.BookIDs(),
-	}
 
-	err = cartRepo.UpdateCartAndStocks(ctx, updatedCart)
+	// the purge keeps what was deleted after the cutoff and the categories of unpurged books
+	require.NoError(t, bookRepo.DeleteBook(ctx, testActorID, hamlet))
+	require.NoError(t, categoryRepo.DeleteCategory(ctx, testActorID, drama, 0))
+	purgedBooks, err := bookRepo.PurgeBooks(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, purgedBooks)
+	purgedCategories, err := categoryRepo.PurgeCategories(ctx, time.Now().Add(time.Minute))
 	require.NoError(t, err)
+	assert.Equal(t, 1, purgedCategories, "only fiction is unreferenced")
 
-	retrievedCart, err := cartRepo.GetCart(ctx, updatedCartData.UserID)
+	purgedBooks, err = bookRepo.PurgeBooks(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, purgedBooks, 1)
+	assert.Equal(t, hamlet, purgedBooks[0].ID())
+	purgedCategories, err = categoryRepo.PurgeCategories(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purgedCategories)
+	deletedCategories, err = categoryRepo.GetDeletedCategories(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, deletedCategories)
+}
+
+func (s *IntegrationSuite) TestAuditEvents(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	auditRepo := pgrepo.NewAuditRepo(&pg.DB{DB: s.db})
+
+	category, err := domain.NewCategory(domain.NewCategoryData{Name: "Fiction"})
+	require.NoError(t, err)
+	category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+	require.NoError(t, err)
+
+	book, err := domain.NewBook(domain.NewBookData{Title: "Dune", Year: 1965, Author: "Frank Herbert",
+		Price: 1000, Stock: 1, CategoryID: category.ID()})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+	update, err := domain.NewBook(domain.NewBookData{ID: book.ID(), Title: "Dune", Year: 1965,
+		Author: "Frank Herbert", Price: 1200, CategoryID: category.ID()})
+	require.NoError(t, err)
+	_, err = bookRepo.UpdateBook(ctx, testActorID+1, update)
+	require.NoError(t, err)
+	require.NoError(t, bookRepo.DeleteBook(ctx, testActorID, book.ID()))
+
+	// failed changes and dry runs leave no events
+	missing, err := domain.NewBook(domain.NewBookData{ID: book.ID() + 100, Title: "Emma", Year: 1815,
+		Author: "Jane Austen", Price: 1, CategoryID: category.ID()})
+	require.NoError(t, err)
+	_, err = bookRepo.UpdateBook(ctx, testActorID, missing)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	imported, err := domain.NewBook(domain.NewBookData{Title: "Emma", Year: 1815, Author: "Jane Austen",
+		Price: 1, CategoryID: category.ID()})
+	require.NoError(t, err)
+	_, err = bookRepo.ImportBooks(ctx, testActorID, []domain.Book{imported}, true)
+	require.NoError(t, err)
+
+	events, err := auditRepo.GetAuditEvents(ctx,
+		domain.AuditFilter{Entity: domain.AuditEntityBook, EntityID: book.ID()}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	actions := make([]domain.AuditAction, 0, len(events))
+	for _, event := range events {
+		actions = append(actions, event.Action())
+	}
+	assert.Equal(t, []domain.AuditAction{domain.AuditActionDelete, domain.AuditActionUpdate,
+		domain.AuditActionCreate}, actions)
+	assert.Nil(t, events[2].Before())
+
+	var before, after map[string]any
+	require.NoError(t, json.Unmarshal(events[1].Before(), &before))
+	require.NoError(t, json.Unmarshal(events[1].After(), &after))
+	assert.EqualValues(t, 1000, before["price"])
+	assert.EqualValues(t, 1200, after["price"])
+	assert.Equal(t, testActorID+1, events[1].ActorID())
+	after = nil
+	require.NoError(t, json.Unmarshal(events[0].After(), &after))
+	assert.Contains(t, after, "deletedAt")
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{ActorID: testActorID + 1}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.AuditActionUpdate, events[0].Action())
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{Entity: domain.AuditEntityCategory}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, category.ID(), events[0].EntityID())
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{}, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.AuditEntityBook, events[0].Entity())
+	assert.Equal(t, domain.AuditEntityCategory, events[1].Entity())
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{From: time.Now().Add(time.Hour)}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// reassigning the books of a deleted category records an update of every moved book
+	other, err := domain.NewCategory(domain.NewCategoryData{Name: "Classics"})
+	require.NoError(t, err)
+	other, err = categoryRepo.CreateCategory(ctx, testActorID, other)
+	require.NoError(t, err)
+	require.NoError(t, categoryRepo.DeleteCategory(ctx, testActorID+2, category.ID(), other.ID()))
+
+	events, err = auditRepo.GetAuditEvents(ctx,
+		domain.AuditFilter{Entity: domain.AuditEntityBook, ActorID: testActorID + 2}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, book.ID(), events[0].EntityID())
+	assert.Equal(t, domain.AuditActionUpdate, events[0].Action())
+	before, after = nil, nil
+	require.NoError(t, json.Unmarshal(events[0].Before(), &before))
+	require.NoError(t, json.Unmarshal(events[0].After(), &after))
+	assert.EqualValues(t, category.ID(), before["categoryId"])
+	assert.EqualValues(t, other.ID(), after["categoryId"])
+}
+
+func (s *IntegrationSuite) TestCategoryTree(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	categoryRepo := pgrepo.NewCategoryRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	// Fiction -> Fantasy -> Epic Fantasy
+	var parentID int
+	categoryIDs := make([]int, 0, 3)
+	for _, name := range []string{"Fiction", "Fantasy", "Epic Fantasy"} {
+		category, err := domain.NewCategory(domain.NewCategoryData{Name: name, ParentID: parentID})
+		require.NoError(t, err)
+		category, err = categoryRepo.CreateCategory(ctx, testActorID, category)
+		require.NoError(t, err)
+		parentID = category.ID()
+		categoryIDs = append(categoryIDs, category.ID())
+	}
+	fiction, fantasy, epicFantasy := categoryIDs[0], categoryIDs[1], categoryIDs[2]
+
+	for _, categoryID := range categoryIDs {
+		book, err := domain.NewBook(domain.NewBookData{Title: "Book", Year: 2000, Author: "A", Price: 1,
+			CategoryID: categoryID})
+		require.NoError(t, err)
+		_, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+	}
+
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{fiction}, IncludeDescendants: true}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, books, 3)
+	books, err = bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{fantasy}, IncludeDescendants: true}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, books, 2)
+	books, err = bookRepo.GetBooks(ctx, domain.BookFilter{CategoryIDs: []int{fiction}}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, books, 1)
+
+	cycle, err := domain.NewCategory(domain.NewCategoryData{ID: fiction, Name: "Fiction", ParentID: epicFantasy})
+	require.NoError(t, err)
+	_, err = categoryRepo.UpdateCategory(ctx, testActorID, cycle)
+	require.ErrorIs(t, err, domain.ErrCategoryCycle)
+
+	moved, err := domain.NewCategory(domain.NewCategoryData{ID: epicFantasy, Name: "Epic Fantasy", ParentID: fiction})
+	require.NoError(t, err)
+	moved, err = categoryRepo.UpdateCategory(ctx, testActorID, moved)
+	require.NoError(t, err)
+	assert.Equal(t, fiction, moved.ParentID())
+}
+
+// AuthorRepo tests.
+func (s *IntegrationSuite) TestCreateAuthor_Conflict(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	authorRepo := pgrepo.NewAuthorRepo(&pg.DB{DB: s.db})
+
+	author, err := domain.NewAuthor(domain.NewAuthorData{Name: "George  Orwell"})
+	require.NoError(t, err)
+
+	createdAuthor, err := authorRepo.CreateAuthor(ctx, author)
+	require.NoError(t, err)
+	assert.Equal(t, "George Orwell", createdAuthor.Name())
+
+	duplicate, err := domain.NewAuthor(domain.NewAuthorData{Name: "george orwell"})
+	require.NoError(t, err)
+	_, err = authorRepo.CreateAuthor(ctx, duplicate)
+	require.ErrorIs(t, err, domain.ErrAuthorConflict)
+
+	renamed, err := domain.NewAuthor(domain.NewAuthorData{ID: createdAuthor.ID(), Name: "Eric Blair"})
+	require.NoError(t, err)
+	updatedAuthor, err := authorRepo.UpdateAuthor(ctx, testActorID, renamed)
+	require.NoError(t, err)
+	assert.Equal(t, "Eric Blair", updatedAuthor.Name())
+
+	err = authorRepo.DeleteAuthor(ctx, testActorID, createdAuthor.ID())
+	require.NoError(t, err)
+	_, err = authorRepo.GetAuthor(ctx, createdAuthor.ID())
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func (s *IntegrationSuite) TestCreateBook_LinksAuthors(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	authorRepo := pgrepo.NewAuthorRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	// Without authorIds the book is linked to the author named by its byline.
+	byline, err := domain.NewBook(domain.NewBookData{Title: "1984", Year: 1949, Author: " George Orwell ", Price: 1})
+	require.NoError(t, err)
+	bylineBook, err := bookRepo.CreateBook(ctx, testActorID, byline)
+	require.NoError(t, err)
+	require.Len(t, bylineBook.AuthorIDs(), 1)
+
+	orwell, err := authorRepo.GetAuthor(ctx, bylineBook.AuthorIDs()[0])
+	require.NoError(t, err)
+	assert.Equal(t, "George Orwell", orwell.Name())
+
+	huxley, err := domain.NewAuthor(domain.NewAuthorData{Name: "Aldous Huxley"})
+	require.NoError(t, err)
+	huxley, err = authorRepo.CreateAuthor(ctx, huxley)
+	require.NoError(t, err)
+
+	coauthored, err := domain.NewBook(domain.NewBookData{
+		Title: "Dystopias", Year: 1950, Author: "Huxley, Orwell", Price: 1,
+		AuthorIDs: []int{huxley.ID(), orwell.ID()},
+	})
+	require.NoError(t, err)
+	coauthoredBook, err := bookRepo.CreateBook(ctx, testActorID, coauthored)
+	require.NoError(t, err)
+	assert.Equal(t, []int{huxley.ID(), orwell.ID()}, coauthoredBook.AuthorIDs())
+
+	books, err := bookRepo.GetBooks(ctx, domain.BookFilter{AuthorID: orwell.ID()}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, books, 2)
+
+	unknown, err := domain.NewBook(domain.NewBookData{
+		Title: "Unknown", Year: 1950, Author: "Nobody", Price: 1, AuthorIDs: []int{orwell.ID() + 100},
+	})
+	require.NoError(t, err)
+	_, err = bookRepo.CreateBook(ctx, testActorID, unknown)
+	require.ErrorIs(t, err, domain.ErrAuthorNotFound)
+}
+
+func (s *IntegrationSuite) TestRenameAndMergeAuthors(t *testing.T) {
+	ctx := context.Background()
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	authorRepo := pgrepo.NewAuthorRepo(&pg.DB{DB: s.db})
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	auditRepo := pgrepo.NewAuditRepo(&pg.DB{DB: s.db})
+
+	createBook := func(title, author string, authorIDs ...int) domain.Book {
+		book, err := domain.NewBook(domain.NewBookData{
+			Title: title, Year: 1949, Author: author, Price: 1, AuthorIDs: authorIDs,
+		})
+		require.NoError(t, err)
+		book, err = bookRepo.CreateBook(ctx, testActorID, book)
+		require.NoError(t, err)
+		return book
+	}
+
+	nineteen := createBook("1984", "George Orwell")
+	orwellID := nineteen.AuthorIDs()[0]
+	farm := createBook("Animal Farm", "Orwell, George", orwellID)
+
+	// renaming the author renames the byline spelling the old name only
+	renamed, err := domain.NewAuthor(domain.NewAuthorData{ID: orwellID, Name: "Eric Blair"})
+	require.NoError(t, err)
+	_, err = authorRepo.UpdateAuthor(ctx, testActorID+1, renamed)
+	require.NoError(t, err)
+
+	book, err := bookRepo.GetBook(ctx, nineteen.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "Eric Blair", book.Author())
+	book, err = bookRepo.GetBook(ctx, farm.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "Orwell, George", book.Author())
+
+	events, err := auditRepo.GetAuditEvents(ctx, domain.AuditFilter{ActorID: testActorID + 1}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, nineteen.ID(), events[0].EntityID())
+
+	// the duplicate's books move to the other author, and the duplicate is deleted
+	duplicate := createBook("Burmese Days", "Eric  Arthur Blair")
+	duplicateID := duplicate.AuthorIDs()[0]
+	both := createBook("Essays", "Eric Blair", orwellID, duplicateID)
+
+	_, err = authorRepo.MergeAuthors(ctx, testActorID, duplicateID, orwellID+100)
+	require.ErrorIs(t, err, domain.ErrAuthorNotFound)
+
+	into, err := authorRepo.MergeAuthors(ctx, testActorID+2, duplicateID, orwellID)
+	require.NoError(t, err)
+	assert.Equal(t, "Eric Blair", into.Name())
+	_, err = authorRepo.GetAuthor(ctx, duplicateID)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	book, err = bookRepo.GetBook(ctx, duplicate.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "Eric Blair", book.Author())
+	assert.Equal(t, []int{orwellID}, book.AuthorIDs())
+	book, err = bookRepo.GetBook(ctx, both.ID())
+	require.NoError(t, err)
+	assert.Equal(t, []int{orwellID}, book.AuthorIDs())
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{ActorID: testActorID + 2}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// deleting the author unlinks all their books, which keep their byline
+	err = authorRepo.DeleteAuthor(ctx, testActorID+3, orwellID)
+	require.NoError(t, err)
+	book, err = bookRepo.GetBook(ctx, both.ID())
+	require.NoError(t, err)
+	assert.Empty(t, book.AuthorIDs())
+	assert.Equal(t, "Eric Blair", book.Author())
+
+	events, err = auditRepo.GetAuditEvents(ctx, domain.AuditFilter{ActorID: testActorID + 3}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	err = authorRepo.DeleteAuthor(ctx, testActorID+3, orwellID)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// CartRepo tests.
+func (s *IntegrationSuite) insertCart(ctx context.Context, t *testing.T, cart domain.Cart) {
+	t.Helper()
+
+	_, err := s.db.NewInsert().Model(&models.Cart{UserID: cart.UserID()}).Column("user_id").Exec(ctx)
+	require.NoError(t, err)
+
+	for _, item := range cart.Items() {
+		_, err = s.db.NewInsert().Model(&models.CartItem{
+			UserID:   cart.UserID(),
+			BookID:   item.BookID(),
+			Quantity: item.Quantity(),
+		}).Exec(ctx)
+		require.NoError(t, err)
+	}
+}
+
+func (s *IntegrationSuite) TestGetCart_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	cartData := domain.NewCartData{
+		UserID:  1,
+		BookIDs: []int{1, 2},
+	}
+
+	cart, err := domain.NewCart(cartData)
+	require.NoError(t, err)
+	s.insertCart(ctx, t, cart)
+
+	retrievedCart, err := cartRepo.GetCart(ctx, cartData.UserID)
+	require.NoError(t, err)
+
+	assert.Equal(t, cartData.UserID, retrievedCart.UserID())
+	assert.ElementsMatch(t, cartData.BookIDs, retrievedCart.BookIDs())
+}
+
+func (s *IntegrationSuite) TestGetCart_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	_, err := cartRepo.GetCart(ctx, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func (s *IntegrationSuite) TestUpdateCartAndStocks_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	cartData := domain.NewCartData{
+		UserID:  1,
+		BookIDs: []int{1, 2},
+	}
+
+	cart, err := domain.NewCart(cartData)
+	require.NoError(t, err)
+	s.insertCart(ctx, t, cart)
+
+	_, err = cartRepo.GetCart(ctx, cartData.UserID)
+	require.NoError(t, err)
+
+	updatedCartData := domain.NewCartData{
+		UserID:  1,
+		BookIDs: []int{1},
+	}
+
+	updatedCart, err := domain.NewCart(updatedCartData)
+	require.NoError(t, err)
+
+	err = cartRepo.UpdateCartAndStocks(ctx, updatedCart)
+	require.NoError(t, err)
+
+	retrievedCart, err := cartRepo.GetCart(ctx, updatedCartData.UserID)
 	require.NoError(t, err)
 
 	assert.Equal(t, updatedCartData.UserID, retrievedCart.UserID())
 	assert.ElementsMatch(t, updatedCartData.BookIDs, retrievedCart.BookIDs())
 }
 
+func (s *IntegrationSuite) TestUpdateCartAndStocks_QuantityDelta(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      10,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	updateQuantity := func(quantity int) error {
+		item, err := domain.NewCartItem(domain.NewCartItemData{BookID: book.ID(), Quantity: quantity})
+		require.NoError(t, err)
+		cart, err := domain.NewCart(domain.NewCartData{UserID: 1, Items: []domain.CartItem{item}})
+		require.NoError(t, err)
+		return cartRepo.UpdateCartAndStocks(ctx, cart)
+	}
+
+	require.NoError(t, updateQuantity(3))
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 7, book.Stock())
+
+	require.NoError(t, updateQuantity(1))
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 9, book.Stock())
+
+	err = updateQuantity(11)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "out of stock")
+
+	cart, err := cartRepo.GetCart(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cart.Quantity(book.ID()))
+}
+
+func (s *IntegrationSuite) TestUpdateCartAndStocks_ConcurrentFirstWrite(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      10,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	// both writers start from a user without a cart, the second must see the first's cart
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, quantity := range []int{3, 2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := domain.NewCartItem(domain.NewCartItemData{BookID: book.ID(), Quantity: quantity})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			cart, err := domain.NewCart(domain.NewCartData{UserID: 1, Items: []domain.CartItem{item}})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = cartRepo.UpdateCartAndStocks(ctx, cart)
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+
+	cart, err := cartRepo.GetCart(ctx, 1)
+	require.NoError(t, err)
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 10-cart.Quantity(book.ID()), book.Stock())
+}
+
+func (s *IntegrationSuite) createTestBooks(ctx context.Context, t *testing.T, stock int) (domain.Book, domain.Book) {
+	t.Helper()
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+
+	book1, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      stock,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book1, err = bookRepo.CreateBook(ctx, testActorID, book1)
+	require.NoError(t, err)
+
+	book2, err := domain.NewBook(domain.NewBookData{
+		Title:      "Animal Farm",
+		Year:       1945,
+		Author:     "George Orwell",
+		Price:      1000,
+		Stock:      stock,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book2, err = bookRepo.CreateBook(ctx, testActorID, book2)
+	require.NoError(t, err)
+
+	return book1, book2
+}
+
+func (s *IntegrationSuite) TestUpdateCartItem_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	book1, book2 := s.createTestBooks(ctx, t, 10)
+
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book1.ID(), 2))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book2.ID(), 3))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book1.ID(), 0))
+
+	cart, err := cartRepo.GetCart(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{book2.ID()}, cart.BookIDs())
+	assert.Equal(t, 3, cart.Quantity(book2.ID()))
+
+	book1, err = bookRepo.GetBook(ctx, book1.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 10, book1.Stock())
+
+	book2, err = bookRepo.GetBook(ctx, book2.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 7, book2.Stock())
+}
+
+func (s *IntegrationSuite) TestClearCart_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	book1, book2 := s.createTestBooks(ctx, t, 10)
+
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book1.ID(), 2))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book2.ID(), 3))
+	require.NoError(t, cartRepo.ClearCart(ctx, 1))
+
+	_, err := cartRepo.GetCart(ctx, 1)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	for _, book := range []domain.Book{book1, book2} {
+		book, err = bookRepo.GetBook(ctx, book.ID())
+		require.NoError(t, err)
+		assert.Equal(t, 10, book.Stock())
+	}
+}
+
+func (s *IntegrationSuite) TestCleanExpiredCarts_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+
+	book1, book2 := s.createTestBooks(ctx, t, 10)
+
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book1.ID(), 2))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 1, book2.ID(), 1))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 2, book1.ID(), 3))
+	require.NoError(t, cartRepo.UpdateCartItem(ctx, 3, book2.ID(), 4))
+
+	// carts of users 1 and 2 are expired
+	_, err := s.db.NewUpdate().Model((*models.Cart)(nil)).Set("updated_at = ?", time.Now().Add(-time.Hour)).
+		Where("user_id IN (?)", bun.In([]int{1, 2})).Exec(ctx)
+	require.NoError(t, err)
+
+	carts, units, err := cartRepo.CleanExpiredCarts(ctx, 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, carts)
+	assert.Equal(t, 6, units)
+
+	book1, err = bookRepo.GetBook(ctx, book1.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 10, book1.Stock())
+
+	book2, err = bookRepo.GetBook(ctx, book2.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 6, book2.Stock())
+
+	_, err = cartRepo.GetCart(ctx, 1)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = cartRepo.GetCart(ctx, 3)
+	require.NoError(t, err)
+}
+
 func (s *IntegrationSuite) TestCheckStocks_Success(t *testing.T) {
 	ctx := context.Background()
 
@@ -615,10 +1831,10 @@ func (s *IntegrationSuite) TestCheckStocks_Success(t *testing.T) {
 	book2, err := domain.NewBook(bookData2)
 	require.NoError(t, err)
 
-	_, err = pgrepo.NewBookRepo(&pg.DB{DB: s.db}).CreateBook(ctx, book1)
+	_, err = pgrepo.NewBookRepo(&pg.DB{DB: s.db}).CreateBook(ctx, testActorID, book1)
 	require.NoError(t, err)
 
-	_, err = pgrepo.NewBookRepo(&pg.DB{DB: s.db}).CreateBook(ctx, book2)
+	_, err = pgrepo.NewBookRepo(&pg.DB{DB: s.db}).CreateBook(ctx, testActorID, book2)
 	require.NoError(t, err)
 
 	cartData := domain.NewCartData{
@@ -668,12 +1884,7 @@ func (s *IntegrationSuite) TestDeleteCart_Success(t *testing.T) {
 
 	cart, err := domain.NewCart(cartData)
 	require.NoError(t, err)
-	cartModel := &models.Cart{
-		UserID:  cart.UserID(),
-		BookIDs: cart.BookIDs(),
-	}
-	_, err = s.db.NewInsert().Model(cartModel).Column("user_id", "book_ids").Exec(ctx)
-	require.NoError(t, err)
+	s.insertCart(ctx, t, cart)
 
 	err = cartRepo.DeleteCart(ctx, cartData.UserID)
 	require.NoError(t, err)
@@ -683,6 +1894,352 @@ func (s *IntegrationSuite) TestDeleteCart_Success(t *testing.T) {
 	assert.Contains(t, err.Error(), "not found")
 }
 
+// OrderRepo tests.
+// withTestPayment attaches an authorized payment to the order, as checkout does.
+func withTestPayment(order domain.Order) (domain.Order, error) {
+	payment, err := domain.NewPayment(domain.NewPaymentData{
+		ID:     "pay_test",
+		Amount: order.Total(),
+		Status: domain.PaymentStatusAuthorized,
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return order.WithPayment(payment)
+}
+
+func (s *IntegrationSuite) TestCreateOrderFromCart_Success(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	orderRepo := pgrepo.NewOrderRepo(&pg.DB{DB: s.db})
+
+	book1, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      200,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book2, err := domain.NewBook(domain.NewBookData{
+		Title:      "Animal Farm",
+		Year:       1945,
+		Author:     "George Orwell",
+		Price:      1000,
+		Stock:      100,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+
+	book1, err = bookRepo.CreateBook(ctx, testActorID, book1)
+	require.NoError(t, err)
+	book2, err = bookRepo.CreateBook(ctx, testActorID, book2)
+	require.NoError(t, err)
+
+	cart, err := domain.NewCart(domain.NewCartData{
+		UserID:  1,
+		BookIDs: []int{book1.ID(), book2.ID()},
+	})
+	require.NoError(t, err)
+
+	err = cartRepo.UpdateCartAndStocks(ctx, cart)
+	require.NoError(t, err)
+
+	order, err := orderRepo.CreateOrderFromCart(ctx, 1, withTestPayment)
+	require.NoError(t, err)
+
+	assert.NotZero(t, order.ID())
+	assert.Equal(t, 1, order.UserID())
+	assert.Equal(t, domain.OrderStatusCapturing, order.Status())
+	assert.Equal(t, "pay_test", order.PaymentID())
+	assert.Equal(t, 2500, order.Total())
+	assert.Len(t, order.Items(), 2)
+
+	retrievedOrder, err := orderRepo.GetOrder(ctx, order.ID())
+	require.NoError(t, err)
+	assert.Equal(t, order.Total(), retrievedOrder.Total())
+	assert.Len(t, retrievedOrder.Items(), 2)
+
+	// The cart is gone after checkout
+	_, err = cartRepo.GetCart(ctx, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func (s *IntegrationSuite) TestCreateOrderFromCart_EmptyCart(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	orderRepo := pgrepo.NewOrderRepo(&pg.DB{DB: s.db})
+
+	_, err := orderRepo.CreateOrderFromCart(ctx, 1, withTestPayment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cart is empty")
+}
+
+func (s *IntegrationSuite) TestCreateOrderFromCart_PaymentFails(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	orderRepo := pgrepo.NewOrderRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      10,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, BookIDs: []int{book.ID()}})
+	require.NoError(t, err)
+	err = cartRepo.UpdateCartAndStocks(ctx, cart)
+	require.NoError(t, err)
+
+	_, err = orderRepo.CreateOrderFromCart(ctx, 1, func(order domain.Order) (domain.Order, error) {
+		return domain.Order{}, errors.New("payment declined")
+	})
+	require.Error(t, err)
+
+	// The cart survives a failed payment and no order is written
+	_, err = cartRepo.GetCart(ctx, 1)
+	require.NoError(t, err)
+
+	orders, err := orderRepo.GetOrders(ctx, domain.OrderFilter{UserID: 1}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func (s *IntegrationSuite) TestUpdateOrderStatus_CancelRestoresStock(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	orderRepo := pgrepo.NewOrderRepo(&pg.DB{DB: s.db})
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      1,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, BookIDs: []int{book.ID()}})
+	require.NoError(t, err)
+	err = cartRepo.UpdateCartAndStocks(ctx, cart)
+	require.NoError(t, err)
+
+	order, err := orderRepo.CreateOrderFromCart(ctx, 1, withTestPayment)
+	require.NoError(t, err)
+
+	// A capturing order is left to checkout, and only checkout marks it paid
+	_, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusCancelled)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "being captured")
+	_, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusPaid)
+	require.Error(t, err)
+	order, err = orderRepo.FinishCheckout(ctx, order.ID(), domain.OrderStatusPaid)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusPaid, order.Status())
+	_, err = orderRepo.FinishCheckout(ctx, order.ID(), domain.OrderStatusCancelled)
+	require.ErrorIs(t, err, domain.ErrInvalidOrderStatus)
+
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 0, book.Stock())
+
+	// A paid order is cancelling while its payment is refunded, and only one cancel gets there
+	order, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusCancelling)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCancelling, order.Status())
+	_, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusCancelling)
+	require.Error(t, err)
+
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 0, book.Stock())
+
+	order, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusCancelled)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCancelled, order.Status())
+
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 1, book.Stock())
+
+	// Cancelled orders are final
+	_, err = orderRepo.UpdateOrderStatus(ctx, order.ID(), domain.OrderStatusPaid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't be moved")
+}
+
+func (s *IntegrationSuite) TestCheckout_CaptureDeclineVoidsPayment(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	bookRepo := pgrepo.NewBookRepo(&pg.DB{DB: s.db})
+	cartRepo := pgrepo.NewCartRepo(&pg.DB{DB: s.db})
+	orderRepo := pgrepo.NewOrderRepo(&pg.DB{DB: s.db})
+	gateway := payment.NewFakeGateway()
+	cartService := services.NewCartService(cartRepo, orderRepo, gateway, time.Minute)
+
+	book, err := domain.NewBook(domain.NewBookData{
+		Title:      "1984",
+		Year:       1949,
+		Author:     "George Orwell",
+		Price:      1500,
+		Stock:      1,
+		CategoryID: 1,
+	})
+	require.NoError(t, err)
+	book, err = bookRepo.CreateBook(ctx, testActorID, book)
+	require.NoError(t, err)
+
+	cart, err := domain.NewCart(domain.NewCartData{UserID: 1, BookIDs: []int{book.ID()}})
+	require.NoError(t, err)
+	err = cartRepo.UpdateCartAndStocks(ctx, cart)
+	require.NoError(t, err)
+
+	_, err = cartService.Checkout(ctx, 1, payment.TokenCaptureDecline)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to capture payment")
+
+	// the order is cancelled, which puts the book back on stock
+	orders, err := orderRepo.GetOrders(ctx, domain.OrderFilter{UserID: 1}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, domain.OrderStatusCancelled, orders[0].Status())
+
+	book, err = bookRepo.GetBook(ctx, book.ID())
+	require.NoError(t, err)
+	assert.Equal(t, 1, book.Stock())
+
+	// and the hold on the card is released
+	_, err = gateway.Refund(ctx, orders[0].PaymentID())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "payment is voided")
+}
+
+// IdempotencyRepo tests.
+func (s *IntegrationSuite) TestTakeOverIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	idempotencyRepo := pgrepo.NewIdempotencyRepo(&pg.DB{DB: s.db})
+
+	newKey := func(fingerprint string, lockedUntil time.Time) domain.IdempotencyKey {
+		key, err := domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{
+			Key:         "key",
+			UserID:      1,
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(time.Hour),
+			LockedUntil: lockedUntil,
+		})
+		require.NoError(t, err)
+		return key
+	}
+
+	_, created, err := idempotencyRepo.CreateIdempotencyKey(ctx, newKey("hash", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// the request still holds the key
+	takenOver, err := idempotencyRepo.TakeOverIdempotencyKey(ctx, newKey("hash", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, takenOver)
+
+	_, err = s.db.NewUpdate().Model((*models.IdempotencyKey)(nil)).
+		Set("locked_until = ?", time.Now().Add(-time.Second)).
+		Where("key = ?", "key").
+		Exec(ctx)
+	require.NoError(t, err)
+
+	// the abandoned key goes to a retry of the same request only, and only once
+	takenOver, err = idempotencyRepo.TakeOverIdempotencyKey(ctx, newKey("other", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, takenOver)
+	takenOver, err = idempotencyRepo.TakeOverIdempotencyKey(ctx, newKey("hash", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.True(t, takenOver)
+	takenOver, err = idempotencyRepo.TakeOverIdempotencyKey(ctx, newKey("hash", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, takenOver)
+}
+
+func (s *IntegrationSuite) TestIdempotencyKey_Owner(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	idempotencyRepo := pgrepo.NewIdempotencyRepo(&pg.DB{DB: s.db})
+
+	newKey := func(owner string, lockedUntil time.Time) domain.IdempotencyKey {
+		key, err := domain.NewIdempotencyKey(domain.NewIdempotencyKeyData{
+			Key:         "key",
+			UserID:      1,
+			Fingerprint: "hash",
+			ExpiresAt:   time.Now().Add(time.Hour),
+			LockedUntil: lockedUntil,
+			Owner:       owner,
+		})
+		require.NoError(t, err)
+		return key
+	}
+
+	// the first request's lease runs out while it is still running, and a retry takes the key over
+	first := newKey("first", time.Now().Add(-time.Second))
+	_, created, err := idempotencyRepo.CreateIdempotencyKey(ctx, first)
+	require.NoError(t, err)
+	require.True(t, created)
+	retry := newKey("retry", time.Now().Add(time.Minute))
+	takenOver, err := idempotencyRepo.TakeOverIdempotencyKey(ctx, retry)
+	require.NoError(t, err)
+	require.True(t, takenOver)
+
+	// the first request can no longer renew, store or delete the key
+	err = idempotencyRepo.RenewIdempotencyKey(ctx, first.WithLockedUntil(time.Now().Add(time.Minute)))
+	require.ErrorIs(t, err, domain.ErrIdempotencyKeyNotHeld)
+	err = idempotencyRepo.SaveIdempotentResponse(ctx, first.WithResponse(201, []byte(`{"id":1}`)))
+	require.ErrorIs(t, err, domain.ErrIdempotencyKeyNotHeld)
+	require.ErrorIs(t, idempotencyRepo.DeleteIdempotencyKey(ctx, first), domain.ErrIdempotencyKeyNotHeld)
+
+	// the retry holds it
+	require.NoError(t, idempotencyRepo.RenewIdempotencyKey(ctx, retry.WithLockedUntil(time.Now().Add(time.Minute))))
+	require.NoError(t, idempotencyRepo.SaveIdempotentResponse(ctx, retry.WithResponse(201, []byte(`{"id":2}`))))
+
+	stored, created, err := idempotencyRepo.CreateIdempotencyKey(ctx, newKey("third", time.Now().Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "retry", stored.Owner())
+	assert.Equal(t, []byte(`{"id":2}`), stored.ResponseBody())
+
+	// a completed key is not renewed
+	err = idempotencyRepo.RenewIdempotencyKey(ctx, retry.WithLockedUntil(time.Now().Add(time.Minute)))
+	require.ErrorIs(t, err, domain.ErrIdempotencyKeyNotHeld)
+}
+
 // HandleBunTransaction tests.
 func (s *IntegrationSuite) TestHandleBunTransaction_Success(t *testing.T) {
 	ctx := context.Background()
@@ -788,10 +2345,41 @@ func createSchema(ctx context.Context, db *bun.DB) error {
 	if err != nil {
 		return fmt.Errorf("failed to create books table: %w", err)
 	}
+	_, err = db.ExecContext(ctx, `ALTER TABLE books ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(author, '')), 'B')) STORED`)
+	if err != nil {
+		return fmt.Errorf("failed to add search vector to books table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE UNIQUE INDEX books_isbn_key ON books (isbn) WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to add unique index to books table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.Author)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create authors table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `CREATE UNIQUE INDEX authors_name_key ON authors (lower(name))`)
+	if err != nil {
+		return fmt.Errorf("failed to add unique index to authors table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.BookAuthor)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create book authors table: %w", err)
+	}
+	_, err = db.ExecContext(ctx, `ALTER TABLE book_authors ADD CONSTRAINT book_authors_author_id_fkey
+		FOREIGN KEY (author_id) REFERENCES authors (id) ON DELETE CASCADE`)
+	if err != nil {
+		return fmt.Errorf("failed to add foreign key to book authors table: %w", err)
+	}
 	_, err = db.NewCreateTable().Model((*models.Category)(nil)).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create categories table: %w", err)
 	}
+	_, err = db.NewCreateTable().Model((*models.BookCategory)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create book categories table: %w", err)
+	}
 	_, err = db.NewCreateTable().Model((*models.Cart)(nil)).Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create carts table: %w", err)
@@ -800,6 +2388,26 @@ func createSchema(ctx context.Context, db *bun.DB) error {
 	if err != nil {
 		return fmt.Errorf("failed to add unique constraint to carts table: %w", err)
 	}
+	_, err = db.NewCreateTable().Model((*models.CartItem)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create cart items table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.Order)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create orders table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.OrderItem)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create order items table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.IdempotencyKey)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency keys table: %w", err)
+	}
+	_, err = db.NewCreateTable().Model((*models.AuditEvent)(nil)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create audit events table: %w", err)
+	}
 	return nil
 }
 
@@ -849,3 +2457,58 @@ func ensureContainerTermination(ctx context.Context, container tc.Container) {
 		log.Fatalf("Failed to terminate container: %v", err)
 	}
 }
+
+// Advisory lock tests.
+func (s *IntegrationSuite) TestLeaderLock_Contention(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	leader := pg.NewLeaderLock(&pg.DB{DB: s.db}, pg.LockKey("test"))
+	follower := pg.NewLeaderLock(&pg.DB{DB: s.db}, pg.LockKey("test"))
+	other := pg.NewLeaderLock(&pg.DB{DB: s.db}, pg.LockKey("other"))
+
+	require.NoError(t, leader.Hold(ctx))
+	require.ErrorIs(t, follower.Hold(ctx), pg.ErrLockNotAcquired)
+	require.NoError(t, other.Hold(ctx))
+
+	// the leader keeps the lock between runs
+	require.NoError(t, leader.Hold(ctx))
+	require.ErrorIs(t, follower.Hold(ctx), pg.ErrLockNotAcquired)
+
+	// the follower takes over once the leader releases it
+	require.NoError(t, leader.Release(ctx))
+	require.NoError(t, follower.Hold(ctx))
+	require.ErrorIs(t, leader.Hold(ctx), pg.ErrLockNotAcquired)
+
+	require.NoError(t, follower.Release(ctx))
+	require.NoError(t, other.Release(ctx))
+}
+
+func (s *IntegrationSuite) TestLeaderLock_ConnectionDropped(t *testing.T) {
+	ctx := context.Background()
+
+	s.db = s.prepareTestPostgresDatabase(uuid.NewString())
+
+	leader := pg.NewLeaderLock(&pg.DB{DB: s.db}, pg.LockKey("test"))
+	follower := pg.NewLeaderLock(&pg.DB{DB: s.db}, pg.LockKey("test"))
+	require.NoError(t, leader.Hold(ctx))
+
+	terminate := func() {
+		_, err := s.db.ExecContext(ctx, `SELECT pg_terminate_backend(pid) FROM pg_locks
+			WHERE locktype = 'advisory' AND objid = ? AND granted`, pg.LockKey("test"))
+		require.NoError(t, err)
+	}
+
+	// the lock went away with the leader's connection, so the leader takes it again on a new one
+	terminate()
+	require.NoError(t, leader.Hold(ctx))
+	require.ErrorIs(t, follower.Hold(ctx), pg.ErrLockNotAcquired)
+
+	// unless another replica took it over in the meantime
+	terminate()
+	require.NoError(t, follower.Hold(ctx))
+	require.ErrorIs(t, leader.Hold(ctx), pg.ErrLockNotAcquired)
+
+	require.NoError(t, follower.Release(ctx))
+}